@@ -0,0 +1,118 @@
+package linmath
+
+import "math"
+
+// AABB is an axis-aligned bounding box.
+type AABB struct {
+	min Vector3
+	max Vector3
+}
+
+func NewAABB(min, max *Vector3) *AABB {
+	return &AABB{
+		min: *min,
+		max: *max,
+	}
+}
+
+// NewEmptyAABB is a function that returns a box containing nothing, which is the identity for Union and Extend.
+func NewEmptyAABB() *AABB {
+	return NewAABB(Splat(math.Inf(1)), Splat(math.Inf(-1)))
+}
+
+func (b *AABB) Min() *Vector3 {
+	return NewVector3(b.min.x, b.min.y, b.min.z)
+}
+
+func (b *AABB) Max() *Vector3 {
+	return NewVector3(b.max.x, b.max.y, b.max.z)
+}
+
+func (b *AABB) IsEmpty() bool {
+	return b.min.x > b.max.x || b.min.y > b.max.y || b.min.z > b.max.z
+}
+
+func (b *AABB) Center() *Vector3 {
+	return b.min.Add(&b.max).MultiplyOnScalar(0.5)
+}
+
+func (b *AABB) Extend(p *Vector3) *AABB {
+	return NewAABB(b.min.Min(p), b.max.Max(p))
+}
+
+func (b *AABB) Union(b2 *AABB) *AABB {
+	return NewAABB(b.min.Min(&b2.min), b.max.Max(&b2.max))
+}
+
+func (b *AABB) Intersection(b2 *AABB) *AABB {
+	return NewAABB(b.min.Max(&b2.min), b.max.Min(&b2.max))
+}
+
+// Transform is a method that returns the box enclosing all eight transformed corners of b.
+func (b *AABB) Transform(m *Matrix4) *AABB {
+	if b.IsEmpty() {
+		return NewEmptyAABB()
+	}
+
+	result := NewEmptyAABB()
+
+	for i := 0; i < 8; i++ {
+		corner := NewVector3(b.min.x, b.min.y, b.min.z)
+		if i&1 != 0 {
+			corner.x = b.max.x
+		}
+
+		if i&2 != 0 {
+			corner.y = b.max.y
+		}
+
+		if i&4 != 0 {
+			corner.z = b.max.z
+		}
+
+		result = result.Extend(m.MultiplyPoint(corner))
+	}
+
+	return result
+}
+
+// IntersectRay is a method that tests a ray against the box with the slab method.
+// Returns true if the ray enters the box somewhere between minT and maxT.
+func (b *AABB) IntersectRay(origin, direction *Vector3, minT, maxT float64) bool {
+	_, _, ok := b.ClipRay(origin, direction, minT, maxT)
+
+	return ok
+}
+
+// ClipRay is a method that returns the part of the span of the ray from minT to maxT that lies inside the box.
+func (b *AABB) ClipRay(origin, direction *Vector3, minT, maxT float64) (float64, float64, bool) {
+	origins := [3]float64{origin.x, origin.y, origin.z}
+	directions := [3]float64{direction.x, direction.y, direction.z}
+	mins := [3]float64{b.min.x, b.min.y, b.min.z}
+	maxs := [3]float64{b.max.x, b.max.y, b.max.z}
+
+	for axis := 0; axis < 3; axis++ {
+		invert := 1. / directions[axis]
+		t0 := (mins[axis] - origins[axis]) * invert
+		t1 := (maxs[axis] - origins[axis]) * invert
+
+		if invert < 0 {
+			t0, t1 = t1, t0
+		}
+
+		// NaN appears when the origin lies on a slab plane of an axis the ray is parallel to.
+		if !math.IsNaN(t0) {
+			minT = math.Max(minT, t0)
+		}
+
+		if !math.IsNaN(t1) {
+			maxT = math.Min(maxT, t1)
+		}
+
+		if maxT < minT {
+			return 0, 0, false
+		}
+	}
+
+	return minT, maxT, true
+}
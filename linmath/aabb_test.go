@@ -0,0 +1,128 @@
+package linmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAABBUnion(t *testing.T) {
+	tests := []struct {
+		inputBox1   *AABB
+		inputBox2   *AABB
+		expectedMin *Vector3
+		expectedMax *Vector3
+	}{
+		{NewAABB(NewVector3(0, 0, 0), NewVector3(1, 1, 1)), NewAABB(NewVector3(2, -1, 0), NewVector3(3, 0, 1)), NewVector3(0, -1, 0), NewVector3(3, 1, 1)},
+		{NewEmptyAABB(), NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1)), NewVector3(-1, -1, -1), NewVector3(1, 1, 1)},
+	}
+
+	for _, ts := range tests {
+		union := ts.inputBox1.Union(ts.inputBox2)
+
+		if !equalVectors(union.Min(), ts.expectedMin) || !equalVectors(union.Max(), ts.expectedMax) {
+			t.Fatalf("expected [%v %v] but have [%v]", ts.expectedMin, ts.expectedMax, union)
+		}
+	}
+}
+
+func TestAABBIntersection(t *testing.T) {
+	tests := []struct {
+		inputBox1     *AABB
+		inputBox2     *AABB
+		expectedEmpty bool
+		expectedMin   *Vector3
+		expectedMax   *Vector3
+	}{
+		{NewAABB(NewVector3(0, 0, 0), NewVector3(2, 2, 2)), NewAABB(NewVector3(1, -1, 1), NewVector3(3, 1, 3)), false, NewVector3(1, 0, 1), NewVector3(2, 1, 2)},
+		{NewAABB(NewVector3(0, 0, 0), NewVector3(1, 1, 1)), NewAABB(NewVector3(2, 2, 2), NewVector3(3, 3, 3)), true, nil, nil},
+	}
+
+	for _, ts := range tests {
+		intersection := ts.inputBox1.Intersection(ts.inputBox2)
+
+		if intersection.IsEmpty() != ts.expectedEmpty {
+			t.Fatalf("expected empty [%v] but have [%v]", ts.expectedEmpty, intersection)
+		}
+
+		if !ts.expectedEmpty && (!equalVectors(intersection.Min(), ts.expectedMin) || !equalVectors(intersection.Max(), ts.expectedMax)) {
+			t.Fatalf("expected [%v %v] but have [%v]", ts.expectedMin, ts.expectedMax, intersection)
+		}
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	tests := []struct {
+		inputBox    *AABB
+		inputMatrix *Matrix4
+		expectedMin *Vector3
+		expectedMax *Vector3
+	}{
+		{NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1)), NewTranslation(1, 2, 3), NewVector3(0, 1, 2), NewVector3(2, 3, 4)},
+		{NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1)), NewScale(2, 1, 0.5), NewVector3(-2, -1, -0.5), NewVector3(2, 1, 0.5)},
+		{NewAABB(NewVector3(0, 0, 0), NewVector3(1, 1, 1)), NewRotationZ(math.Pi / 4), NewVector3(-math.Sqrt2/2, 0, 0), NewVector3(math.Sqrt2/2, math.Sqrt2, 1)},
+	}
+
+	for _, ts := range tests {
+		box := ts.inputBox.Transform(ts.inputMatrix)
+
+		if !equalVectors(box.Min(), ts.expectedMin) || !equalVectors(box.Max(), ts.expectedMax) {
+			t.Fatalf("expected [%v %v] but have [%v]", ts.expectedMin, ts.expectedMax, box)
+		}
+	}
+
+	if box := NewEmptyAABB().Transform(NewTranslation(1, 1, 1)); !box.IsEmpty() {
+		t.Fatalf("expected empty box but have [%v]", box)
+	}
+}
+
+func TestAABBIntersectRay(t *testing.T) {
+	box := NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1))
+	tests := []struct {
+		inputOrigin    *Vector3
+		inputDirection *Vector3
+		inputMinT      float64
+		inputMaxT      float64
+		expectedHit    bool
+	}{
+		{NewVector3(0, 0, -5), NewVector3(0, 0, 1), 0, math.Inf(1), true},
+		{NewVector3(0, 0, -5), NewVector3(0, 0, -1), 0, math.Inf(1), false},
+		{NewVector3(0, 0, -5), NewVector3(0, 0, 1), 0, 3, false},
+		{NewVector3(0, 0, 0), NewVector3(1, 0, 0), 0, math.Inf(1), true},
+		{NewVector3(0, 2, -5), NewVector3(0, 0, 1), 0, math.Inf(1), false},
+		{NewVector3(-5, -5, -5), NewVector3(1, 1, 1), 0, math.Inf(1), true},
+		{NewVector3(1, 0, -5), NewVector3(0, 0, 1), 0, math.Inf(1), true},
+	}
+
+	for _, ts := range tests {
+		hit := box.IntersectRay(ts.inputOrigin, ts.inputDirection, ts.inputMinT, ts.inputMaxT)
+
+		if hit != ts.expectedHit {
+			t.Fatalf("expected [%v] but have [%v] for ray [%v %v]", ts.expectedHit, hit, ts.inputOrigin, ts.inputDirection)
+		}
+	}
+}
+
+func TestAABBClipRay(t *testing.T) {
+	box := NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1))
+	tests := []struct {
+		inputOrigin    *Vector3
+		inputDirection *Vector3
+		inputMinT      float64
+		inputMaxT      float64
+		expectedMinT   float64
+		expectedMaxT   float64
+	}{
+		{NewVector3(0, 0, -5), NewVector3(0, 0, 1), 0, math.Inf(1), 4, 6},
+		{NewVector3(0, 0, -5), NewVector3(0, 0, 2), 0, math.Inf(1), 2, 3},
+		{NewVector3(0, 0, 0), NewVector3(1, 0, 0), 0, math.Inf(1), 0, 1},
+		{NewVector3(0, 0, -5), NewVector3(0, 0, 1), 4.5, 5.5, 4.5, 5.5},
+	}
+
+	for _, ts := range tests {
+		minT, maxT, ok := box.ClipRay(ts.inputOrigin, ts.inputDirection, ts.inputMinT, ts.inputMaxT)
+
+		if !ok || math.Abs(minT-ts.expectedMinT) > epsilon || math.Abs(maxT-ts.expectedMaxT) > epsilon {
+			t.Fatalf("expected [%v %v] but have [%v %v %v] for ray [%v %v]", ts.expectedMinT, ts.expectedMaxT, minT, maxT, ok, ts.inputOrigin, ts.inputDirection)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package linmath
+
+// Frustum is the volume seen by a camera, bounded by six planes facing inwards.
+type Frustum struct {
+	planes [6]Plane
+}
+
+// NewFrustum is a function that extracts the planes of the frustum from a view-projection matrix
+// with the Gribb–Hartmann method. The matrix has to map the visible volume to -w <= x <= w,
+// -w <= y <= w and 0 <= z <= w, as NewPerspective does; planes are in the space the matrix transforms from.
+func NewFrustum(viewProjection *Matrix4) *Frustum {
+	m := viewProjection.m
+	plane := func(row [4]float64) Plane {
+		return *NewPlane(row[0], row[1], row[2], row[3])
+	}
+	combine := func(sign float64, row int) [4]float64 {
+		return [4]float64{
+			m[3][0] + sign*m[row][0],
+			m[3][1] + sign*m[row][1],
+			m[3][2] + sign*m[row][2],
+			m[3][3] + sign*m[row][3],
+		}
+	}
+
+	return &Frustum{
+		planes: [6]Plane{
+			plane(combine(1, 0)),  // left
+			plane(combine(-1, 0)), // right
+			plane(combine(1, 1)),  // bottom
+			plane(combine(-1, 1)), // top
+			plane(m[2]),           // near
+			plane(combine(-1, 2)), // far
+		},
+	}
+}
+
+func (f *Frustum) Planes() []Plane {
+	return f.planes[:]
+}
+
+func (f *Frustum) ContainsPoint(point *Vector3) bool {
+	for i := range f.planes {
+		if f.planes[i].SignedDistance(point) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ClassifySphere is a method that tells whether a sphere is inside, outside or partially inside the frustum.
+// A sphere near a corner of the frustum may be reported as Intersecting even though it is outside.
+func (f *Frustum) ClassifySphere(center *Vector3, radius float64) Classification {
+	result := Inside
+
+	for i := range f.planes {
+		switch f.planes[i].ClassifySphere(center, radius) {
+		case Outside:
+			return Outside
+		case Intersecting:
+			result = Intersecting
+		}
+	}
+
+	return result
+}
+
+// ClassifyAABB is a method that tells whether a box is inside, outside or partially inside the frustum.
+// Like ClassifySphere it errs on the side of Intersecting.
+func (f *Frustum) ClassifyAABB(box *AABB) Classification {
+	if box.IsEmpty() {
+		return Outside
+	}
+
+	result := Inside
+
+	for i := range f.planes {
+		switch f.planes[i].ClassifyAABB(box) {
+		case Outside:
+			return Outside
+		case Intersecting:
+			result = Intersecting
+		}
+	}
+
+	return result
+}
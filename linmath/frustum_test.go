@@ -0,0 +1,79 @@
+package linmath
+
+import "testing"
+
+func TestFrustumContainsPoint(t *testing.T) {
+	frustum := NewFrustum(NewPerspective(1, 1, 1, 0.1, 100))
+	tests := []struct {
+		inputPoint       *Vector3
+		expectedContains bool
+	}{
+		{NewVector3(0, 0, 1), true},
+		{NewVector3(0.49, 0.49, 1), true},
+		{NewVector3(0.51, 0, 1), false},
+		{NewVector3(0, -0.51, 1), false},
+		{NewVector3(0, 0, 0.05), false},
+		{NewVector3(0, 0, -1), false},
+		{NewVector3(0, 0, 99), true},
+		{NewVector3(0, 0, 101), false},
+	}
+
+	for _, ts := range tests {
+		contains := frustum.ContainsPoint(ts.inputPoint)
+
+		if contains != ts.expectedContains {
+			t.Fatalf("expected [%v] but have [%v] for [%v]", ts.expectedContains, contains, ts.inputPoint)
+		}
+	}
+}
+
+func TestFrustumClassifySphere(t *testing.T) {
+	// The camera stands at (0, 0, -10), so the frustum planes are moved along with it.
+	viewProjection := NewPerspective(1, 1, 1, 0.1, 100).Multiply(NewTranslation(0, 0, 10))
+	frustum := NewFrustum(viewProjection)
+
+	tests := []struct {
+		inputCenter            *Vector3
+		inputRadius            float64
+		expectedClassification Classification
+	}{
+		{NewVector3(0, 0, 0), 1, Inside},
+		{NewVector3(0, 0, -20), 1, Outside},
+		{NewVector3(0, 0, -10), 1, Intersecting},
+		{NewVector3(20, 0, 0), 1, Outside},
+		{NewVector3(5, 0, 0), 1, Intersecting},
+		{NewVector3(0, 0, 89.5), 1, Intersecting},
+		{NewVector3(0, 0, 95), 1, Outside},
+	}
+
+	for _, ts := range tests {
+		classification := frustum.ClassifySphere(ts.inputCenter, ts.inputRadius)
+
+		if classification != ts.expectedClassification {
+			t.Fatalf("expected [%v] but have [%v] for [%v %v]", ts.expectedClassification, classification, ts.inputCenter, ts.inputRadius)
+		}
+	}
+}
+
+func TestFrustumClassifyAABB(t *testing.T) {
+	frustum := NewFrustum(NewPerspective(1, 1, 1, 0.1, 100))
+	tests := []struct {
+		inputBox               *AABB
+		expectedClassification Classification
+	}{
+		{NewAABB(NewVector3(-1, -1, 9), NewVector3(1, 1, 11)), Inside},
+		{NewAABB(NewVector3(-1, -1, -3), NewVector3(1, 1, -1)), Outside},
+		{NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1)), Intersecting},
+		{NewAABB(NewVector3(10, -1, 2), NewVector3(12, 1, 4)), Outside},
+		{NewAABB(NewVector3(0, 0, 50), NewVector3(30, 1, 60)), Intersecting},
+		{NewEmptyAABB(), Outside},
+	}
+
+	for _, ts := range tests {
+		classification := frustum.ClassifyAABB(ts.inputBox)
+
+		if classification != ts.expectedClassification {
+			t.Fatalf("expected [%v] but have [%v] for [%v]", ts.expectedClassification, classification, ts.inputBox)
+		}
+	}
+}
@@ -16,6 +16,18 @@ func NewVector3(x, y, z float64) *Vector3 {
 	}
 }
 
+func (v *Vector3) X() float64 {
+	return v.x
+}
+
+func (v *Vector3) Y() float64 {
+	return v.y
+}
+
+func (v *Vector3) Z() float64 {
+	return v.z
+}
+
 func (v *Vector3) Length() float64 {
 	return math.Sqrt(v.x*v.x + v.y*v.y + v.z*v.z)
 }
@@ -54,6 +66,14 @@ func (v *Vector3) Dot(v2 *Vector3) float64 {
 	return v.x*v2.x + v.y*v2.y + v.z*v2.z
 }
 
+func (v *Vector3) Cross(v2 *Vector3) *Vector3 {
+	return NewVector3(
+		v.y*v2.z-v.z*v2.y,
+		v.z*v2.x-v.x*v2.z,
+		v.x*v2.y-v.y*v2.x,
+	)
+}
+
 func (v *Vector3) Add(v2 *Vector3) *Vector3 {
 	return NewVector3(
 		v.x+v2.x,
@@ -94,6 +114,22 @@ func (v *Vector3) Clamp(min, max float64) *Vector3 {
 	)
 }
 
+func (v *Vector3) Min(v2 *Vector3) *Vector3 {
+	return NewVector3(
+		math.Min(v.x, v2.x),
+		math.Min(v.y, v2.y),
+		math.Min(v.z, v2.z),
+	)
+}
+
+func (v *Vector3) Max(v2 *Vector3) *Vector3 {
+	return NewVector3(
+		math.Max(v.x, v2.x),
+		math.Max(v.y, v2.y),
+		math.Max(v.z, v2.z),
+	)
+}
+
 func (v *Vector3) Power(scalar float64) *Vector3 {
 	return NewVector3(
 		math.Pow(v.x, scalar),
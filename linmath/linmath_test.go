@@ -394,3 +394,54 @@ func TestSplat(t *testing.T) {
 		}
 	}
 }
+
+func TestCross(t *testing.T) {
+	tests := []struct {
+		inputVector1  *Vector3
+		inputVector2  *Vector3
+		expectedCross *Vector3
+	}{
+		{NewVector3(1, 0, 0), NewVector3(0, 1, 0), NewVector3(0, 0, 1)},
+		{NewVector3(0, 1, 0), NewVector3(0, 0, 1), NewVector3(1, 0, 0)},
+		{NewVector3(0, 0, 1), NewVector3(1, 0, 0), NewVector3(0, 1, 0)},
+		{NewVector3(0, 1, 0), NewVector3(1, 0, 0), NewVector3(0, 0, -1)},
+		{NewVector3(1, 2, 3), NewVector3(4, 5, 6), NewVector3(-3, 6, -3)},
+		{NewVector3(2, 2, 2), NewVector3(-1, -1, -1), NewVector3(0, 0, 0)},
+	}
+
+	for _, ts := range tests {
+		vector1 := ts.inputVector1
+		vector2 := ts.inputVector2
+		cross := vector1.Cross(vector2)
+
+		if cross.x != ts.expectedCross.x || cross.y != ts.expectedCross.y || cross.z != ts.expectedCross.z {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedCross, cross)
+		}
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	tests := []struct {
+		inputVector1 *Vector3
+		inputVector2 *Vector3
+		expectedMin  *Vector3
+		expectedMax  *Vector3
+	}{
+		{NewVector3(1, 2, 3), NewVector3(3, 2, 1), NewVector3(1, 2, 1), NewVector3(3, 2, 3)},
+		{NewVector3(-1, 5, 0), NewVector3(1, -5, 0), NewVector3(-1, -5, 0), NewVector3(1, 5, 0)},
+		{NewVector3(0, 0, 0), NewVector3(0, 0, 0), NewVector3(0, 0, 0), NewVector3(0, 0, 0)},
+	}
+
+	for _, ts := range tests {
+		min := ts.inputVector1.Min(ts.inputVector2)
+		max := ts.inputVector1.Max(ts.inputVector2)
+
+		if min.x != ts.expectedMin.x || min.y != ts.expectedMin.y || min.z != ts.expectedMin.z {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedMin, min)
+		}
+
+		if max.x != ts.expectedMax.x || max.y != ts.expectedMax.y || max.z != ts.expectedMax.z {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedMax, max)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package linmath
+
+import "math"
+
+// Matrix4 is a row-major 4x4 matrix used for affine and projective transforms.
+// Points are treated as column vectors, so a.Multiply(b) applies b first and then a.
+type Matrix4 struct {
+	m [4][4]float64
+}
+
+func NewMatrix4(rows [4][4]float64) *Matrix4 {
+	return &Matrix4{m: rows}
+}
+
+func NewIdentity() *Matrix4 {
+	return NewMatrix4([4][4]float64{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+func NewTranslation(x, y, z float64) *Matrix4 {
+	return NewMatrix4([4][4]float64{
+		{1, 0, 0, x},
+		{0, 1, 0, y},
+		{0, 0, 1, z},
+		{0, 0, 0, 1},
+	})
+}
+
+func NewScale(x, y, z float64) *Matrix4 {
+	return NewMatrix4([4][4]float64{
+		{x, 0, 0, 0},
+		{0, y, 0, 0},
+		{0, 0, z, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// NewRotationX is a function that returns a rotation around the X axis by the angle in radians.
+func NewRotationX(angle float64) *Matrix4 {
+	sin, cos := math.Sincos(angle)
+
+	return NewMatrix4([4][4]float64{
+		{1, 0, 0, 0},
+		{0, cos, -sin, 0},
+		{0, sin, cos, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// NewRotationY is a function that returns a rotation around the Y axis by the angle in radians.
+func NewRotationY(angle float64) *Matrix4 {
+	sin, cos := math.Sincos(angle)
+
+	return NewMatrix4([4][4]float64{
+		{cos, 0, sin, 0},
+		{0, 1, 0, 0},
+		{-sin, 0, cos, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// NewRotationZ is a function that returns a rotation around the Z axis by the angle in radians.
+func NewRotationZ(angle float64) *Matrix4 {
+	sin, cos := math.Sincos(angle)
+
+	return NewMatrix4([4][4]float64{
+		{cos, -sin, 0, 0},
+		{sin, cos, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// NewQuaternionRotation is a function that returns the rotation of the quaternion xi + yj + zk + w,
+// which is normalized first.
+func NewQuaternionRotation(x, y, z, w float64) *Matrix4 {
+	length := math.Sqrt(x*x + y*y + z*z + w*w)
+	x, y, z, w = x/length, y/length, z/length, w/length
+
+	return NewMatrix4([4][4]float64{
+		{1 - 2*(y*y+z*z), 2 * (x*y - z*w), 2 * (x*z + y*w), 0},
+		{2 * (x*y + z*w), 1 - 2*(x*x+z*z), 2 * (y*z - x*w), 0},
+		{2 * (x*z - y*w), 2 * (y*z + x*w), 1 - 2*(x*x+y*y), 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// NewLookRotation is a function that returns the rotation turning +Z towards the forward direction,
+// with +Y as close to up as possible. Its columns are the rotated X, Y and Z axes.
+func NewLookRotation(forward, up *Vector3) *Matrix4 {
+	z := forward.Normal()
+	x := up.Cross(z).Normal()
+	y := z.Cross(x)
+
+	return NewMatrix4([4][4]float64{
+		{x.x, y.x, z.x, 0},
+		{x.y, y.y, z.y, 0},
+		{x.z, y.z, z.z, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// NewPerspective is a function that returns the projection of a camera looking along +Z onto a viewport
+// of the given size at distance d. The visible volume between near and far maps to
+// -w <= x <= w, -w <= y <= w and 0 <= z <= w, with w equal to the depth of the point.
+func NewPerspective(d, viewportWidth, viewportHeight, near, far float64) *Matrix4 {
+	return NewMatrix4([4][4]float64{
+		{2 * d / viewportWidth, 0, 0, 0},
+		{0, 2 * d / viewportHeight, 0, 0},
+		{0, 0, far / (far - near), -far * near / (far - near)},
+		{0, 0, 1, 0},
+	})
+}
+
+func (m *Matrix4) At(row, column int) float64 {
+	return m.m[row][column]
+}
+
+func (m *Matrix4) Multiply(m2 *Matrix4) *Matrix4 {
+	var result Matrix4
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				result.m[i][j] += m.m[i][k] * m2.m[k][j]
+			}
+		}
+	}
+
+	return &result
+}
+
+// Lerp is a method that interpolates every element linearly between m (t = 0) and m2 (t = 1).
+// It is exact for translations and scales; rotations are sheared in between, so they need small steps.
+func (m *Matrix4) Lerp(m2 *Matrix4, t float64) *Matrix4 {
+	var result Matrix4
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			result.m[i][j] = m.m[i][j] + (m2.m[i][j]-m.m[i][j])*t
+		}
+	}
+
+	return &result
+}
+
+// MultiplyPoint is a method that transforms a point, including the translation part of the matrix.
+func (m *Matrix4) MultiplyPoint(v *Vector3) *Vector3 {
+	x := m.m[0][0]*v.x + m.m[0][1]*v.y + m.m[0][2]*v.z + m.m[0][3]
+	y := m.m[1][0]*v.x + m.m[1][1]*v.y + m.m[1][2]*v.z + m.m[1][3]
+	z := m.m[2][0]*v.x + m.m[2][1]*v.y + m.m[2][2]*v.z + m.m[2][3]
+	w := m.m[3][0]*v.x + m.m[3][1]*v.y + m.m[3][2]*v.z + m.m[3][3]
+
+	if w != 1 && w != 0 {
+		return NewVector3(x/w, y/w, z/w)
+	}
+
+	return NewVector3(x, y, z)
+}
+
+func (m *Matrix4) MultiplyVector4(v *Vector4) *Vector4 {
+	return NewVector4(
+		m.m[0][0]*v.x+m.m[0][1]*v.y+m.m[0][2]*v.z+m.m[0][3]*v.w,
+		m.m[1][0]*v.x+m.m[1][1]*v.y+m.m[1][2]*v.z+m.m[1][3]*v.w,
+		m.m[2][0]*v.x+m.m[2][1]*v.y+m.m[2][2]*v.z+m.m[2][3]*v.w,
+		m.m[3][0]*v.x+m.m[3][1]*v.y+m.m[3][2]*v.z+m.m[3][3]*v.w,
+	)
+}
+
+// MultiplyDirection is a method that transforms a direction, ignoring the translation part of the matrix.
+func (m *Matrix4) MultiplyDirection(v *Vector3) *Vector3 {
+	return NewVector3(
+		m.m[0][0]*v.x+m.m[0][1]*v.y+m.m[0][2]*v.z,
+		m.m[1][0]*v.x+m.m[1][1]*v.y+m.m[1][2]*v.z,
+		m.m[2][0]*v.x+m.m[2][1]*v.y+m.m[2][2]*v.z,
+	)
+}
+
+func (m *Matrix4) Transpose() *Matrix4 {
+	var result Matrix4
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			result.m[i][j] = m.m[j][i]
+		}
+	}
+
+	return &result
+}
+
+// Inverse is a method that computes the inverse matrix by Gauss-Jordan elimination with partial pivoting.
+// Returns false if the matrix is singular.
+func (m *Matrix4) Inverse() (*Matrix4, bool) {
+	a := m.m
+	inverse := NewIdentity().m
+
+	for column := 0; column < 4; column++ {
+		pivot := column
+		for row := column + 1; row < 4; row++ {
+			if math.Abs(a[row][column]) > math.Abs(a[pivot][column]) {
+				pivot = row
+			}
+		}
+
+		if math.Abs(a[pivot][column]) < 1e-12 {
+			return nil, false
+		}
+
+		a[column], a[pivot] = a[pivot], a[column]
+		inverse[column], inverse[pivot] = inverse[pivot], inverse[column]
+
+		scale := 1. / a[column][column]
+		for j := 0; j < 4; j++ {
+			a[column][j] *= scale
+			inverse[column][j] *= scale
+		}
+
+		for row := 0; row < 4; row++ {
+			if row == column {
+				continue
+			}
+
+			factor := a[row][column]
+			for j := 0; j < 4; j++ {
+				a[row][j] -= factor * a[column][j]
+				inverse[row][j] -= factor * inverse[column][j]
+			}
+		}
+	}
+
+	return NewMatrix4(inverse), true
+}
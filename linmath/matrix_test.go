@@ -0,0 +1,210 @@
+package linmath
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func equalVectors(v1, v2 *Vector3) bool {
+	return math.Abs(v1.x-v2.x) < epsilon && math.Abs(v1.y-v2.y) < epsilon && math.Abs(v1.z-v2.z) < epsilon
+}
+
+func equalMatrices(m1, m2 *Matrix4) bool {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if math.Abs(m1.m[i][j]-m2.m[i][j]) > epsilon {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestMultiplyPoint(t *testing.T) {
+	tests := []struct {
+		inputMatrix   *Matrix4
+		inputPoint    *Vector3
+		expectedPoint *Vector3
+	}{
+		{NewIdentity(), NewVector3(1, 2, 3), NewVector3(1, 2, 3)},
+		{NewTranslation(1, -2, 3), NewVector3(1, 2, 3), NewVector3(2, 0, 6)},
+		{NewScale(2, 3, -1), NewVector3(1, 2, 3), NewVector3(2, 6, -3)},
+		{NewRotationX(math.Pi / 2), NewVector3(0, 1, 0), NewVector3(0, 0, 1)},
+		{NewRotationY(math.Pi / 2), NewVector3(0, 0, 1), NewVector3(1, 0, 0)},
+		{NewRotationZ(math.Pi / 2), NewVector3(1, 0, 0), NewVector3(0, 1, 0)},
+		{NewTranslation(1, 0, 0).Multiply(NewScale(2, 2, 2)), NewVector3(1, 1, 1), NewVector3(3, 2, 2)},
+		{NewScale(2, 2, 2).Multiply(NewTranslation(1, 0, 0)), NewVector3(1, 1, 1), NewVector3(4, 2, 2)},
+	}
+
+	for _, ts := range tests {
+		point := ts.inputMatrix.MultiplyPoint(ts.inputPoint)
+
+		if !equalVectors(point, ts.expectedPoint) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedPoint, point)
+		}
+	}
+}
+
+func TestMultiplyDirection(t *testing.T) {
+	tests := []struct {
+		inputMatrix       *Matrix4
+		inputDirection    *Vector3
+		expectedDirection *Vector3
+	}{
+		{NewTranslation(1, -2, 3), NewVector3(1, 2, 3), NewVector3(1, 2, 3)},
+		{NewScale(2, 3, -1), NewVector3(1, 2, 3), NewVector3(2, 6, -3)},
+		{NewRotationZ(math.Pi), NewVector3(1, 0, 0), NewVector3(-1, 0, 0)},
+	}
+
+	for _, ts := range tests {
+		direction := ts.inputMatrix.MultiplyDirection(ts.inputDirection)
+
+		if !equalVectors(direction, ts.expectedDirection) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedDirection, direction)
+		}
+	}
+}
+
+func TestLookRotation(t *testing.T) {
+	tests := []struct {
+		inputForward      *Vector3
+		inputDirection    *Vector3
+		expectedDirection *Vector3
+	}{
+		{NewVector3(0, 0, 1), NewVector3(1, 2, 3), NewVector3(1, 2, 3)},
+		{NewVector3(0, 0, 5), NewVector3(0, 0, 1), NewVector3(0, 0, 1)},
+		{NewVector3(1, 0, 0), NewVector3(0, 0, 1), NewVector3(1, 0, 0)},
+		{NewVector3(1, 0, 0), NewVector3(1, 0, 0), NewVector3(0, 0, -1)},
+		{NewVector3(0, 0, -1), NewVector3(1, 1, 0), NewVector3(-1, 1, 0)},
+		{NewVector3(0, 1, 1), NewVector3(0, 1, 0), NewVector3(0, math.Sqrt2/2, -math.Sqrt2/2)},
+	}
+
+	for _, ts := range tests {
+		direction := NewLookRotation(ts.inputForward, NewVector3(0, 1, 0)).MultiplyDirection(ts.inputDirection)
+
+		if !equalVectors(direction, ts.expectedDirection) {
+			t.Fatalf("expected [%v] but have [%v] for [%v]", ts.expectedDirection, direction, ts.inputForward)
+		}
+	}
+}
+
+func TestQuaternionRotation(t *testing.T) {
+	half := math.Sqrt2 / 2
+
+	tests := []struct {
+		inputQuaternion [4]float64
+		expectedMatrix  *Matrix4
+	}{
+		{[4]float64{0, 0, 0, 1}, NewIdentity()},
+		{[4]float64{half, 0, 0, half}, NewRotationX(math.Pi / 2)},
+		{[4]float64{0, 2, 0, 2}, NewRotationY(math.Pi / 2)},
+		{[4]float64{0, 0, math.Sin(math.Pi / 6), math.Cos(math.Pi / 6)}, NewRotationZ(math.Pi / 3)},
+	}
+
+	for _, ts := range tests {
+		q := ts.inputQuaternion
+		if rotation := NewQuaternionRotation(q[0], q[1], q[2], q[3]); !equalMatrices(rotation, ts.expectedMatrix) {
+			t.Fatalf("expected [%v] but have [%v] for [%v]", ts.expectedMatrix, rotation, q)
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	matrix := NewMatrix4([4][4]float64{
+		{1, 2, 3, 4},
+		{5, 6, 7, 8},
+		{9, 10, 11, 12},
+		{13, 14, 15, 16},
+	})
+	expected := NewMatrix4([4][4]float64{
+		{1, 5, 9, 13},
+		{2, 6, 10, 14},
+		{3, 7, 11, 15},
+		{4, 8, 12, 16},
+	})
+
+	if transpose := matrix.Transpose(); !equalMatrices(transpose, expected) {
+		t.Fatalf("expected [%v] but have [%v]", expected, transpose)
+	}
+}
+
+func TestLerp(t *testing.T) {
+	tests := []struct {
+		inputMatrix1   *Matrix4
+		inputMatrix2   *Matrix4
+		inputT         float64
+		expectedMatrix *Matrix4
+	}{
+		{NewTranslation(0, 0, 0), NewTranslation(2, 4, -2), 0, NewTranslation(0, 0, 0)},
+		{NewTranslation(0, 0, 0), NewTranslation(2, 4, -2), 0.5, NewTranslation(1, 2, -1)},
+		{NewTranslation(0, 0, 0), NewTranslation(2, 4, -2), 1, NewTranslation(2, 4, -2)},
+		{NewScale(1, 1, 1), NewScale(3, 5, 1), 0.25, NewScale(1.5, 2, 1)},
+	}
+
+	for _, ts := range tests {
+		lerp := ts.inputMatrix1.Lerp(ts.inputMatrix2, ts.inputT)
+
+		if !equalMatrices(lerp, ts.expectedMatrix) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedMatrix, lerp)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	tests := []*Matrix4{
+		NewIdentity(),
+		NewTranslation(1, -2, 3),
+		NewScale(2, 4, 0.5),
+		NewRotationX(0.3).Multiply(NewRotationY(1.1)).Multiply(NewRotationZ(-0.7)),
+		NewTranslation(5, 0, -1).Multiply(NewRotationY(0.5)).Multiply(NewScale(3, 1, 2)),
+		NewMatrix4([4][4]float64{
+			{0, 1, 0, 0},
+			{1, 0, 0, 0},
+			{0, 0, 0, 1},
+			{0, 0, 1, 0},
+		}),
+	}
+
+	for _, matrix := range tests {
+		inverse, ok := matrix.Inverse()
+		if !ok {
+			t.Fatalf("expected [%v] to be invertible", matrix)
+		}
+
+		if product := matrix.Multiply(inverse); !equalMatrices(product, NewIdentity()) {
+			t.Fatalf("expected [%v] but have [%v]", NewIdentity(), product)
+		}
+	}
+
+	if _, ok := NewScale(1, 0, 1).Inverse(); ok {
+		t.Fatalf("expected singular matrix to have no inverse")
+	}
+}
+
+func TestPerspective(t *testing.T) {
+	projection := NewPerspective(1, 1, 1, 0.1, 100)
+	tests := []struct {
+		inputPoint    *Vector3
+		expectedPoint *Vector3
+	}{
+		{NewVector3(0, 0, 0.1), NewVector3(0, 0, 0)},
+		{NewVector3(0, 0, 100), NewVector3(0, 0, 1)},
+		{NewVector3(0.5, -0.5, 1), NewVector3(1, -1, 100/99.9*(1-0.1/1))},
+		{NewVector3(2, 1, 4), NewVector3(1, 0.5, 100/99.9*(1-0.1/4))},
+	}
+
+	for _, ts := range tests {
+		clip := projection.MultiplyVector4(NewVector4(ts.inputPoint.x, ts.inputPoint.y, ts.inputPoint.z, 1))
+
+		if clip.w != ts.inputPoint.z {
+			t.Fatalf("expected w [%v] but have [%v]", ts.inputPoint.z, clip.w)
+		}
+
+		if point := clip.PerspectiveDivide(); !equalVectors(point, ts.expectedPoint) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedPoint, point)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package linmath
+
+import "math"
+
+// Classification is the relation of a volume to a plane or a frustum.
+type Classification int
+
+const (
+	Outside Classification = iota
+	Intersecting
+	Inside
+)
+
+// Plane is the set of points p with normal·p + distance = 0. The normal is unit length,
+// so the signed distance of a point is its Euclidean distance, positive in front of the plane.
+type Plane struct {
+	normal   Vector3
+	distance float64
+}
+
+// NewPlane is a function that returns the plane a*x + b*y + c*z + d = 0, normalizing its coefficients.
+func NewPlane(a, b, c, d float64) *Plane {
+	length := math.Sqrt(a*a + b*b + c*c)
+
+	return &Plane{
+		normal:   *NewVector3(a/length, b/length, c/length),
+		distance: d / length,
+	}
+}
+
+// NewPlaneFromPoint is a function that returns the plane through the point facing along the normal.
+func NewPlaneFromPoint(normal, point *Vector3) *Plane {
+	n := normal.Normal()
+
+	return &Plane{
+		normal:   *n,
+		distance: -n.Dot(point),
+	}
+}
+
+func (p *Plane) Normal() *Vector3 {
+	return NewVector3(p.normal.x, p.normal.y, p.normal.z)
+}
+
+func (p *Plane) Distance() float64 {
+	return p.distance
+}
+
+func (p *Plane) SignedDistance(point *Vector3) float64 {
+	return p.normal.Dot(point) + p.distance
+}
+
+// ClassifySphere is a method that tells whether a sphere lies in front of the plane (Inside), behind it (Outside) or across it.
+func (p *Plane) ClassifySphere(center *Vector3, radius float64) Classification {
+	switch distance := p.SignedDistance(center); {
+	case distance < -radius:
+		return Outside
+	case distance > radius:
+		return Inside
+	default:
+		return Intersecting
+	}
+}
+
+// ClassifyAABB is a method that tells whether a box lies in front of the plane (Inside), behind it (Outside) or across it.
+// Only the corners farthest along and against the normal need to be tested.
+func (p *Plane) ClassifyAABB(box *AABB) Classification {
+	positive, negative := box.Max(), box.Min()
+
+	if p.normal.x < 0 {
+		positive.x, negative.x = negative.x, positive.x
+	}
+
+	if p.normal.y < 0 {
+		positive.y, negative.y = negative.y, positive.y
+	}
+
+	if p.normal.z < 0 {
+		positive.z, negative.z = negative.z, positive.z
+	}
+
+	switch {
+	case p.SignedDistance(positive) < 0:
+		return Outside
+	case p.SignedDistance(negative) > 0:
+		return Inside
+	default:
+		return Intersecting
+	}
+}
@@ -0,0 +1,72 @@
+package linmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPlaneSignedDistance(t *testing.T) {
+	tests := []struct {
+		inputPlane       *Plane
+		inputPoint       *Vector3
+		expectedDistance float64
+	}{
+		{NewPlane(0, 1, 0, 0), NewVector3(5, 2, -3), 2},
+		{NewPlane(0, 2, 0, 0), NewVector3(5, 2, -3), 2},
+		{NewPlane(0, 0, -1, 4), NewVector3(0, 0, 1), 3},
+		{NewPlane(1, 1, 0, 0), NewVector3(1, 1, 0), math.Sqrt2},
+		{NewPlaneFromPoint(NewVector3(0, 0, 3), NewVector3(0, 0, 2)), NewVector3(7, 7, 1), -1},
+	}
+
+	for _, ts := range tests {
+		distance := ts.inputPlane.SignedDistance(ts.inputPoint)
+
+		if math.Abs(distance-ts.expectedDistance) > epsilon {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedDistance, distance)
+		}
+	}
+}
+
+func TestPlaneClassifySphere(t *testing.T) {
+	plane := NewPlane(0, 1, 0, 0)
+	tests := []struct {
+		inputCenter            *Vector3
+		inputRadius            float64
+		expectedClassification Classification
+	}{
+		{NewVector3(0, 2, 0), 1, Inside},
+		{NewVector3(0, -2, 0), 1, Outside},
+		{NewVector3(0, 0.5, 0), 1, Intersecting},
+		{NewVector3(0, -0.5, 0), 1, Intersecting},
+	}
+
+	for _, ts := range tests {
+		classification := plane.ClassifySphere(ts.inputCenter, ts.inputRadius)
+
+		if classification != ts.expectedClassification {
+			t.Fatalf("expected [%v] but have [%v] for [%v %v]", ts.expectedClassification, classification, ts.inputCenter, ts.inputRadius)
+		}
+	}
+}
+
+func TestPlaneClassifyAABB(t *testing.T) {
+	plane := NewPlane(1, 1, 0, 0)
+	tests := []struct {
+		inputBox               *AABB
+		expectedClassification Classification
+	}{
+		{NewAABB(NewVector3(1, 1, -1), NewVector3(2, 2, 1)), Inside},
+		{NewAABB(NewVector3(-2, -2, -1), NewVector3(-1, -1, 1)), Outside},
+		{NewAABB(NewVector3(-1, -1, -1), NewVector3(1, 1, 1)), Intersecting},
+		{NewAABB(NewVector3(-3, 2, 0), NewVector3(-2, 3, 0)), Intersecting},
+		{NewAABB(NewVector3(-3, 0, 0), NewVector3(-2, 1, 0)), Outside},
+	}
+
+	for _, ts := range tests {
+		classification := plane.ClassifyAABB(ts.inputBox)
+
+		if classification != ts.expectedClassification {
+			t.Fatalf("expected [%v] but have [%v] for [%v]", ts.expectedClassification, classification, ts.inputBox)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package linmath
+
+import "math"
+
+// machineEpsilon is the relative rounding error of float64 arithmetic.
+const machineEpsilon = 0x1p-53
+
+// SolveQuadratic is a function that returns the real roots of a*t^2 + b*t + c = 0 in ascending order.
+// The root that the textbook formula computes by subtracting two close numbers is taken from
+// the citardauq form c/q instead, and the discriminant is evaluated with a fused multiply-add,
+// so both roots keep full precision. A double root is returned twice; ok is false if there are no real roots.
+func SolveQuadratic(a, b, c float64) (t0, t1 float64, ok bool) {
+	if a == 0 {
+		if b == 0 {
+			return 0, 0, false
+		}
+
+		return -c / b, -c / b, true
+	}
+
+	discriminant := differenceOfProducts(b, b, 4*a, c)
+	if discriminant < 0 {
+		return 0, 0, false
+	}
+
+	q := -0.5 * (b + math.Copysign(math.Sqrt(discriminant), b))
+	if q == 0 {
+		// b and the discriminant are both zero, so c is zero too and zero is a double root.
+		return 0, 0, true
+	}
+
+	t0, t1 = q/a, c/q
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+
+	return t0, t1, true
+}
+
+// differenceOfProducts is a function that computes a*b - c*d with Kahan's algorithm,
+// recovering the rounding error of c*d with a fused multiply-add.
+func differenceOfProducts(a, b, c, d float64) float64 {
+	cd := c * d
+	err := math.FMA(-c, d, cd)
+
+	return math.FMA(a, b, -cd) + err
+}
+
+// SolveCubic is a function that returns the distinct real roots of a*t^3 + b*t^2 + c*t + d = 0 in ascending order.
+// It is solved like SolveQuartic, and falls back to lower degrees when the leading coefficients are zero.
+func SolveCubic(a, b, c, d float64) []float64 {
+	return realRoots([]float64{a, b, c, d})
+}
+
+// SolveQuartic is a function that returns the distinct real roots of a*t^4 + b*t^3 + c*t^2 + d*t + e = 0
+// in ascending order. Closed forms such as Ferrari's lose most of their digits when roots are close together
+// or far apart, so the roots are isolated instead: between two neighbouring roots of the derivative the polynomial
+// is monotonic and holds at most one root, which is bracketed and refined by Newton's method kept inside the bracket.
+// A double root is found where the polynomial touches zero exactly; rounding may turn it into two close roots or none.
+func SolveQuartic(a, b, c, d, e float64) []float64 {
+	return realRoots([]float64{a, b, c, d, e})
+}
+
+// realRoots is a function that returns the distinct real roots of the polynomial whose coefficients are listed
+// from the highest power down, in ascending order.
+func realRoots(coefficients []float64) []float64 {
+	for len(coefficients) > 0 && coefficients[0] == 0 {
+		coefficients = coefficients[1:]
+	}
+
+	switch len(coefficients) {
+	case 0, 1:
+		return nil
+	case 2:
+		return []float64{-coefficients[1] / coefficients[0]}
+	case 3:
+		t0, t1, ok := SolveQuadratic(coefficients[0], coefficients[1], coefficients[2])
+		if !ok {
+			return nil
+		}
+
+		if t0 == t1 {
+			return []float64{t0}
+		}
+
+		return []float64{t0, t1}
+	}
+
+	n := len(coefficients) - 1
+	derivative := make([]float64, n)
+	for i := range derivative {
+		derivative[i] = coefficients[i] * float64(n-i)
+	}
+
+	// Every root lies within Cauchy's bound.
+	bound := 0.
+	for _, c := range coefficients[1:] {
+		bound = math.Max(bound, math.Abs(c/coefficients[0]))
+	}
+
+	bound++
+
+	points := []float64{-bound}
+	for _, p := range realRoots(derivative) {
+		if -bound < p && p < bound {
+			points = append(points, p)
+		}
+	}
+
+	points = append(points, bound)
+
+	// A value within the rounding error of Horner's rule is zero: where the polynomial only touches zero
+	// at a root of the derivative, rounding would otherwise decide between a double root and none.
+	values := make([]float64, len(points))
+	zeros := make([]bool, len(points))
+	for i, p := range points {
+		var magnitude float64
+		for _, c := range coefficients {
+			magnitude = magnitude*math.Abs(p) + math.Abs(c)
+		}
+
+		values[i], _ = evaluatePolynomial(coefficients, p)
+		zeros[i] = math.Abs(values[i]) <= 4*float64(n)*machineEpsilon*magnitude
+	}
+
+	var roots []float64
+	for i, p := range points {
+		if zeros[i] {
+			roots = append(roots, p)
+			continue
+		}
+
+		if i > 0 && !zeros[i-1] && (values[i-1] < 0) != (values[i] < 0) {
+			roots = append(roots, refineRoot(coefficients, points[i-1], p, values[i-1]))
+		}
+	}
+
+	return roots
+}
+
+// refineRoot is a function that finds the root of the polynomial between low and high, where it is monotonic
+// and has the sign of lowValue at low and the other sign at high. Newton steps that would leave the bracket
+// are replaced by bisection, so the root is always found.
+func refineRoot(coefficients []float64, low, high, lowValue float64) float64 {
+	x := (low + high) / 2
+
+	for i := 0; i < 200; i++ {
+		value, slope := evaluatePolynomial(coefficients, x)
+		if value == 0 {
+			return x
+		}
+
+		if (value < 0) == (lowValue < 0) {
+			low = x
+		} else {
+			high = x
+		}
+
+		next := x - value/slope
+		if slope == 0 || !(low <= next && next <= high) {
+			next = (low + high) / 2
+		}
+
+		if next == x || high-low <= 1e-16*math.Abs(x) {
+			return next
+		}
+
+		x = next
+	}
+
+	return x
+}
+
+// evaluatePolynomial is a function that returns the value and the derivative of the polynomial at x by Horner's rule.
+func evaluatePolynomial(coefficients []float64, x float64) (value, slope float64) {
+	for _, c := range coefficients {
+		slope = slope*x + value
+		value = value*x + c
+	}
+
+	return value, slope
+}
@@ -0,0 +1,120 @@
+package linmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolveQuadratic(t *testing.T) {
+	tests := []struct {
+		inputA     float64
+		inputB     float64
+		inputC     float64
+		expectedOk bool
+		expectedT0 float64
+		expectedT1 float64
+	}{
+		{1, -3, 2, true, 1, 2},
+		{1, 3, 2, true, -2, -1},
+		{2, 0, -8, true, -2, 2},
+		{1, -2, 1, true, 1, 1},
+		{1, 0, 0, true, 0, 0},
+		{1, 0, 1, false, 0, 0},
+		{0, 2, -4, true, 2, 2},
+		{0, 0, 1, false, 0, 0},
+		{-1, 0, 4, true, -2, 2},
+		// The textbook formula returns 0 for the small root because -b + sqrt(b^2 - 4ac) cancels.
+		{1, 1e9, 1, true, -1e9, -1e-9},
+		{1, -1e9, 1, true, 1e-9, 1e9},
+	}
+
+	for _, ts := range tests {
+		t0, t1, ok := SolveQuadratic(ts.inputA, ts.inputB, ts.inputC)
+
+		if ok != ts.expectedOk {
+			t.Fatalf("expected [%v] but have [%v] for [%v %v %v]", ts.expectedOk, ok, ts.inputA, ts.inputB, ts.inputC)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if !closeRelative(t0, ts.expectedT0) || !closeRelative(t1, ts.expectedT1) {
+			t.Fatalf("expected [%v %v] but have [%v %v]", ts.expectedT0, ts.expectedT1, t0, t1)
+		}
+	}
+}
+
+func closeRelative(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestSolveQuartic(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputA        float64
+		inputB        float64
+		inputC        float64
+		inputD        float64
+		inputE        float64
+		expectedRoots []float64
+	}{
+		{"four roots", 1, -10, 35, -50, 24, []float64{1, 2, 3, 4}},
+		{"no roots", 1, 0, 5, 0, 4, nil},
+		{"two roots", 1, 0, 0, 0, -16, []float64{-2, 2}},
+		// (t - 1)^2 (t - 2) (t - 3), where the polynomial only touches zero at 1.
+		{"double root", 1, -7, 17, -17, 6, []float64{1, 2, 3}},
+		{"quadruple root", 1, -4, 6, -4, 1, []float64{1}},
+		// (t - 0.001) (t - 1) (t - 1000) (t + 1000), roots six orders of magnitude apart.
+		{"far apart", 1, -1.001, -999999.999, 1001000, -1000, []float64{-1000, 0.001, 1, 1000}},
+		// (t - 1) (t - 1.000001) (t^2 + 1), two roots very close together.
+		{"close together", 1, -2.000001, 2.000001, -2.000001, 1.000001, []float64{1, 1.000001}},
+		{"cubic", 0, 1, -6, 11, -6, []float64{1, 2, 3}},
+		{"quadratic", 0, 0, 1, 0, -4, []float64{-2, 2}},
+		{"linear", 0, 0, 0, 2, -1, []float64{0.5}},
+	}
+
+	for _, ts := range tests {
+		roots := SolveQuartic(ts.inputA, ts.inputB, ts.inputC, ts.inputD, ts.inputE)
+
+		if len(roots) != len(ts.expectedRoots) {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expectedRoots, roots)
+		}
+
+		for i := range roots {
+			if math.Abs(roots[i]-ts.expectedRoots[i]) > 1e-9*math.Max(1, math.Abs(ts.expectedRoots[i])) {
+				t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expectedRoots, roots)
+			}
+		}
+	}
+}
+
+func TestSolveCubic(t *testing.T) {
+	tests := []struct {
+		inputA        float64
+		inputB        float64
+		inputC        float64
+		inputD        float64
+		expectedRoots []float64
+	}{
+		{1, -6, 11, -6, []float64{1, 2, 3}},
+		{1, 0, 0, -8, []float64{2}},
+		{1, 0, 0, 0, []float64{0}},
+		{2, -4, 2, 0, []float64{0, 1}},
+		{-1, 0, 1, 0, []float64{-1, 0, 1}},
+	}
+
+	for _, ts := range tests {
+		roots := SolveCubic(ts.inputA, ts.inputB, ts.inputC, ts.inputD)
+
+		if len(roots) != len(ts.expectedRoots) {
+			t.Fatalf("expected [%v] but have [%v] for [%v %v %v %v]", ts.expectedRoots, roots, ts.inputA, ts.inputB, ts.inputC, ts.inputD)
+		}
+
+		for i := range roots {
+			if !closeRelative(roots[i], ts.expectedRoots[i]) && math.Abs(roots[i]-ts.expectedRoots[i]) > 1e-12 {
+				t.Fatalf("expected [%v] but have [%v]", ts.expectedRoots, roots)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package linmath
+
+// Ray is a half-line starting at Origin and going along Direction.
+// Time is the moment within the shutter interval at which the ray is sent, used for motion blur.
+type Ray struct {
+	Origin    Vector3
+	Direction Vector3
+	Time      float64
+}
+
+func NewRay(origin, direction *Vector3, time float64) *Ray {
+	return &Ray{
+		Origin:    *origin,
+		Direction: *direction,
+		Time:      time,
+	}
+}
+
+// At is a method that returns the point of the ray at parameter t.
+func (r *Ray) At(t float64) *Vector3 {
+	return r.Origin.Add(r.Direction.MultiplyOnScalar(t))
+}
@@ -0,0 +1,24 @@
+package linmath
+
+import "testing"
+
+func TestAt(t *testing.T) {
+	tests := []struct {
+		inputRay      *Ray
+		inputT        float64
+		expectedPoint *Vector3
+	}{
+		{NewRay(NewVector3(0, 0, 0), NewVector3(0, 0, 1), 0), 0, NewVector3(0, 0, 0)},
+		{NewRay(NewVector3(0, 0, 0), NewVector3(0, 0, 1), 0), 2.5, NewVector3(0, 0, 2.5)},
+		{NewRay(NewVector3(1, 2, 3), NewVector3(2, -1, 0), 0.5), 2, NewVector3(5, 0, 3)},
+		{NewRay(NewVector3(1, 2, 3), NewVector3(2, -1, 0), 0.5), -1, NewVector3(-1, 3, 3)},
+	}
+
+	for _, ts := range tests {
+		point := ts.inputRay.At(ts.inputT)
+
+		if !equalVectors(point, ts.expectedPoint) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedPoint, point)
+		}
+	}
+}
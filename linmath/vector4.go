@@ -0,0 +1,53 @@
+package linmath
+
+// Vector4 is a point in homogeneous coordinates, as produced by a projection matrix.
+type Vector4 struct {
+	x float64
+	y float64
+	z float64
+	w float64
+}
+
+func NewVector4(x, y, z, w float64) *Vector4 {
+	return &Vector4{
+		x: x,
+		y: y,
+		z: z,
+		w: w,
+	}
+}
+
+func (v *Vector4) X() float64 {
+	return v.x
+}
+
+func (v *Vector4) Y() float64 {
+	return v.y
+}
+
+func (v *Vector4) Z() float64 {
+	return v.z
+}
+
+func (v *Vector4) W() float64 {
+	return v.w
+}
+
+func (v *Vector4) Dot(v2 *Vector4) float64 {
+	return v.x*v2.x + v.y*v2.y + v.z*v2.z + v.w*v2.w
+}
+
+// Lerp is a method that interpolates linearly between v (t = 0) and v2 (t = 1).
+func (v *Vector4) Lerp(v2 *Vector4, t float64) *Vector4 {
+	return NewVector4(
+		v.x+(v2.x-v.x)*t,
+		v.y+(v2.y-v.y)*t,
+		v.z+(v2.z-v.z)*t,
+		v.w+(v2.w-v.w)*t,
+	)
+}
+
+// PerspectiveDivide is a method that returns the Cartesian point x/w, y/w, z/w.
+func (v *Vector4) PerspectiveDivide() *Vector3 {
+	return NewVector3(v.x/v.w, v.y/v.w, v.z/v.w)
+}
@@ -0,0 +1,43 @@
+package linmath
+
+import "testing"
+
+func TestVector4Lerp(t *testing.T) {
+	tests := []struct {
+		inputVector1 *Vector4
+		inputVector2 *Vector4
+		inputT       float64
+		expectedLerp *Vector4
+	}{
+		{NewVector4(0, 0, 0, 1), NewVector4(2, 4, 6, 3), 0, NewVector4(0, 0, 0, 1)},
+		{NewVector4(0, 0, 0, 1), NewVector4(2, 4, 6, 3), 0.5, NewVector4(1, 2, 3, 2)},
+		{NewVector4(0, 0, 0, 1), NewVector4(2, 4, 6, 3), 1, NewVector4(2, 4, 6, 3)},
+	}
+
+	for _, ts := range tests {
+		lerp := ts.inputVector1.Lerp(ts.inputVector2, ts.inputT)
+
+		if *lerp != *ts.expectedLerp {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedLerp, lerp)
+		}
+	}
+}
+
+func TestPerspectiveDivide(t *testing.T) {
+	tests := []struct {
+		inputVector   *Vector4
+		expectedPoint *Vector3
+	}{
+		{NewVector4(1, 2, 3, 1), NewVector3(1, 2, 3)},
+		{NewVector4(2, 4, 6, 2), NewVector3(1, 2, 3)},
+		{NewVector4(-1, 0.5, 0, 0.5), NewVector3(-2, 1, 0)},
+	}
+
+	for _, ts := range tests {
+		point := ts.inputVector.PerspectiveDivide()
+
+		if !equalVectors(point, ts.expectedPoint) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedPoint, point)
+		}
+	}
+}
@@ -1,156 +1,435 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"github.com/UnTea/ComputerGraphics/linmath"
+	"github.com/UnTea/ComputerGraphics/render"
 	"image"
-	"image/color"
-	"image/png"
+	"io"
 	"log"
 	"math"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
-const (
-	screenWidth      = 600
-	screenHeight     = 600
-	viewportSize     = 1
-	projectionPlaneZ = 1
-)
+func main() {
+	backend := flag.String("backend", "raytrace", "renderer to use: raytrace or raster")
+	width := flag.Int("width", render.DefaultWidth, "width of the image in pixels")
+	height := flag.Int("height", render.DefaultHeight, "height of the image in pixels")
+	samples := flag.Int("samples", render.DefaultSamples, "rays per pixel when the camera has an aperture or an open shutter, passes in serve mode")
+	sampler := flag.String("sampler", "sobol", "sequence the ray tracer draws its samples from: sobol, halton, bluenoise or random")
+	seed := flag.Uint64("seed", 0, "seed of the sampler, the same seed renders the same image")
+	workers := flag.Int("workers", 0, "goroutines ray tracing the image, 0 uses every CPU")
+	wireframe := flag.Bool("wireframe", false, "draw only the triangle edges with the raster backend")
+	shading := flag.String("shading", "phong", "lighting of the raster backend: flat, gouraud or phong")
+	frames := flag.Int("frames", 0, "number of animation frames to render into the output directory, 0 renders only image.png")
+	fps := flag.Float64("fps", 24, "frames per second of the animation")
+	output := flag.String("output", "frames", "directory of the animation frames")
+	encoding := flag.String("animation", "", "also encode the frames into the output directory as animation.gif (gif) or animation.png (apng)")
+	sceneFile := flag.String("scene", "", "glTF 2.0 file (.gltf or .glb) to render instead of the demo scene")
+	meshFile := flag.String("mesh", "", "PLY or STL mesh (.ply or .stl) to place between the spheres of the demo scene")
+	fog := flag.Float64("fog", 0, "density of the fog the ray tracer fades distant objects into, 0 for none")
+	aovFile := flag.String("aovs", "", "OpenEXR file to also write the image and the depth, normal, albedo, ID, UV, hit count and motion passes of the ray tracer into")
+	subdivide := flag.Int("subdivide", 0, "levels of subdivision smoothing the -mesh, keeping edges sharper than 45 degrees")
+	scheme := flag.String("scheme", "loop", "subdivision scheme of -subdivide: loop for triangle meshes or catmull-clark for quad meshes")
+	address := flag.String("address", "localhost:8080", "address of the preview server in serve mode")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [serve]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "serve ray traces progressively and shows the image in the browser as it refines.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-type sphere struct {
-	center linmath.Vector3
-	radius float64
-	color  Color
-}
+	shadingMode, ok := render.ShadingModes[*shading]
+	if !ok {
+		log.Fatalf("unknown shading %q", *shading)
+	}
 
-func NewSphere(center linmath.Vector3, radius float64, color Color) *sphere {
-	return &sphere{center, radius, color}
-}
+	samplerKind, ok := render.Samplers[*sampler]
+	if !ok {
+		log.Fatalf("unknown sampler %q", *sampler)
+	}
 
-type Color struct {
-	r, g, b, a uint8
-}
+	backendKind, ok := render.Backends[*backend]
+	if !ok {
+		log.Fatalf("unknown backend %q", *backend)
+	}
 
-func NewColor(r, g, b, a uint8) *Color {
-	return &Color{r, g, b, a}
-}
+	subdivisionScheme, ok := render.SubdivisionSchemes[*scheme]
+	if !ok {
+		log.Fatalf("unknown subdivision scheme %q", *scheme)
+	}
+
+	encoder, ok := encoders[*encoding]
+	if !ok {
+		log.Fatalf("unknown animation format %q", *encoding)
+	}
+
+	// An interrupt cancels the render instead of killing the program, so no half-written file is left behind.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	options := &render.Options{
+		Backend:   backendKind,
+		Width:     *width,
+		Height:    *height,
+		Samples:   *samples,
+		Sampler:   samplerKind,
+		Seed:      *seed,
+		Workers:   *workers,
+		Wireframe: *wireframe,
+		Shading:   shadingMode,
+	}
+
+	renderImage := func(scene *render.Scene, label string) (image.Image, *render.Statistics, error) {
+		options.Progress = render.ProgressBar(os.Stderr, label)
+
+		img, stats, err := render.RenderContext(ctx, scene, options)
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+		}
+
+		return img, stats, err
+	}
+
+	if *sceneFile != "" && *frames > 0 {
+		log.Fatal("-frames animates the demo scene and cannot be combined with -scene")
+	}
+
+	if *aovFile != "" && *frames > 0 {
+		log.Fatal("-aovs writes the passes of a single image and cannot be combined with -frames")
+	}
+
+	scene := demoScene()
+	if *sceneFile != "" {
+		var warnings []string
+		var err error
+		if scene, warnings, err = render.LoadGLTF(*sceneFile); err != nil {
+			log.Fatal(err)
+		}
+
+		for _, warning := range warnings {
+			log.Print(warning)
+		}
+	}
+
+	if *meshFile != "" {
+		mesh, err := loadMesh(*meshFile, subdivisionScheme, *subdivide)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		scene.Objects = append(scene.Objects, mesh)
+	}
+
+	if *fog > 0 {
+		scene.Fog = render.NewFog(*render.NewColor(200, 210, 230, 255), *fog)
+	}
+
+	switch flag.Arg(0) {
+	case "":
+	case "serve":
+		if err := render.Serve(ctx, *address, scene, options); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *frames > 0 {
+		animation, err := demoAnimation()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = render.RenderSequence(*output, *frames, func(frame int) (image.Image, error) {
+			label := fmt.Sprintf("frame %d/%d", frame, *frames)
+
+			current, err := animation.Frame(scene, float64(frame-1) / *fps, 1 / *fps)
+			if err != nil {
+				return nil, err
+			}
+
+			img, stats, err := renderImage(current, label)
+			if err != nil {
+				return nil, err
+			}
+
+			fmt.Fprint(os.Stderr, stats)
+
+			return img, nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
 
-func PutPixel(pixels []*Color, x, y int, color *Color) {
-	//x, y = screenWidth/2+x, screenHeight/2-y
+		if encoder.encode != nil {
+			if err = encodeSequence(*output, *frames, *fps, encoder); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		return
+	}
+
+	if *aovFile != "" {
+		options.Progress = render.ProgressBar(os.Stderr, "rendering")
+
+		img, aovs, stats, err := render.RenderAOVs(ctx, scene, options)
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			log.Fatal(err)
+		}
+
+		start := time.Now()
+		if err = render.WritePNG("image.png", img); err != nil {
+			log.Fatal(err)
+		}
+
+		if err = writeEXR(*aovFile, img, aovs); err != nil {
+			log.Fatal(err)
+		}
+
+		printStats(stats, time.Since(start))
 
-	if x < 0 || x >= screenWidth || y < 0 || y >= screenHeight {
 		return
 	}
 
-	pixels[x+y*screenWidth] = color
+	img, stats, err := renderImage(scene, "rendering")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	if err = render.WritePNG("image.png", img); err != nil {
+		log.Fatal(err)
+	}
+
+	printStats(stats, time.Since(start))
 }
 
-// CanvasToViewPort is a function that converts 2D canvas coordinates to 3D viewport coordinates.
-func CanvasToViewPort(x, y float64) *linmath.Vector3 {
-	return linmath.NewVector3(
-		x*viewportSize/screenWidth,
-		y*viewportSize/screenHeight,
-		projectionPlaneZ,
-	)
+// printStats is a function that prints the statistics of a render followed by the time spent encoding its output.
+// Encoding is kept out of the phases of the render, so it does not lower the ray throughput.
+func printStats(stats *render.Statistics, encoding time.Duration) {
+	fmt.Fprint(os.Stderr, stats)
+	fmt.Fprintf(os.Stderr, "encoding:           %v\n", encoding.Round(time.Millisecond))
 }
 
-// IntersectRaySphere is a function that computes the intersection of a ray and a sphere.
-//	Returns the values of t for the intersections.
-func IntersectRaySphere(origin, direction *linmath.Vector3, sphere *sphere) (t1, t2 float64) {
-	co := origin.Subtraction(&sphere.center)
-	a := direction.Dot(direction)
-	b := 2 * co.Dot(direction)
-	c := co.Dot(co) - sphere.radius*sphere.radius
+// encoder writes a whole frame sequence into a single animated file.
+type encoder struct {
+	name   string
+	encode func(w io.Writer, frames []image.Image, fps float64) error
+}
+
+var encoders = map[string]encoder{
+	"":     {},
+	"gif":  {"animation.gif", render.EncodeGIF},
+	"apng": {"animation.png", render.EncodeAPNG},
+}
 
-	// at^2 + bt + c = 0
-	discriminant := b*b - 4*a*c
+// encodeSequence is a function that encodes the frames rendered into the directory as one animation next to them.
+func encodeSequence(directory string, frames int, fps float64, encoder encoder) error {
+	images, err := render.LoadSequence(directory, frames)
+	if err != nil {
+		return err
+	}
 
-	if discriminant < 0 {
-		return -1, -1
+	file, err := os.Create(filepath.Join(directory, encoder.name))
+	if err != nil {
+		return err
 	}
 
-	return (-b + math.Sqrt(discriminant)) / (2 * a), (-b - math.Sqrt(discriminant)) / (2 * a)
+	if err = encoder.encode(file, images, fps); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
 }
 
-func TraceRay(origin, direction *linmath.Vector3, minT, maxT float64, spheres []sphere) (color Color) {
-	closestT := math.Inf(1)
-	var closestSphere sphere
+// writeEXR is a function that encodes the image and its AOVs into a new OpenEXR file.
+func writeEXR(name string, img image.Image, aovs *render.AOVs) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
 
-	for _, s := range spheres {
-		t1, t2 := IntersectRaySphere(origin, direction, &s)
+	if err = render.EncodeEXR(file, img, aovs); err != nil {
+		_ = file.Close()
+		return err
+	}
 
-		if t1 < closestT && minT < t1 && t1 < maxT {
-			closestT, closestSphere = t1, s
-		}
+	return file.Close()
+}
 
-		if t2 < closestT && minT < t2 && t2 < maxT {
-			closestT, closestSphere = t2, s
-		}
+// loadMesh is a function that loads a PLY or STL mesh in gray and fits it into a unit box
+// standing on the floor between the red and the blue spheres, whatever the units of the file.
+// Subdivision runs before the mesh is fitted, so its bounds are those of the smooth surface.
+func loadMesh(name string, scheme render.SubdivisionScheme, levels int) (render.Object, error) {
+	load := render.LoadPLY
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ply":
+	case ".stl":
+		load = render.LoadSTL
+	default:
+		return nil, fmt.Errorf("%s: unknown mesh format, expected .ply or .stl", name)
+	}
+
+	mesh, err := load(name, *render.NewMaterial(*render.NewColor(200, 200, 200, 255), 100., 0., nil), render.SmoothNormals)
+	if err != nil {
+		return nil, err
 	}
 
-	if (sphere{}) == closestSphere {
-		return *NewColor(255, 255, 255, 255) // backgroundColor is white
+	if levels > 0 {
+		mesh = mesh.Subdivide(scheme, levels, linmath.Radians(45))
 	}
 
-	return closestSphere.color
+	bounds := mesh.Bounds()
+	size := bounds.Max().Subtraction(bounds.Min())
+	scale := 1. / math.Max(math.Max(size.X(), size.Y()), math.Max(size.Z(), 1e-9))
+	center := bounds.Min().Add(bounds.Max()).DivideOnScalar(2)
+
+	return render.NewInstance(
+		mesh,
+		linmath.NewTranslation(1.2, -1., 2.2).
+			Multiply(linmath.NewScale(scale, scale, scale)).
+			Multiply(linmath.NewTranslation(-center.X(), -bounds.Min().Y(), -center.Z())),
+		nil,
+	), nil
 }
 
-func main() {
-	spheres := []sphere{
-		*NewSphere(
-			*linmath.NewVector3(0., -1., 3.),
-			1.,
-			*NewColor(255, 0, 0, 255),
-		),
-		*NewSphere(
-			*linmath.NewVector3(2., 0., 4.),
-			1.,
-			*NewColor(0, 0, 255, 255),
-		),
-		*NewSphere(
-			*linmath.NewVector3(-2., 0., 4.),
-			1.,
-			*NewColor(0, 255, 0, 255),
-		),
-	}
-
-	img := image.NewRGBA(image.Rect(0, 0, screenWidth, screenHeight))
-	var pixels = make([]*Color, screenWidth*screenHeight)
-
-	for x := 0; x < screenWidth; x++ {
-		for y := 0; y < screenHeight; y++ {
-			direction := CanvasToViewPort(float64(x)-screenWidth/2, float64(y)-screenHeight/2)
-			c := TraceRay(linmath.NewVector3(0., 0., 0.), direction, 0., math.Inf(1), spheres)
-			y2 := screenHeight - y - 1
-			PutPixel(pixels, x, y2, &c)
-			img.Set(
-				x,
-				y2,
-				color.NRGBA{
-					R: pixels[x+y2*screenWidth].r,
-					G: pixels[x+y2*screenWidth].g,
-					B: pixels[x+y2*screenWidth].b,
-					A: 255,
+// demoScene is a function that returns the scene rendered by the CLI.
+func demoScene() *render.Scene {
+	// unitSphere is shared by every instance below; each instance only adds its own transform.
+	unitSphere := render.NewSphere(
+		*linmath.NewVector3(0., 0., 0.),
+		1.,
+		*render.NewMaterial(*render.NewColor(255, 255, 0, 255), 1000., 0., nil),
+	)
+
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewSphere(
+				*linmath.NewVector3(0., -1., 3.),
+				1.,
+				*render.NewMaterial(*render.NewColor(255, 0, 0, 255), 500., 0.2, nil),
+			),
+			render.NewSphere(
+				*linmath.NewVector3(2., 0., 4.),
+				1.,
+				*render.NewMaterial(*render.NewColor(0, 0, 255, 255), 500., 0.3, nil),
+			),
+			render.NewSphere(
+				*linmath.NewVector3(-2., 0., 4.),
+				1.,
+				*render.NewMaterial(*render.NewColor(0, 255, 0, 255), 10., 0.4, render.NewCheckerTexture(16, 8)),
+			),
+			render.NewMesh(
+				[]linmath.Vector3{
+					*linmath.NewVector3(-50., -1., -50.),
+					*linmath.NewVector3(-50., -1., 50.),
+					*linmath.NewVector3(50., -1., 50.),
+					*linmath.NewVector3(50., -1., -50.),
 				},
-			)
-		}
+				nil,
+				[][2]float64{{0., 0.}, {0., 50.}, {50., 50.}, {50., 0.}},
+				nil,
+				[][3]int{{0, 1, 2}, {0, 2, 3}},
+				*render.NewMaterial(*render.NewColor(255, 255, 0, 255), 1000., 0.5, render.NewCheckerTexture(8, 2)),
+			),
+			render.NewInstance(
+				unitSphere,
+				linmath.NewTranslation(0., 1.5, 5.).Multiply(linmath.NewScale(1.5, 0.4, 0.4)),
+				nil,
+			),
+			render.NewMovingInstance(
+				unitSphere,
+				linmath.NewTranslation(-0.3, 2.5, 6.).
+					Multiply(linmath.NewRotationZ(linmath.Radians(30))).
+					Multiply(linmath.NewScale(0.8, 0.3, 0.3)),
+				linmath.NewTranslation(0.5, 2.5, 6.).
+					Multiply(linmath.NewRotationZ(linmath.Radians(30))).
+					Multiply(linmath.NewScale(0.8, 0.3, 0.3)),
+				render.NewMaterial(*render.NewColor(255, 0, 255, 255), 100., 0., nil),
+			),
+			render.NewDifference(
+				render.NewSphere(
+					*linmath.NewVector3(0., 0.5, 8.),
+					1.,
+					*render.NewMaterial(*render.NewColor(0, 255, 255, 255), 50., 0., nil),
+				),
+				render.NewSphere(
+					*linmath.NewVector3(0.6, 0.9, 7.2),
+					0.7,
+					*render.NewMaterial(*render.NewColor(255, 128, 0, 255), -1., 0., nil),
+				),
+			),
+		},
+		Lights: []render.Light{
+			*render.NewAmbientLight(0.2),
+			*render.NewPointLight(0.6, linmath.NewVector3(2., 1., 0.)),
+			*render.NewDirectionalLight(0.2, linmath.NewVector3(1., 4., 4.)),
+		},
+		// The spheres at z = 4 are in focus; the hexagonal aperture gives the blur of the rest its shape.
+		// The magenta ellipsoid moves while the shutter is open for the whole frame.
+		Camera: render.NewCamera(linmath.NewVector3(0., 0., 0.), linmath.NewIdentity(), 0.08, 4., 6, 0., 1.),
 	}
+}
 
-	file, err := os.Create("image.png")
-	if err != nil {
-		log.Fatal(err)
+// demoAnimation is a function that returns a turntable of the demo scene: the camera circles the spheres
+// while rising a little, the blue sphere bounces, and the point light dims and moves.
+// Its first frame matches the still image.
+func demoAnimation() (*render.Animation, error) {
+	const duration = 4.
+
+	var orbit []*render.Keyframe
+	for i := 0; i <= 8; i++ {
+		angle := linmath.Radians(float64(i) * 45)
+		orbit = append(orbit, render.NewKeyframe(duration*float64(i)/8, 5*math.Sin(angle), 0.5*(1-math.Cos(angle)), 5-5*math.Cos(angle)))
 	}
 
-	if err = png.Encode(file, img); err != nil {
-		err = file.Close()
-		if err != nil {
-			return
+	// The sphere lands every second; both handles of a landing point up, so it leaves the ground as fast as it hits it.
+	var bounce []*render.Keyframe
+	for i := 0.; i <= duration; i++ {
+		bounce = append(bounce, render.NewBezierKeyframe(i, []float64{0., 0., 0.}, []float64{0., 1., 0.}, []float64{0., 1., 0.}))
+	}
+
+	// track is a function that returns a track, keeping the first error for the end so the animation reads as one literal.
+	var err error
+	track := func(interpolation render.Interpolation, keys ...*render.Keyframe) *render.Track {
+		t, trackErr := render.NewTrack(interpolation, keys...)
+		if err == nil {
+			err = trackErr
 		}
 
-		log.Fatal(err)
+		return t
 	}
 
-	if err = file.Close(); err != nil {
-		log.Fatal(err)
+	animation := &render.Animation{
+		CameraPosition: track(render.CatmullRomInterpolation, orbit...),
+		CameraTarget:   track(render.LinearInterpolation, render.NewKeyframe(0, 0., 0., 5.)),
+		LightIntensity: map[int]*render.Track{
+			1: track(render.LinearInterpolation, render.NewKeyframe(0, 0.6), render.NewKeyframe(duration/2, 0.3), render.NewKeyframe(duration, 0.6)),
+		},
+		LightPosition: map[int]*render.Track{
+			1: track(
+				render.BezierInterpolation,
+				render.NewBezierKeyframe(0, []float64{2., 1., 0.}, nil, []float64{4., 3., 2.}),
+				render.NewBezierKeyframe(duration, []float64{2., 1., 0.}, []float64{0., 3., 2.}, nil),
+			),
+		},
+		ObjectTransform: map[int]*render.TransformTrack{
+			1: {Translation: track(render.BezierInterpolation, bounce...)},
+		},
 	}
+
+	return animation, err
 }
@@ -0,0 +1,101 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// Mesh is an indexed triangle mesh. Triangles index into Vertices and, when it is not empty, into Normals,
+// which has a normal per vertex. Triangles are wound so that (v1 - v0) x (v2 - v0) points out of the surface.
+type Mesh struct {
+	Vertices  []linmath.Vector3
+	Normals   []linmath.Vector3
+	Triangles [][3]int
+}
+
+// Bounds is a method that returns the smallest axis-aligned box around the vertices.
+func (m *Mesh) Bounds() *linmath.AABB {
+	bounds := linmath.NewEmptyAABB()
+	for i := range m.Vertices {
+		bounds = bounds.Extend(&m.Vertices[i])
+	}
+
+	return bounds
+}
+
+// BoundingSphere is a method that returns a sphere around the vertices using Ritter's algorithm: a first guess
+// spans two vertices far from each other and grows just enough to take in every vertex left outside it.
+// The sphere is at most a few percent larger than the smallest one.
+func (m *Mesh) BoundingSphere() (*linmath.Vector3, float64) {
+	if len(m.Vertices) == 0 {
+		return linmath.NewVector3(0, 0, 0), 0
+	}
+
+	farthest := func(from *linmath.Vector3) *linmath.Vector3 {
+		result, distance := &m.Vertices[0], -1.
+		for i := range m.Vertices {
+			if d := m.Vertices[i].Subtraction(from).Length(); d > distance {
+				result, distance = &m.Vertices[i], d
+			}
+		}
+
+		return result
+	}
+
+	a := farthest(&m.Vertices[0])
+	b := farthest(a)
+
+	center := a.Add(b).DivideOnScalar(2)
+	radius := b.Subtraction(a).Length() / 2
+
+	for i := range m.Vertices {
+		offset := m.Vertices[i].Subtraction(center)
+
+		// The new sphere touches the vertex and the far side of the old one.
+		if d := offset.Length(); d > radius {
+			radius = (radius + d) / 2
+			center = m.Vertices[i].Subtraction(offset.MultiplyOnScalar(radius / d))
+		}
+	}
+
+	return center, radius
+}
+
+// Center is a method that returns the mesh moved so that the center of its bounding box is at the origin.
+// The triangles and normals are shared with m.
+func (m *Mesh) Center() *Mesh {
+	if len(m.Vertices) == 0 {
+		return m.transform(linmath.NewVector3(0, 0, 0), 1)
+	}
+
+	return m.transform(m.Bounds().Center().Negative(), 1)
+}
+
+// Normalize is a method that returns the mesh centered and uniformly scaled so that its bounding box
+// fits between -1 and 1 on every axis, which makes meshes of any units show at the same size.
+// The triangles and normals, which a uniform scale doesn't change, are shared with m.
+func (m *Mesh) Normalize() *Mesh {
+	if len(m.Vertices) == 0 {
+		return m.transform(linmath.NewVector3(0, 0, 0), 1)
+	}
+
+	bounds := m.Bounds()
+	size := bounds.Max().Subtraction(bounds.Min())
+
+	scale := 1.
+	if extent := math.Max(size.X(), math.Max(size.Y(), size.Z())); extent > 0 {
+		scale = 2 / extent
+	}
+
+	return m.transform(bounds.Center().Negative(), scale)
+}
+
+// transform is a method that returns the mesh with every vertex moved by the offset and then scaled.
+func (m *Mesh) transform(offset *linmath.Vector3, scale float64) *Mesh {
+	vertices := make([]linmath.Vector3, len(m.Vertices))
+	for i := range m.Vertices {
+		vertices[i] = *m.Vertices[i].Add(offset).MultiplyOnScalar(scale)
+	}
+
+	return &Mesh{vertices, m.Normals, m.Triangles}
+}
@@ -0,0 +1,99 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func equalVectors(v1, v2 *linmath.Vector3) bool {
+	return v1.Subtraction(v2).Length() < epsilon
+}
+
+// cube is a function that returns a cube from (0, 0, 0) to (2, 2, 2) wound counterclockwise seen from outside.
+func cube() *Mesh {
+	return &Mesh{
+		Vertices: []linmath.Vector3{
+			*linmath.NewVector3(0, 0, 0), *linmath.NewVector3(2, 0, 0), *linmath.NewVector3(2, 2, 0), *linmath.NewVector3(0, 2, 0),
+			*linmath.NewVector3(0, 0, 2), *linmath.NewVector3(2, 0, 2), *linmath.NewVector3(2, 2, 2), *linmath.NewVector3(0, 2, 2),
+		},
+		Triangles: [][3]int{
+			{0, 3, 2}, {0, 2, 1}, // z = 0
+			{4, 5, 6}, {4, 6, 7}, // z = 2
+			{0, 1, 5}, {0, 5, 4}, // y = 0
+			{3, 7, 6}, {3, 6, 2}, // y = 2
+			{0, 4, 7}, {0, 7, 3}, // x = 0
+			{1, 2, 6}, {1, 6, 5}, // x = 2
+		},
+	}
+}
+
+func TestBounds(t *testing.T) {
+	bounds := cube().Bounds()
+
+	if !equalVectors(bounds.Min(), linmath.NewVector3(0, 0, 0)) || !equalVectors(bounds.Max(), linmath.NewVector3(2, 2, 2)) {
+		t.Fatalf("expected [[0 0 0] [2 2 2]] but have [%v]", bounds)
+	}
+
+	if !(&Mesh{}).Bounds().IsEmpty() {
+		t.Fatalf("expected the bounds of an empty mesh to be empty")
+	}
+}
+
+func TestBoundingSphere(t *testing.T) {
+	tests := []struct {
+		inputVertices  []linmath.Vector3
+		expectedRadius float64
+	}{
+		{cube().Vertices, math.Sqrt(3)},
+		{[]linmath.Vector3{*linmath.NewVector3(-1, 0, 0), *linmath.NewVector3(1, 0, 0), *linmath.NewVector3(0, 0.5, 0)}, 1},
+		{[]linmath.Vector3{*linmath.NewVector3(3, 4, 5)}, 0},
+	}
+
+	for _, ts := range tests {
+		center, radius := (&Mesh{Vertices: ts.inputVertices}).BoundingSphere()
+
+		// Ritter's sphere is not always the smallest one, but it must hold every vertex and be close to its size.
+		for i := range ts.inputVertices {
+			if d := ts.inputVertices[i].Subtraction(center).Length(); d > radius+epsilon {
+				t.Fatalf("expected [%v] inside the sphere [%v %v] but it is [%v] away", ts.inputVertices[i], center, radius, d)
+			}
+		}
+
+		if radius > ts.expectedRadius*1.05+epsilon {
+			t.Fatalf("expected a radius of about [%v] but have [%v]", ts.expectedRadius, radius)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	m := &Mesh{
+		Vertices:  []linmath.Vector3{*linmath.NewVector3(10, 20, 30), *linmath.NewVector3(14, 20, 30), *linmath.NewVector3(10, 22, 31)},
+		Triangles: [][3]int{{0, 1, 2}},
+	}
+
+	tests := []struct {
+		name        string
+		inputMesh   *Mesh
+		expectedMin *linmath.Vector3
+		expectedMax *linmath.Vector3
+	}{
+		{"center", m.Center(), linmath.NewVector3(-2, -1, -0.5), linmath.NewVector3(2, 1, 0.5)},
+		{"normalize", m.Normalize(), linmath.NewVector3(-1, -0.5, -0.25), linmath.NewVector3(1, 0.5, 0.25)},
+		{"point", (&Mesh{Vertices: []linmath.Vector3{*linmath.NewVector3(1, 2, 3)}}).Normalize(), linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 0)},
+	}
+
+	for _, ts := range tests {
+		bounds := ts.inputMesh.Bounds()
+
+		if !equalVectors(bounds.Min(), ts.expectedMin) || !equalVectors(bounds.Max(), ts.expectedMax) {
+			t.Fatalf("%s: expected [%v %v] but have [%v]", ts.name, ts.expectedMin, ts.expectedMax, bounds)
+		}
+	}
+
+	if !equalVectors(&m.Vertices[0], linmath.NewVector3(10, 20, 30)) {
+		t.Fatalf("expected the original mesh to stay where it was but have [%v]", m.Vertices[0])
+	}
+}
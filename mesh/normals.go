@@ -0,0 +1,91 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// GenerateNormals is a method that returns the mesh with a normal for each vertex: the normals of the triangles
+// around it, weighted by their angle at the vertex. Unlike weighting by area, this doesn't depend on how
+// a surface happens to be split into triangles. Triangles whose normals are more than creaseAngle radians
+// apart don't blend, so a vertex on a sharp edge is split into a vertex for each side of it; a crease angle
+// of Pi smooths everything. It returns the mesh and, for each of its vertices, the index of the vertex of m
+// it comes from. Vertices keep their index and split ones are appended.
+func (m *Mesh) GenerateNormals(creaseAngle float64) (*Mesh, []int) {
+	faces := make([]linmath.Vector3, len(m.Triangles))
+	angles := make([][3]float64, len(m.Triangles))
+	corners := make([][][2]int, len(m.Vertices))
+
+	for i, t := range m.Triangles {
+		v0, v1, v2 := &m.Vertices[t[0]], &m.Vertices[t[1]], &m.Vertices[t[2]]
+		if face := v1.Subtraction(v0).Cross(v2.Subtraction(v0)); face.Length() > 0 {
+			faces[i] = *face.Normal()
+		}
+
+		for k := range t {
+			angles[i][k] = angle(&m.Vertices[t[k]], &m.Vertices[t[(k+1)%3]], &m.Vertices[t[(k+2)%3]])
+			corners[t[k]] = append(corners[t[k]], [2]int{i, k})
+		}
+	}
+
+	smooth := creaseAngle >= math.Pi
+	minCos := math.Cos(creaseAngle)
+
+	vertices := append([]linmath.Vector3(nil), m.Vertices...)
+	normals := make([]linmath.Vector3, len(m.Vertices))
+	origins := make([]int, len(m.Vertices))
+	for i := range origins {
+		origins[i] = i
+	}
+
+	type split struct {
+		vertex int
+		normal linmath.Vector3
+	}
+
+	splits := map[split]int{}
+	claimed := make([]bool, len(m.Vertices))
+	triangles := make([][3]int, len(m.Triangles))
+
+	for i, t := range m.Triangles {
+		for k, v := range t {
+			// Every corner of a vertex sums the same triangles in the same order,
+			// so corners on the same side of all creases get exactly the same normal.
+			var sum linmath.Vector3
+			for _, c := range corners[v] {
+				if c[0] == i || smooth || faces[i].Dot(&faces[c[0]]) >= minCos {
+					sum = *sum.Add(faces[c[0]].MultiplyOnScalar(angles[c[0]][c[1]]))
+				}
+			}
+
+			if sum.Length() > 0 {
+				sum = *sum.Normal()
+			}
+
+			index, ok := splits[split{v, sum}]
+			if !ok {
+				// The first normal of a vertex stays with it and every other one makes a copy of the vertex.
+				index = v
+				if claimed[v] {
+					index = len(vertices)
+					vertices, normals, origins = append(vertices, m.Vertices[v]), append(normals, linmath.Vector3{}), append(origins, v)
+				}
+
+				claimed[v] = true
+				normals[index] = sum
+				splits[split{v, sum}] = index
+			}
+
+			triangles[i][k] = index
+		}
+	}
+
+	return &Mesh{vertices, normals, triangles}, origins
+}
+
+// angle is a function that returns the angle at the corner between the edges to the other two corners.
+func angle(corner, v1, v2 *linmath.Vector3) float64 {
+	edge1, edge2 := v1.Subtraction(corner), v2.Subtraction(corner)
+
+	return math.Atan2(edge1.Cross(edge2).Length(), edge1.Dot(edge2))
+}
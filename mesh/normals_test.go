@@ -0,0 +1,76 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestGenerateNormals(t *testing.T) {
+	tests := []struct {
+		name             string
+		inputCrease      float64
+		expectedVertices int
+	}{
+		// Every corner of the cube is shared by three faces at right angles.
+		{"smooth", math.Pi, 8},
+		{"crease", linmath.Radians(60), 24},
+		{"just over the crease", linmath.Radians(91), 8},
+	}
+
+	for _, ts := range tests {
+		c := cube()
+		result, origins := c.GenerateNormals(ts.inputCrease)
+
+		if len(result.Vertices) != ts.expectedVertices || len(result.Normals) != ts.expectedVertices || len(origins) != ts.expectedVertices {
+			t.Fatalf("%s: expected [%v] vertices but have [%v], [%v] normals and [%v] origins", ts.name, ts.expectedVertices, len(result.Vertices), len(result.Normals), len(origins))
+		}
+
+		// The vertices of the cube keep their index.
+		for i := range c.Vertices {
+			if origins[i] != i || result.Vertices[i] != c.Vertices[i] {
+				t.Fatalf("%s: expected vertex [%v] to keep its index but it comes from [%v]", ts.name, i, origins[i])
+			}
+		}
+
+		center := linmath.NewVector3(1, 1, 1)
+
+		for i, tr := range result.Triangles {
+			for k, v := range tr {
+				if origins[v] != c.Triangles[i][k] {
+					t.Fatalf("%s: expected corner [%v %v] to come from [%v] but have [%v]", ts.name, i, k, c.Triangles[i][k], origins[v])
+				}
+
+				out := result.Vertices[v].Subtraction(center).Normal()
+				if len(result.Vertices) == 8 && !equalVectors(&result.Normals[v], out) {
+					t.Fatalf("%s: expected the normal [%v] but have [%v]", ts.name, out, result.Normals[v])
+				}
+
+				// A split vertex takes the normal of its face, which is the axis it is farthest along from the center.
+				face := c.Vertices[c.Triangles[i][1]].Subtraction(&c.Vertices[c.Triangles[i][0]]).
+					Cross(c.Vertices[c.Triangles[i][2]].Subtraction(&c.Vertices[c.Triangles[i][0]])).Normal()
+				if len(result.Vertices) == 24 && !equalVectors(&result.Normals[v], face) {
+					t.Fatalf("%s: expected the normal [%v] but have [%v]", ts.name, face, result.Normals[v])
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateNormalsAngleWeighted(t *testing.T) {
+	// The apex of a square pyramid. Splitting one side into more triangles changes the area around
+	// the apex on that side but not the angle, so the normal still points straight up.
+	m := &Mesh{
+		Vertices: []linmath.Vector3{
+			*linmath.NewVector3(-1, 0, -1), *linmath.NewVector3(1, 0, -1), *linmath.NewVector3(1, 0, 1), *linmath.NewVector3(-1, 0, 1),
+			*linmath.NewVector3(0, 1, 0), *linmath.NewVector3(0, 0, -1), *linmath.NewVector3(0.5, 0, -1),
+		},
+		Triangles: [][3]int{{0, 4, 5}, {5, 4, 6}, {6, 4, 1}, {1, 4, 2}, {2, 4, 3}, {3, 4, 0}},
+	}
+
+	result, _ := m.GenerateNormals(math.Pi)
+
+	if !equalVectors(&result.Normals[4], linmath.NewVector3(0, 1, 0)) {
+		t.Fatalf("expected [0 1 0] but have [%v]", result.Normals[4])
+	}
+}
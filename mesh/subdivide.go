@@ -0,0 +1,373 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"sort"
+)
+
+// Weight is the share of a vertex of the coarse mesh in a vertex made by subdivision.
+// The weights of a vertex add up to 1, so other per-vertex data such as texture coordinates
+// or colors can be interpolated with them the same way as the positions.
+type Weight struct {
+	Vertex int
+	Weight float64
+}
+
+// mix is a weighted sum of the vertices of the level being subdivided.
+type mix map[int]float64
+
+// add is a method that adds the other sum scaled by the factor.
+func (m mix) add(other mix, factor float64) {
+	for v, w := range other {
+		m[v] += w * factor
+	}
+}
+
+// subdivision is a polygon mesh being subdivided level after level. Vertices are kept as weights of the vertices
+// of the original mesh, and edges are keyed by their ends, lower index first.
+type subdivision struct {
+	original []linmath.Vector3
+	faces    [][]int
+	weights  [][]Weight
+	creases  map[[2]int]bool
+
+	edges       map[[2]int]int
+	ends        [][2]int
+	edgeFaces   [][]int
+	vertexEdges [][]int
+	vertexFaces [][]int
+}
+
+// Loop is a method that smooths the triangle mesh by Loop subdivision: every level splits each triangle into four
+// and moves the vertices towards a smooth limit surface. Edges where the triangles meet at more than creaseAngle
+// radians, as well as boundary edges, are creases that subdivide as curves of their own and stay sharp;
+// a vertex on more than two of them is a corner that doesn't move. A crease angle of Pi creases only the boundary.
+// It returns the subdivided mesh, without normals, and for each of its vertices the weights of the vertices of m.
+func (m *Mesh) Loop(levels int, creaseAngle float64) (*Mesh, [][]Weight) {
+	faces := make([][]int, len(m.Triangles))
+	for i, t := range m.Triangles {
+		faces[i] = []int{t[0], t[1], t[2]}
+	}
+
+	s := newSubdivision(m.Vertices, faces, creaseAngle)
+	for i := 0; i < levels; i++ {
+		s.loop()
+	}
+
+	return s.mesh()
+}
+
+// CatmullClark is a function that smooths a polygon mesh by Catmull–Clark subdivision: every level splits each face
+// of n vertices into n quads around its center. The faces list the indices of their vertices in the same winding
+// as the triangles of a Mesh. Creases and corners are handled like in Loop. It returns the subdivided mesh,
+// split into triangles and without normals, and for each of its vertices the weights of the original vertices.
+func CatmullClark(vertices []linmath.Vector3, faces [][]int, levels int, creaseAngle float64) (*Mesh, [][]Weight) {
+	s := newSubdivision(vertices, faces, creaseAngle)
+	for i := 0; i < levels; i++ {
+		s.catmullClark()
+	}
+
+	return s.mesh()
+}
+
+// newSubdivision is a function that returns the level 0 of a subdivision with the edges sharper than the crease angle
+// marked as creases. Faces of less than three vertices are dropped.
+func newSubdivision(vertices []linmath.Vector3, faces [][]int, creaseAngle float64) *subdivision {
+	s := &subdivision{original: vertices, weights: make([][]Weight, len(vertices)), creases: map[[2]int]bool{}}
+
+	for i := range vertices {
+		s.weights[i] = []Weight{{i, 1}}
+	}
+
+	for _, f := range faces {
+		if len(f) >= 3 {
+			s.faces = append(s.faces, f)
+		}
+	}
+
+	s.connect()
+
+	if creaseAngle < math.Pi {
+		normals := make([]*linmath.Vector3, len(s.faces))
+		for f, face := range s.faces {
+			normals[f] = newellNormal(vertices, face)
+		}
+
+		minCos := math.Cos(creaseAngle)
+		for e, faces := range s.edgeFaces {
+			if len(faces) == 2 && normals[faces[0]].Dot(normals[faces[1]]) < minCos {
+				s.creases[s.ends[e]] = true
+			}
+		}
+	}
+
+	return s
+}
+
+// connect is a method that finds the edges of the faces and what is around every edge and vertex.
+func (s *subdivision) connect() {
+	s.edges = map[[2]int]int{}
+	s.ends, s.edgeFaces = nil, nil
+	s.vertexEdges = make([][]int, len(s.weights))
+	s.vertexFaces = make([][]int, len(s.weights))
+
+	for f, face := range s.faces {
+		for i, v := range face {
+			s.vertexFaces[v] = append(s.vertexFaces[v], f)
+
+			key := edgeKey(v, face[(i+1)%len(face)])
+			e, ok := s.edges[key]
+			if !ok {
+				e = len(s.ends)
+				s.edges[key] = e
+				s.ends = append(s.ends, key)
+				s.edgeFaces = append(s.edgeFaces, nil)
+				s.vertexEdges[key[0]] = append(s.vertexEdges[key[0]], e)
+				s.vertexEdges[key[1]] = append(s.vertexEdges[key[1]], e)
+			}
+
+			s.edgeFaces[e] = append(s.edgeFaces[e], f)
+		}
+	}
+}
+
+// loop is a method that subdivides the triangles one level by the Loop rules.
+func (s *subdivision) loop() {
+	n := len(s.weights)
+	weights := make([][]Weight, n+len(s.ends))
+
+	for v := 0; v < n; v++ {
+		m, ok := s.creaseVertex(v)
+		if !ok {
+			k := float64(len(s.vertexEdges[v]))
+
+			beta := 3 / (8 * k)
+			if k == 3 {
+				beta = 3. / 16
+			}
+
+			m = mix{v: 1 - k*beta}
+			for _, e := range s.vertexEdges[v] {
+				m[s.other(e, v)] += beta
+			}
+		}
+
+		weights[v] = s.compose(m)
+	}
+
+	for e, ends := range s.ends {
+		m := mix{ends[0]: 1. / 2, ends[1]: 1. / 2}
+
+		// A smooth edge also leans towards the corners across it.
+		if !s.isCrease(e) {
+			m = mix{ends[0]: 3. / 8, ends[1]: 3. / 8}
+			for _, f := range s.edgeFaces[e] {
+				for _, v := range s.faces[f] {
+					if v != ends[0] && v != ends[1] {
+						m[v] += 1. / 8
+					}
+				}
+			}
+		}
+
+		weights[n+e] = s.compose(m)
+	}
+
+	faces := make([][]int, 0, 4*len(s.faces))
+	for _, face := range s.faces {
+		a, b, c := face[0], face[1], face[2]
+		ab, bc, ca := n+s.edges[edgeKey(a, b)], n+s.edges[edgeKey(b, c)], n+s.edges[edgeKey(c, a)]
+
+		faces = append(faces, []int{a, ab, ca}, []int{ab, b, bc}, []int{ca, bc, c}, []int{ab, bc, ca})
+	}
+
+	s.next(faces, weights, n)
+}
+
+// catmullClark is a method that subdivides the faces one level by the Catmull–Clark rules.
+func (s *subdivision) catmullClark() {
+	n, edges := len(s.weights), len(s.ends)
+	weights := make([][]Weight, n+edges+len(s.faces))
+
+	center := func(f int) mix {
+		m := mix{}
+		for _, v := range s.faces[f] {
+			m[v] += 1 / float64(len(s.faces[f]))
+		}
+
+		return m
+	}
+
+	for f := range s.faces {
+		weights[n+edges+f] = s.compose(center(f))
+	}
+
+	for e, ends := range s.ends {
+		m := mix{ends[0]: 1. / 2, ends[1]: 1. / 2}
+
+		if !s.isCrease(e) {
+			m = mix{ends[0]: 1. / 4, ends[1]: 1. / 4}
+			for _, f := range s.edgeFaces[e] {
+				m.add(center(f), 1./4)
+			}
+		}
+
+		weights[n+e] = s.compose(m)
+	}
+
+	for v := 0; v < n; v++ {
+		m, ok := s.creaseVertex(v)
+		if !ok {
+			// (Q + 2R + (k - 3) v) / k, where Q is the average of the centers of the faces around the vertex
+			// and R of the midpoints of its edges.
+			k := float64(len(s.vertexEdges[v]))
+
+			m = mix{v: (k - 3) / k}
+			for _, f := range s.vertexFaces[v] {
+				m.add(center(f), 1/(k*float64(len(s.vertexFaces[v]))))
+			}
+
+			for _, e := range s.vertexEdges[v] {
+				m.add(mix{s.ends[e][0]: 1. / 2, s.ends[e][1]: 1. / 2}, 2/(k*k))
+			}
+		}
+
+		weights[v] = s.compose(m)
+	}
+
+	var faces [][]int
+	for f, face := range s.faces {
+		k := len(face)
+		for i, v := range face {
+			next, previous := face[(i+1)%k], face[(i+k-1)%k]
+			faces = append(faces, []int{v, n + s.edges[edgeKey(v, next)], n + edges + f, n + s.edges[edgeKey(previous, v)]})
+		}
+	}
+
+	s.next(faces, weights, n)
+}
+
+// next is a method that moves on to the subdivided level. Both halves of a crease are creases,
+// the point that split edge e being at index n + e.
+func (s *subdivision) next(faces [][]int, weights [][]Weight, n int) {
+	creases := map[[2]int]bool{}
+	for e, ends := range s.ends {
+		if s.isCrease(e) {
+			creases[edgeKey(ends[0], n+e)] = true
+			creases[edgeKey(n+e, ends[1])] = true
+		}
+	}
+
+	s.faces, s.weights, s.creases = faces, weights, creases
+	s.connect()
+}
+
+// creaseVertex is a method that returns where a vertex on creases moves, if it is on any: along the crease between
+// its neighbours on it, or nowhere when it is a corner of more creases or on no edge at all.
+func (s *subdivision) creaseVertex(v int) (mix, bool) {
+	var neighbours []int
+	for _, e := range s.vertexEdges[v] {
+		if s.isCrease(e) {
+			neighbours = append(neighbours, s.other(e, v))
+		}
+	}
+
+	switch {
+	case len(neighbours) == 2:
+		return mix{v: 3. / 4, neighbours[0]: 1. / 8, neighbours[1]: 1. / 8}, true
+	case len(neighbours) > 2 || len(s.vertexEdges[v]) == 0:
+		return mix{v: 1}, true
+	}
+
+	return nil, false
+}
+
+// isCrease is a method that tells whether the edge is sharp: marked as a crease, on the boundary, or shared by more than two faces.
+func (s *subdivision) isCrease(e int) bool {
+	return len(s.edgeFaces[e]) != 2 || s.creases[s.ends[e]]
+}
+
+// other is a method that returns the end of the edge that is not the vertex.
+func (s *subdivision) other(e, v int) int {
+	if s.ends[e][0] == v {
+		return s.ends[e][1]
+	}
+
+	return s.ends[e][0]
+}
+
+// compose is a method that returns the weights of the original vertices in a sum of vertices of the current level.
+// Vertices are summed in order of index, so the same mesh always subdivides to exactly the same numbers.
+func (s *subdivision) compose(m mix) []Weight {
+	vertices := make([]int, 0, len(m))
+	for v := range m {
+		vertices = append(vertices, v)
+	}
+
+	sort.Ints(vertices)
+
+	sum := map[int]float64{}
+	for _, v := range vertices {
+		for _, w := range s.weights[v] {
+			sum[w.Vertex] += m[v] * w.Weight
+		}
+	}
+
+	weights := make([]Weight, 0, len(sum))
+	for v, w := range sum {
+		weights = append(weights, Weight{v, w})
+	}
+
+	sort.Slice(weights, func(i, j int) bool { return weights[i].Vertex < weights[j].Vertex })
+
+	return weights
+}
+
+// mesh is a method that returns the current level as a triangle mesh, splitting faces into fans, with the weights.
+func (s *subdivision) mesh() (*Mesh, [][]Weight) {
+	vertices := make([]linmath.Vector3, len(s.weights))
+	for i, weights := range s.weights {
+		for _, w := range weights {
+			vertices[i] = *vertices[i].Add(s.original[w.Vertex].MultiplyOnScalar(w.Weight))
+		}
+	}
+
+	var triangles [][3]int
+	for _, face := range s.faces {
+		for j := 1; j+1 < len(face); j++ {
+			triangles = append(triangles, [3]int{face[0], face[j], face[j+1]})
+		}
+	}
+
+	return &Mesh{vertices, nil, triangles}, s.weights
+}
+
+// edgeKey is a function that returns the key of the edge between two vertices.
+func edgeKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+
+	return [2]int{a, b}
+}
+
+// newellNormal is a function that returns the normal of a polygon by Newell's method,
+// which holds up for polygons that are not quite flat.
+func newellNormal(vertices []linmath.Vector3, face []int) *linmath.Vector3 {
+	var x, y, z float64
+
+	for i, v := range face {
+		a, b := &vertices[v], &vertices[face[(i+1)%len(face)]]
+		x += (a.Y() - b.Y()) * (a.Z() + b.Z())
+		y += (a.Z() - b.Z()) * (a.X() + b.X())
+		z += (a.X() - b.X()) * (a.Y() + b.Y())
+	}
+
+	normal := linmath.NewVector3(x, y, z)
+	if normal.Length() == 0 {
+		return normal
+	}
+
+	return normal.Normal()
+}
@@ -0,0 +1,172 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+// octahedron is a function that returns an octahedron with its vertices on the unit sphere.
+func octahedron() *Mesh {
+	return &Mesh{
+		Vertices: []linmath.Vector3{
+			*linmath.NewVector3(1, 0, 0), *linmath.NewVector3(-1, 0, 0),
+			*linmath.NewVector3(0, 1, 0), *linmath.NewVector3(0, -1, 0),
+			*linmath.NewVector3(0, 0, 1), *linmath.NewVector3(0, 0, -1),
+		},
+		Triangles: [][3]int{
+			{0, 2, 4}, {2, 1, 4}, {1, 3, 4}, {3, 0, 4},
+			{2, 0, 5}, {1, 2, 5}, {3, 1, 5}, {0, 3, 5},
+		},
+	}
+}
+
+// cubeFaces are the quads of cube.
+var cubeFaces = [][]int{{0, 3, 2, 1}, {4, 5, 6, 7}, {0, 1, 5, 4}, {3, 7, 6, 2}, {0, 4, 7, 3}, {1, 2, 6, 5}}
+
+// checkWeights is a function that checks that the weights of every vertex add up to 1 and give its position.
+func checkWeights(t *testing.T, name string, original []linmath.Vector3, result *Mesh, weights [][]Weight) {
+	if len(weights) != len(result.Vertices) {
+		t.Fatalf("%s: expected weights for [%v] vertices but have [%v]", name, len(result.Vertices), len(weights))
+	}
+
+	for i, ws := range weights {
+		var sum float64
+		var position linmath.Vector3
+
+		for _, w := range ws {
+			sum += w.Weight
+			position = *position.Add(original[w.Vertex].MultiplyOnScalar(w.Weight))
+		}
+
+		if math.Abs(sum-1) > epsilon || !equalVectors(&position, &result.Vertices[i]) {
+			t.Fatalf("%s: expected the weights of vertex [%v] to add up to 1 at [%v] but have [%v] at [%v]", name, i, result.Vertices[i], sum, position)
+		}
+	}
+}
+
+func TestLoop(t *testing.T) {
+	tests := []struct {
+		name              string
+		inputLevels       int
+		expectedVertices  int
+		expectedTriangles int
+	}{
+		{"level 0", 0, 6, 8},
+		{"level 1", 1, 18, 32},
+		{"level 2", 2, 66, 128},
+	}
+
+	for _, ts := range tests {
+		m := octahedron()
+		result, weights := m.Loop(ts.inputLevels, math.Pi)
+
+		if len(result.Vertices) != ts.expectedVertices || len(result.Triangles) != ts.expectedTriangles {
+			t.Fatalf("%s: expected [%v] vertices and [%v] triangles but have [%v] and [%v]", ts.name, ts.expectedVertices, ts.expectedTriangles, len(result.Vertices), len(result.Triangles))
+		}
+
+		checkWeights(t, ts.name, m.Vertices, result, weights)
+
+		// The octahedron shrinks towards a sphere, the same in every direction, and keeps its winding.
+		bounds := result.Bounds()
+		if !equalVectors(bounds.Max(), bounds.Min().Negative()) || math.Abs(bounds.Max().X()-bounds.Max().Y()) > epsilon || bounds.Max().X() > 1 {
+			t.Fatalf("%s: expected bounds symmetric about the origin and inside the octahedron but have [%v]", ts.name, bounds)
+		}
+
+		for _, tr := range result.Triangles {
+			v0, v1, v2 := &result.Vertices[tr[0]], &result.Vertices[tr[1]], &result.Vertices[tr[2]]
+			if v1.Subtraction(v0).Cross(v2.Subtraction(v0)).Dot(v0.Add(v1).Add(v2)) <= 0 {
+				t.Fatalf("%s: expected the triangle [%v] to face out", ts.name, tr)
+			}
+		}
+	}
+
+	// The vertices of the first level are known: an original one becomes 5/8 of itself, being surrounded
+	// by four neighbours at right angles, and a new one 3/8 of each end of its edge and 1/8 of the two corners across.
+	result, _ := octahedron().Loop(1, math.Pi)
+	if !equalVectors(&result.Vertices[0], linmath.NewVector3(5./8, 0, 0)) {
+		t.Fatalf("expected [0.625 0 0] but have [%v]", result.Vertices[0])
+	}
+}
+
+func TestCatmullClark(t *testing.T) {
+	c := cube()
+
+	result, weights := CatmullClark(c.Vertices, cubeFaces, 1, math.Pi)
+
+	// Every quad of the cube becomes four, each two triangles.
+	if len(result.Vertices) != 26 || len(result.Triangles) != 48 {
+		t.Fatalf("expected [26] vertices and [48] triangles but have [%v] and [%v]", len(result.Vertices), len(result.Triangles))
+	}
+
+	checkWeights(t, "cube", c.Vertices, result, weights)
+
+	// A corner of a cube from -1 to 1 moves to 5/9 of itself; this one is 2 wide and centered at 1.
+	expected := linmath.NewVector3(1-5./9, 1-5./9, 1-5./9)
+	if !equalVectors(&result.Vertices[0], expected) {
+		t.Fatalf("expected [%v] but have [%v]", expected, result.Vertices[0])
+	}
+
+	// A pentagon on its own has nothing but boundary, so its outline subdivides as a curve and its center stays.
+	pentagon := make([]linmath.Vector3, 5)
+	for i := range pentagon {
+		angle := 2 * math.Pi * float64(i) / 5
+		pentagon[i] = *linmath.NewVector3(math.Cos(angle), math.Sin(angle), 0)
+	}
+
+	result, weights = CatmullClark(pentagon, [][]int{{0, 1, 2, 3, 4}}, 2, math.Pi)
+	checkWeights(t, "pentagon", pentagon, result, weights)
+
+	if len(result.Triangles) != 2*4*5 {
+		t.Fatalf("expected [40] triangles but have [%v]", len(result.Triangles))
+	}
+
+	for i := range result.Vertices {
+		if z := result.Vertices[i].Z(); z != 0 {
+			t.Fatalf("expected the pentagon to stay flat but have [%v]", result.Vertices[i])
+		}
+	}
+}
+
+func TestSubdivisionCreases(t *testing.T) {
+	c := cube()
+
+	tests := []struct {
+		name      string
+		subdivide func(crease float64) *Mesh
+	}{
+		{"loop", func(crease float64) *Mesh { m, _ := c.Loop(2, crease); return m }},
+		{"catmull-clark", func(crease float64) *Mesh { m, _ := CatmullClark(c.Vertices, cubeFaces, 2, crease); return m }},
+	}
+
+	for _, ts := range tests {
+		// The diagonals that split the faces of the triangle cube are flat and don't crease.
+		sharp := ts.subdivide(linmath.Radians(45))
+		smooth := ts.subdivide(math.Pi)
+
+		// With every edge of the cube a crease, its corners stay and its edges stay straight,
+		// so nothing leaves the surface of the cube.
+		for i := range sharp.Vertices {
+			v := &sharp.Vertices[i]
+			onFace := false
+			for _, x := range []float64{v.X(), v.Y(), v.Z()} {
+				if math.Abs(x) < epsilon || math.Abs(x-2) < epsilon {
+					onFace = true
+				}
+
+				if x < -epsilon || x > 2+epsilon {
+					onFace = false
+					break
+				}
+			}
+
+			if !onFace {
+				t.Fatalf("%s: expected [%v] on the surface of the cube", ts.name, v)
+			}
+		}
+
+		if b := smooth.Bounds(); b.Max().X() > 1.95 || b.Min().X() < 0.05 {
+			t.Fatalf("%s: expected the smooth cube to round off but have [%v]", ts.name, b)
+		}
+	}
+}
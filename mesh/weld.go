@@ -0,0 +1,100 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// Weld is a method that merges every vertex into the first vertex no farther away than the tolerance,
+// so a triangle soup such as an STL file becomes a connected mesh. A tolerance of 0 merges only vertices
+// at exactly the same position. It returns the welded mesh and, for each of its vertices, the index
+// of the vertex of m it comes from, so callers can carry other per-vertex data along.
+// The normals are dropped, since merged vertices may disagree on them; generate them again after welding.
+// Triangles that lose a corner to welding are kept until RemoveDegenerate.
+func (m *Mesh) Weld(tolerance float64) (*Mesh, []int) {
+	var vertices []linmath.Vector3
+	var origins []int
+
+	indices := make([]int, len(m.Vertices))
+
+	if tolerance <= 0 {
+		seen := map[linmath.Vector3]int{}
+
+		for i, v := range m.Vertices {
+			index, ok := seen[v]
+			if !ok {
+				index = len(vertices)
+				seen[v] = index
+				vertices, origins = append(vertices, v), append(origins, i)
+			}
+
+			indices[i] = index
+		}
+	} else {
+		// Vertices are bucketed in cells as large as the tolerance, so a match is always in a neighbouring cell.
+		cells := map[[3]int64][]int{}
+		cell := func(v *linmath.Vector3) [3]int64 {
+			return [3]int64{
+				int64(math.Floor(v.X() / tolerance)),
+				int64(math.Floor(v.Y() / tolerance)),
+				int64(math.Floor(v.Z() / tolerance)),
+			}
+		}
+
+		for i := range m.Vertices {
+			v := &m.Vertices[i]
+			c := cell(v)
+
+			index := -1
+			for dx := int64(-1); dx <= 1 && index < 0; dx++ {
+				for dy := int64(-1); dy <= 1 && index < 0; dy++ {
+					for dz := int64(-1); dz <= 1 && index < 0; dz++ {
+						for _, candidate := range cells[[3]int64{c[0] + dx, c[1] + dy, c[2] + dz}] {
+							if vertices[candidate].Subtraction(v).Length() <= tolerance {
+								index = candidate
+								break
+							}
+						}
+					}
+				}
+			}
+
+			if index < 0 {
+				index = len(vertices)
+				cells[c] = append(cells[c], index)
+				vertices, origins = append(vertices, *v), append(origins, i)
+			}
+
+			indices[i] = index
+		}
+	}
+
+	triangles := make([][3]int, len(m.Triangles))
+	for i, t := range m.Triangles {
+		triangles[i] = [3]int{indices[t[0]], indices[t[1]], indices[t[2]]}
+	}
+
+	return &Mesh{vertices, nil, triangles}, origins
+}
+
+// RemoveDegenerate is a method that returns the mesh without the triangles that use a vertex twice
+// or whose area is no more than epsilon. Such triangles have no normal and nothing to show.
+// The vertices and normals are shared with m.
+func (m *Mesh) RemoveDegenerate(epsilon float64) *Mesh {
+	var triangles [][3]int
+
+	for _, t := range m.Triangles {
+		if t[0] == t[1] || t[1] == t[2] || t[2] == t[0] {
+			continue
+		}
+
+		v0, v1, v2 := &m.Vertices[t[0]], &m.Vertices[t[1]], &m.Vertices[t[2]]
+		if v1.Subtraction(v0).Cross(v2.Subtraction(v0)).Length()/2 <= epsilon {
+			continue
+		}
+
+		triangles = append(triangles, t)
+	}
+
+	return &Mesh{m.Vertices, m.Normals, triangles}
+}
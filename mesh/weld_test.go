@@ -0,0 +1,97 @@
+package mesh
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"testing"
+)
+
+// soup is a function that returns the triangles of the mesh with their own three vertices each, like an STL file.
+func soup(m *Mesh, offset func(i int) float64) *Mesh {
+	result := &Mesh{}
+
+	for _, t := range m.Triangles {
+		for _, v := range t {
+			i := len(result.Vertices)
+			result.Vertices = append(result.Vertices, *m.Vertices[v].Add(linmath.Splat(offset(i))))
+		}
+
+		n := len(result.Vertices)
+		result.Triangles = append(result.Triangles, [3]int{n - 3, n - 2, n - 1})
+	}
+
+	return result
+}
+
+func TestWeld(t *testing.T) {
+	exact := func(int) float64 { return 0 }
+	noisy := func(i int) float64 { return float64(i) * 1e-5 }
+
+	tests := []struct {
+		name             string
+		inputMesh        *Mesh
+		inputTolerance   float64
+		expectedVertices int
+	}{
+		{"exact", soup(cube(), exact), 0, 8},
+		{"noise without tolerance", soup(cube(), noisy), 0, 36},
+		{"noise within tolerance", soup(cube(), noisy), 1e-3, 8},
+		{"tolerance smaller than the noise", soup(cube(), noisy), 1e-5, 36},
+		{"tolerance larger than the cube", soup(cube(), exact), 10, 1},
+	}
+
+	for _, ts := range tests {
+		welded, origins := ts.inputMesh.Weld(ts.inputTolerance)
+
+		if len(welded.Vertices) != ts.expectedVertices || len(origins) != ts.expectedVertices {
+			t.Fatalf("%s: expected [%v] vertices but have [%v] with [%v] origins", ts.name, ts.expectedVertices, len(welded.Vertices), len(origins))
+		}
+
+		for i, o := range origins {
+			if welded.Vertices[i] != ts.inputMesh.Vertices[o] {
+				t.Fatalf("%s: expected vertex [%v] to come from [%v] but have [%v]", ts.name, i, ts.inputMesh.Vertices[o], welded.Vertices[i])
+			}
+		}
+
+		// Every corner moved by no more than the tolerance.
+		for i, tr := range welded.Triangles {
+			for k, v := range tr {
+				original := &ts.inputMesh.Vertices[ts.inputMesh.Triangles[i][k]]
+				if d := welded.Vertices[v].Subtraction(original).Length(); d > ts.inputTolerance+epsilon {
+					t.Fatalf("%s: expected corner [%v %v] within [%v] of [%v] but have [%v]", ts.name, i, k, ts.inputTolerance, original, welded.Vertices[v])
+				}
+			}
+		}
+	}
+}
+
+func TestRemoveDegenerate(t *testing.T) {
+	m := &Mesh{
+		Vertices: []linmath.Vector3{
+			*linmath.NewVector3(0, 0, 0), *linmath.NewVector3(1, 0, 0), *linmath.NewVector3(0, 1, 0),
+			*linmath.NewVector3(2, 0, 0), *linmath.NewVector3(0, 1e-6, 0),
+		},
+		Triangles: [][3]int{{0, 1, 2}, {0, 0, 1}, {0, 1, 3}, {0, 1, 4}},
+	}
+
+	tests := []struct {
+		inputEpsilon      float64
+		expectedTriangles [][3]int
+	}{
+		{0, [][3]int{{0, 1, 2}, {0, 1, 4}}},
+		{1e-3, [][3]int{{0, 1, 2}}},
+	}
+
+	for _, ts := range tests {
+		result := m.RemoveDegenerate(ts.inputEpsilon)
+
+		if len(result.Triangles) != len(ts.expectedTriangles) {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedTriangles, result.Triangles)
+		}
+
+		for i := range result.Triangles {
+			if result.Triangles[i] != ts.expectedTriangles[i] {
+				t.Fatalf("expected [%v] but have [%v]", ts.expectedTriangles, result.Triangles)
+			}
+		}
+	}
+}
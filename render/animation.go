@@ -0,0 +1,307 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"sort"
+)
+
+// shutterFraction is the part of a frame during which the shutter of an animated camera is open.
+const shutterFraction = 0.5
+
+type Interpolation int
+
+const (
+	LinearInterpolation     Interpolation = iota
+	BezierInterpolation                   // cubic Bézier curve between keys, shaped by their handles
+	CatmullRomInterpolation               // smooth curve through the keys with tangents from their neighbours
+)
+
+// Keyframe is the value of an animated property at a time in seconds.
+// Values are vectors of any length, so the same tracks animate positions, angles and intensities.
+type Keyframe struct {
+	time    float64
+	value   []float64
+	in, out []float64 // Bézier handles before and after the key, the value itself when nil
+}
+
+func NewKeyframe(time float64, value ...float64) *Keyframe {
+	return &Keyframe{time: time, value: value}
+}
+
+// NewBezierKeyframe is a function that returns a keyframe with the handles of a Bézier track.
+func NewBezierKeyframe(time float64, value, in, out []float64) *Keyframe {
+	return &Keyframe{time, value, in, out}
+}
+
+// Track is the sequence of keyframes of one property.
+type Track struct {
+	keys          []Keyframe
+	interpolation Interpolation
+}
+
+// NewTrack is a function that returns a track through the keyframes in the order of their times.
+// Every keyframe, and every Bézier handle, has to hold as many values as the others.
+func NewTrack(interpolation Interpolation, keys ...*Keyframe) (*Track, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("render: track has no keyframes")
+	}
+
+	n := len(keys[0].value)
+	t := &Track{interpolation: interpolation}
+
+	for _, k := range keys {
+		if len(k.value) != n {
+			return nil, fmt.Errorf("render: keyframe at %v has %d values instead of %d", k.time, len(k.value), n)
+		}
+
+		if (k.in != nil && len(k.in) != n) || (k.out != nil && len(k.out) != n) {
+			return nil, fmt.Errorf("render: handles of the keyframe at %v do not have %d values", k.time, n)
+		}
+
+		t.keys = append(t.keys, *k)
+	}
+
+	sort.SliceStable(t.keys, func(i, j int) bool {
+		return t.keys[i].time < t.keys[j].time
+	})
+
+	return t, nil
+}
+
+// check is a method that returns an error unless the track holds n values, naming the property it animates.
+func (t *Track) check(property string, n int) error {
+	if have := len(t.keys[0].value); have != n {
+		return fmt.Errorf("render: %s track has %d values instead of %d", property, have, n)
+	}
+
+	return nil
+}
+
+// At is a method that returns the value of the track at the time.
+// Before the first and after the last keyframe the track holds their values.
+func (t *Track) At(time float64) []float64 {
+	last := len(t.keys) - 1
+
+	if time <= t.keys[0].time {
+		return t.keys[0].value
+	}
+
+	if time >= t.keys[last].time {
+		return t.keys[last].value
+	}
+
+	i := sort.Search(last, func(i int) bool {
+		return t.keys[i+1].time > time
+	})
+
+	k0, k1 := &t.keys[i], &t.keys[i+1]
+	s := (time - k0.time) / (k1.time - k0.time)
+	value := make([]float64, len(k0.value))
+
+	switch t.interpolation {
+	case LinearInterpolation:
+		for j := range value {
+			value[j] = k0.value[j] + (k1.value[j]-k0.value[j])*s
+		}
+	case BezierInterpolation:
+		p1, p2 := k0.out, k1.in
+		if p1 == nil {
+			p1 = k0.value
+		}
+
+		if p2 == nil {
+			p2 = k1.value
+		}
+
+		u := 1 - s
+		for j := range value {
+			value[j] = u*u*u*k0.value[j] + 3*u*u*s*p1[j] + 3*u*s*s*p2[j] + s*s*s*k1.value[j]
+		}
+	case CatmullRomInterpolation:
+		m0, m1 := t.tangent(i), t.tangent(i+1)
+		span := k1.time - k0.time
+
+		// Cubic Hermite basis; the tangents are per second, so they are scaled to the span.
+		s2, s3 := s*s, s*s*s
+		h00, h10, h01, h11 := 2*s3-3*s2+1, s3-2*s2+s, -2*s3+3*s2, s3-s2
+		for j := range value {
+			value[j] = h00*k0.value[j] + h10*span*m0[j] + h01*k1.value[j] + h11*span*m1[j]
+		}
+	}
+
+	return value
+}
+
+// tangent is a method that returns the Catmull–Rom tangent at a key, the slope between its neighbours.
+// The first and last keys use their only neighbour.
+func (t *Track) tangent(i int) []float64 {
+	previous, next := &t.keys[i], &t.keys[i]
+	if i > 0 {
+		previous = &t.keys[i-1]
+	}
+
+	if i < len(t.keys)-1 {
+		next = &t.keys[i+1]
+	}
+
+	tangent := make([]float64, len(t.keys[i].value))
+
+	for j := range tangent {
+		tangent[j] = (next.value[j] - previous.value[j]) / (next.time - previous.time)
+	}
+
+	return tangent
+}
+
+// TransformTrack animates an affine transform that is applied on top of the placement of an object:
+// scale first, then rotation in degrees around the Z, X and Y axes, then translation.
+// Missing tracks leave their part of the transform unchanged.
+type TransformTrack struct {
+	Translation *Track
+	Rotation    *Track
+	Scale       *Track
+}
+
+func (t *TransformTrack) At(time float64) *linmath.Matrix4 {
+	transform := linmath.NewIdentity()
+
+	if t.Translation != nil {
+		v := t.Translation.At(time)
+		transform = transform.Multiply(linmath.NewTranslation(v[0], v[1], v[2]))
+	}
+
+	if t.Rotation != nil {
+		v := t.Rotation.At(time)
+		transform = transform.
+			Multiply(linmath.NewRotationY(linmath.Radians(v[1]))).
+			Multiply(linmath.NewRotationX(linmath.Radians(v[0]))).
+			Multiply(linmath.NewRotationZ(linmath.Radians(v[2])))
+	}
+
+	if t.Scale != nil {
+		v := t.Scale.At(time)
+		transform = transform.Multiply(linmath.NewScale(v[0], v[1], v[2]))
+	}
+
+	return transform
+}
+
+// Animation describes how the camera, the lights and the objects of a scene change over time.
+// Every track is optional, a property without one keeps its value from the scene.
+// Lights and objects are referred to by their index in the scene.
+type Animation struct {
+	CameraPosition  *Track
+	CameraTarget    *Track // point the camera looks at
+	LightIntensity  map[int]*Track
+	LightPosition   map[int]*Track // position of a point light or direction of a directional light
+	ObjectTransform map[int]*TransformTrack
+}
+
+// check is a method that returns an error unless every track animates a light or object of the scene
+// and holds as many values as its property.
+func (a *Animation) check(scene *Scene) error {
+	if scene.Camera == nil {
+		return errors.New("render: scene has no camera")
+	}
+
+	for property, t := range map[string]*Track{"camera position": a.CameraPosition, "camera target": a.CameraTarget} {
+		if t != nil {
+			if err := t.check(property, 3); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, tracks := range []map[int]*Track{a.LightIntensity, a.LightPosition} {
+		for i := range tracks {
+			if i < 0 || i >= len(scene.Lights) {
+				return fmt.Errorf("render: animated light %d does not exist", i)
+			}
+		}
+	}
+
+	for i, t := range a.LightIntensity {
+		if err := t.check(fmt.Sprintf("light %d intensity", i), 1); err != nil {
+			return err
+		}
+	}
+
+	for i, t := range a.LightPosition {
+		if err := t.check(fmt.Sprintf("light %d position", i), 3); err != nil {
+			return err
+		}
+	}
+
+	for i, t := range a.ObjectTransform {
+		if i < 0 || i >= len(scene.Objects) {
+			return fmt.Errorf("render: animated object %d does not exist", i)
+		}
+
+		for property, track := range map[string]*Track{"translation": t.Translation, "rotation": t.Rotation, "scale": t.Scale} {
+			if track != nil {
+				if err := track.check(fmt.Sprintf("object %d %s", i, property), 3); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Frame is a method that returns the scene at the time of a frame lasting frameDuration seconds.
+// Objects move while the shutter is open, so fast objects are blurred like in a still with a moving instance.
+// It returns an error if a track animates a light or object missing from the scene or has the wrong number of values.
+func (a *Animation) Frame(base *Scene, time, frameDuration float64) (*Scene, error) {
+	if err := a.check(base); err != nil {
+		return nil, err
+	}
+
+	frame := &Scene{
+		Objects: append([]Object(nil), base.Objects...),
+		Lights:  append([]Light(nil), base.Lights...),
+		Fog:     base.Fog,
+		Volumes: base.Volumes,
+	}
+
+	for i, t := range a.LightIntensity {
+		frame.Lights[i].intensity = t.At(time)[0]
+	}
+
+	for i, t := range a.LightPosition {
+		value := t.At(time)
+		v := linmath.NewVector3(value[0], value[1], value[2])
+
+		if frame.Lights[i].lightType == directionalLight {
+			frame.Lights[i].direction = *v
+		} else {
+			frame.Lights[i].position = *v
+		}
+	}
+
+	closing := time + shutterFraction*frameDuration
+	for i, t := range a.ObjectTransform {
+		frame.Objects[i] = NewMovingInstance(base.Objects[i], t.At(time), t.At(closing), nil)
+	}
+
+	camera := base.Camera
+	position, rotation := &camera.position, &camera.rotation
+
+	if a.CameraPosition != nil {
+		v := a.CameraPosition.At(time)
+		position = linmath.NewVector3(v[0], v[1], v[2])
+	}
+
+	if a.CameraTarget != nil {
+		v := a.CameraTarget.At(time)
+		rotation = linmath.NewLookRotation(linmath.NewVector3(v[0], v[1], v[2]).Subtraction(position), linmath.NewVector3(0., 1., 0.))
+	}
+
+	frame.Camera = &Camera{}
+	*frame.Camera = *camera
+	frame.Camera.position, frame.Camera.rotation = *position, *rotation
+
+	return frame, nil
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"strings"
+	"testing"
+)
+
+// newTestTrack is a function that returns a track through the keyframes and fails the test if they do not make one.
+func newTestTrack(t *testing.T, interpolation Interpolation, keys ...*Keyframe) *Track {
+	track, err := NewTrack(interpolation, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return track
+}
+
+func TestTrackAt(t *testing.T) {
+	linear := newTestTrack(t, LinearInterpolation, NewKeyframe(2, 10, 20), NewKeyframe(0, 0, 0), NewKeyframe(4, 10, 0))
+	bezier := newTestTrack(t,
+		BezierInterpolation,
+		NewBezierKeyframe(0, []float64{0}, nil, []float64{1}),
+		NewBezierKeyframe(1, []float64{0}, []float64{1}, nil),
+	)
+	// Catmull–Rom through evenly spaced keys on a line is the line itself.
+	straight := newTestTrack(t, CatmullRomInterpolation, NewKeyframe(0, 0), NewKeyframe(1, 2), NewKeyframe(2, 4), NewKeyframe(3, 6))
+	curved := newTestTrack(t, CatmullRomInterpolation, NewKeyframe(0, 0), NewKeyframe(1, 1), NewKeyframe(3, 0))
+
+	tests := []struct {
+		name          string
+		inputTrack    *Track
+		inputTime     float64
+		expectedValue []float64
+	}{
+		{"before the first key", linear, -1, []float64{0, 0}},
+		{"after the last key", linear, 5, []float64{10, 0}},
+		{"on a key", linear, 2, []float64{10, 20}},
+		{"linear", linear, 1, []float64{5, 10}},
+		{"linear second span", linear, 3.5, []float64{10, 5}},
+		{"bezier middle", bezier, 0.5, []float64{0.75}},
+		{"bezier start", bezier, 0, []float64{0}},
+		{"catmull-rom on a line", straight, 1.25, []float64{2.5}},
+		{"catmull-rom through a key", curved, 1, []float64{1}},
+		{"catmull-rom first span", curved, 0.5, []float64{0.625}},
+	}
+
+	for _, ts := range tests {
+		value := ts.inputTrack.At(ts.inputTime)
+
+		for i := range value {
+			if math.Abs(value[i]-ts.expectedValue[i]) > 1e-9 {
+				t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expectedValue, value)
+			}
+		}
+	}
+}
+
+func TestAnimationFrame(t *testing.T) {
+	base := &Scene{
+		Objects: []Object{NewSphere(*linmath.NewVector3(0, 0, 5), 1, *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil))},
+		Lights:  []Light{*NewPointLight(1, linmath.NewVector3(0, 0, 0))},
+		Camera:  NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 1),
+	}
+
+	a := &Animation{
+		CameraPosition:  newTestTrack(t, LinearInterpolation, NewKeyframe(0, 0, 0, 0), NewKeyframe(1, 0, 0, 10)),
+		CameraTarget:    newTestTrack(t, LinearInterpolation, NewKeyframe(0, 0, 0, 5)),
+		LightIntensity:  map[int]*Track{0: newTestTrack(t, LinearInterpolation, NewKeyframe(0, 1), NewKeyframe(1, 0))},
+		ObjectTransform: map[int]*TransformTrack{0: {Translation: newTestTrack(t, LinearInterpolation, NewKeyframe(0, 0, 0, 0), NewKeyframe(1, 2, 0, 0))}},
+	}
+
+	frame, err := a.Frame(base, 0.5, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(frame.Lights[0].intensity-0.5) > 1e-9 || base.Lights[0].intensity != 1 {
+		t.Fatalf("expected intensity [0.5] and an unchanged base but have [%v] and [%v]", frame.Lights[0].intensity, base.Lights[0].intensity)
+	}
+
+	// The camera is past the target, so it has turned around to look down -Z.
+	if forward := frame.Camera.rotation.MultiplyDirection(linmath.NewVector3(0, 0, 1)); forward.Z() > -1+1e-9 {
+		t.Fatalf("expected the camera to look down -Z but have [%v]", forward)
+	}
+
+	// The sphere moves from x = 1 to x = 1.5 while the shutter is open.
+	bounds := frame.Objects[0].Bounds()
+	if math.Abs(bounds.Min().X()-0) > 1e-9 || math.Abs(bounds.Max().X()-2.5) > 1e-9 {
+		t.Fatalf("expected bounds from [0] to [2.5] but have [%v]", bounds)
+	}
+}
+
+func TestNewTrackErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputKeys     []*Keyframe
+		expectedError string
+	}{
+		{"no keyframes", nil, "no keyframes"},
+		{"values", []*Keyframe{NewKeyframe(0, 1, 2), NewKeyframe(1, 3)}, "has 1 values instead of 2"},
+		{"handles", []*Keyframe{NewBezierKeyframe(0, []float64{0, 0}, nil, []float64{1})}, "handles"},
+	}
+
+	for _, ts := range tests {
+		if _, err := NewTrack(BezierInterpolation, ts.inputKeys...); err == nil || !strings.Contains(err.Error(), ts.expectedError) {
+			t.Fatalf("%s: expected an error containing [%v] but have [%v]", ts.name, ts.expectedError, err)
+		}
+	}
+}
+
+func TestAnimationFrameErrors(t *testing.T) {
+	base := &Scene{
+		Objects: []Object{NewSphere(*linmath.NewVector3(0, 0, 5), 1, Material{})},
+		Lights:  []Light{*NewPointLight(1, linmath.NewVector3(0, 0, 0))},
+		Camera:  NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 1),
+	}
+
+	single := newTestTrack(t, LinearInterpolation, NewKeyframe(0, 1))
+	triple := newTestTrack(t, LinearInterpolation, NewKeyframe(0, 1, 2, 3))
+
+	tests := []struct {
+		name           string
+		inputAnimation *Animation
+		expectedError  string
+	}{
+		{"missing light", &Animation{LightIntensity: map[int]*Track{1: single}}, "light 1 does not exist"},
+		{"negative light", &Animation{LightPosition: map[int]*Track{-1: triple}}, "light -1 does not exist"},
+		{"missing object", &Animation{ObjectTransform: map[int]*TransformTrack{1: {Translation: triple}}}, "object 1 does not exist"},
+		{"short position", &Animation{CameraPosition: single}, "camera position track has 1 values instead of 3"},
+		{"long intensity", &Animation{LightIntensity: map[int]*Track{0: triple}}, "light 0 intensity track has 3 values instead of 1"},
+		{"short scale", &Animation{ObjectTransform: map[int]*TransformTrack{0: {Scale: single}}}, "object 0 scale track"},
+	}
+
+	for _, ts := range tests {
+		if _, err := ts.inputAnimation.Frame(base, 0, 1); err == nil || !strings.Contains(err.Error(), ts.expectedError) {
+			t.Fatalf("%s: expected an error containing [%v] but have [%v]", ts.name, ts.expectedError, err)
+		}
+	}
+}
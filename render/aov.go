@@ -0,0 +1,257 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image"
+	"math"
+	"reflect"
+	"time"
+)
+
+// AOVs are the arbitrary output variables of a ray traced image: what the primary rays of every pixel hit first,
+// listed row by row from the top like the pixels of the image. Depths are those of the nearest sample of a pixel,
+// IDs those hit by most of its samples, with the share of the samples that hit them as their coverage,
+// and the rest is averaged over the samples that hit something. Pixels whose samples all miss are infinitely deep
+// and have zero IDs, coverages, normals, albedos, UVs and motion.
+type AOVs struct {
+	Width, Height    int
+	Depth            []float64         // distance from the camera along its view axis
+	Distance         []float64         // distance from the camera position
+	Normal           []linmath.Vector3 // world space
+	CameraNormal     []linmath.Vector3 // camera space, +Z along the view axis
+	Albedo           []Color
+	ObjectID         []int // index among the objects of the scene of the object hit plus 1
+	ObjectCoverage   []float64
+	MaterialID       []int // index into Materials plus 1
+	MaterialCoverage []float64
+	Materials        []*Material // the different materials hit, in the order they first appear in the image
+	UV               [][2]float64
+	Hits             []float64    // surfaces hit by a primary ray and its mirror reflections, averaged over all samples
+	Motion           [][2]float64 // pixels the point hit moves across the image from time 0 to time 1, +Y down
+
+	materials []*Material // material hit by most samples of every pixel, numbered once the whole image is done
+}
+
+func newAOVs(width, height int) *AOVs {
+	n := width * height
+
+	return &AOVs{
+		Width:            width,
+		Height:           height,
+		Depth:            make([]float64, n),
+		Distance:         make([]float64, n),
+		Normal:           make([]linmath.Vector3, n),
+		CameraNormal:     make([]linmath.Vector3, n),
+		Albedo:           make([]Color, n),
+		ObjectID:         make([]int, n),
+		ObjectCoverage:   make([]float64, n),
+		MaterialID:       make([]int, n),
+		MaterialCoverage: make([]float64, n),
+		UV:               make([][2]float64, n),
+		Hits:             make([]float64, n),
+		Motion:           make([][2]float64, n),
+		materials:        make([]*Material, n),
+	}
+}
+
+// RenderAOVs is a function that ray traces the scene seen by its camera like RenderContext
+// and also returns the AOVs of the image. Only the ray tracer has them.
+func RenderAOVs(ctx context.Context, scene *Scene, options *Options) (image.Image, *AOVs, *Statistics, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	options = options.withDefaults()
+	stats := &Statistics{}
+
+	if scene.Camera == nil {
+		return nil, nil, stats, errors.New("render: scene has no camera")
+	}
+
+	if options.Backend != RayTraceBackend {
+		return nil, nil, stats, errors.New("render: only the ray tracer renders AOVs")
+	}
+
+	canvas := NewCanvas(options.Width, options.Height, true)
+	aovs := newAOVs(options.Width, options.Height)
+	start := time.Now()
+
+	err := rayTrace(ctx, scene, canvas, aovs, options, stats)
+	stats.addPhase("ray tracing", start)
+	aovs.numberMaterials()
+
+	return canvas, aovs, stats, err
+}
+
+// set is a method that gathers the AOVs of the pixel given in image coordinates from its samples.
+func (a *AOVs) set(x, y int, samples []Sample, scene *Scene) {
+	i := x + y*a.Width
+	camera := scene.Camera
+	toCamera := camera.rotation.Transpose()
+
+	a.Depth[i], a.Distance[i] = math.Inf(1), math.Inf(1)
+
+	var normal, cameraNormal linmath.Vector3
+	var albedo [3]float64
+	var uv, motion [2]float64
+	hit, hits := 0, 0
+
+	for _, s := range samples {
+		hits += s.Hits
+		if s.Object < 0 {
+			continue
+		}
+
+		hit++
+		offset := s.Hit.Point.Subtraction(&camera.position)
+		a.Depth[i] = math.Min(a.Depth[i], toCamera.MultiplyDirection(offset).Z())
+		a.Distance[i] = math.Min(a.Distance[i], offset.Length())
+
+		normal = *normal.Add(s.Hit.Normal)
+		cameraNormal = *cameraNormal.Add(toCamera.MultiplyDirection(s.Hit.Normal))
+		albedo[0], albedo[1], albedo[2] = albedo[0]+float64(s.Hit.Color.r), albedo[1]+float64(s.Hit.Color.g), albedo[2]+float64(s.Hit.Color.b)
+		uv[0], uv[1] = uv[0]+s.Hit.U, uv[1]+s.Hit.V
+
+		dx, dy := a.motion(&s, scene)
+		motion[0], motion[1] = motion[0]+dx, motion[1]+dy
+	}
+
+	a.Hits[i] = float64(hits) / float64(len(samples))
+	if hit == 0 {
+		return
+	}
+
+	n := float64(hit)
+	if normal.Length() > 0 {
+		a.Normal[i], a.CameraNormal[i] = *normal.Normal(), *cameraNormal.Normal()
+	}
+
+	a.Albedo[i] = *NewColor(uint8(math.Round(albedo[0]/n)), uint8(math.Round(albedo[1]/n)), uint8(math.Round(albedo[2]/n)), 255)
+	a.UV[i] = [2]float64{uv[0] / n, uv[1] / n}
+	a.Motion[i] = [2]float64{motion[0] / n, motion[1] / n}
+
+	object, objects := majority(samples, func(s *Sample) interface{} { return s.Object })
+	a.ObjectID[i], a.ObjectCoverage[i] = samples[object].Object+1, float64(objects)/float64(len(samples))
+
+	material, materials := majority(samples, func(s *Sample) interface{} { return newMaterialKey(s.Hit.Material) })
+	a.materials[i], a.MaterialCoverage[i] = samples[material].Hit.Material, float64(materials)/float64(len(samples))
+}
+
+// majority is a function that returns the first of the samples that hit something whose key most of them share,
+// and how many do.
+func majority(samples []Sample, key func(s *Sample) interface{}) (index, count int) {
+	counts := make(map[interface{}]int)
+	index = -1
+
+	for j := range samples {
+		if samples[j].Object < 0 {
+			continue
+		}
+
+		k := key(&samples[j])
+		counts[k]++
+
+		if index < 0 || counts[k] > count {
+			index, count = j, counts[k]
+		}
+	}
+
+	return index, count
+}
+
+// motion is a method that returns how many pixels the point a sample hit moves across the image from time 0 to time 1.
+// Only moving instances placed directly in the scene move.
+func (a *AOVs) motion(s *Sample, scene *Scene) (float64, float64) {
+	instance, ok := scene.Objects[s.Object].(*Instance)
+	if !ok {
+		return 0, 0
+	}
+
+	start, end := instance.travel(s.Hit.Point, s.Time)
+	x0, y0, ok0 := scene.Camera.project(start, a.Width, a.Height)
+	x1, y1, ok1 := scene.Camera.project(end, a.Width, a.Height)
+
+	if !ok0 || !ok1 {
+		return 0, 0
+	}
+
+	return x1 - x0, y1 - y0
+}
+
+// numberMaterials is a method that numbers the materials hit from 1 in the order they first appear in the image,
+// which does not depend on the order the tiles were traced in.
+func (a *AOVs) numberMaterials() {
+	ids := make(map[materialKey]int)
+
+	for i, m := range a.materials {
+		if m == nil {
+			continue
+		}
+
+		key := newMaterialKey(m)
+		id, ok := ids[key]
+		if !ok {
+			a.Materials = append(a.Materials, m)
+			id = len(a.Materials)
+			ids[key] = id
+		}
+
+		a.MaterialID[i] = id
+	}
+
+	a.materials = nil
+}
+
+// materialKey tells materials apart for their IDs. Materials that look the same are the same material,
+// even when every object holds its own copy, so the key holds the settings of the material and the identity of its images.
+type materialKey struct {
+	color                                                                Color
+	specular, reflective, normalScale, metallic, roughness, meanFreePath float64
+	texture, normalMap, metallicRoughness                                interface{}
+}
+
+func newMaterialKey(m *Material) materialKey {
+	// identity is a function that returns what tells an image apart: where it is for the usual pointer to an image,
+	// and otherwise the material holding it, as an image value may not even be comparable.
+	identity := func(img image.Image) interface{} {
+		if img == nil {
+			return nil
+		}
+
+		if v := reflect.ValueOf(img); v.Kind() == reflect.Ptr {
+			return v.Pointer()
+		}
+
+		return m
+	}
+
+	return materialKey{
+		m.color,
+		m.specular, m.reflective, m.normalScale, m.metallic, m.roughness, m.meanFreePath,
+		identity(m.texture), identity(m.normalMap), identity(m.metallicRoughness),
+	}
+}
+
+// ObjectMask is a method that returns the coverage of the pixels whose object has the ID as a mask.
+func (a *AOVs) ObjectMask(id int) *image.Gray {
+	return a.mask(a.ObjectID, a.ObjectCoverage, id)
+}
+
+// MaterialMask is a method that returns the coverage of the pixels whose material has the ID as a mask.
+func (a *AOVs) MaterialMask(id int) *image.Gray {
+	return a.mask(a.MaterialID, a.MaterialCoverage, id)
+}
+
+func (a *AOVs) mask(ids []int, coverage []float64, id int) *image.Gray {
+	mask := image.NewGray(image.Rect(0, 0, a.Width, a.Height))
+
+	for i := range ids {
+		if ids[i] == id && id > 0 {
+			mask.Pix[i] = uint8(math.Round(coverage[i] * 255))
+		}
+	}
+
+	return mask
+}
@@ -0,0 +1,178 @@
+package render
+
+import (
+	"context"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// aovScene is a scene of a gray sphere moving right, another gray sphere on the left and a red one on the right.
+func aovScene() *Scene {
+	gray := *NewMaterial(*NewColor(200, 200, 200, 255), -1, 0, nil)
+	red := *NewMaterial(*NewColor(255, 0, 0, 255), 10, 0, nil)
+
+	return &Scene{
+		Objects: []Object{
+			NewMovingInstance(NewSphere(*linmath.NewVector3(0, 0, 0), 1, gray), linmath.NewTranslation(0, 0, 5), linmath.NewTranslation(1, 0, 5), nil),
+			NewSphere(*linmath.NewVector3(-3, 0, 8), 1, gray),
+			NewSphere(*linmath.NewVector3(3, 1, 8), 1, red),
+		},
+		Lights: []Light{*NewAmbientLight(1)},
+		Camera: NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 0),
+	}
+}
+
+func TestRenderAOVs(t *testing.T) {
+	img, aovs, _, err := RenderAOVs(context.Background(), aovScene(), &Options{Width: 64, Height: 48})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 64 || bounds.Dy() != 48 {
+		t.Fatalf("expected [64x48] but have [%v]", bounds)
+	}
+
+	// Materials are numbered from the top of the image, where the red sphere is highest.
+	// The left and right spheres are crossed through their centers, a unit before them along the rays.
+	left := math.Sqrt(0.375*0.375+1) * 8
+	right := math.Sqrt(0.375*0.375+0.125*0.125+1) * 8
+
+	tests := []struct {
+		name             string
+		inputX, inputY   int
+		expectedDepth    float64
+		expectedDistance float64
+		expectedNormal   linmath.Vector3
+		expectedObject   int
+		expectedMaterial int
+		expectedHits     float64
+		expectedMotion   [2]float64
+	}{
+		{"moving sphere", 32, 23, 4, 4, *linmath.NewVector3(0, 0, -1), 1, 2, 1, [2]float64{12, 0}},
+		{"gray sphere", 14, 23, 8 * (left - 1) / left, left - 1, *linmath.NewVector3(0.375, 0, -1).Normal(), 2, 2, 1, [2]float64{0, 0}},
+		{"red sphere", 50, 17, 8 * (right - 1) / right, right - 1, *linmath.NewVector3(-0.375, -0.125, -1).Normal(), 3, 1, 1, [2]float64{0, 0}},
+		{"background", 0, 0, math.Inf(1), math.Inf(1), *linmath.NewVector3(0, 0, 0), 0, 0, 0, [2]float64{0, 0}},
+	}
+
+	for _, ts := range tests {
+		i := ts.inputX + ts.inputY*aovs.Width
+
+		if have := aovs.Depth[i]; math.Abs(have-ts.expectedDepth) > 1e-9 && have != ts.expectedDepth {
+			t.Fatalf("%s: expected depth [%v] but have [%v]", ts.name, ts.expectedDepth, have)
+		}
+
+		if have := aovs.Distance[i]; math.Abs(have-ts.expectedDistance) > 1e-9 && have != ts.expectedDistance {
+			t.Fatalf("%s: expected distance [%v] but have [%v]", ts.name, ts.expectedDistance, have)
+		}
+
+		// The camera is not turned, so normals are the same in world and camera space.
+		for _, have := range []linmath.Vector3{aovs.Normal[i], aovs.CameraNormal[i]} {
+			if have.Subtraction(&ts.expectedNormal).Length() > 1e-9 {
+				t.Fatalf("%s: expected normal [%v] but have [%v]", ts.name, ts.expectedNormal, have)
+			}
+		}
+
+		if have := aovs.ObjectID[i]; have != ts.expectedObject {
+			t.Fatalf("%s: expected object [%v] but have [%v]", ts.name, ts.expectedObject, have)
+		}
+
+		if have := aovs.MaterialID[i]; have != ts.expectedMaterial {
+			t.Fatalf("%s: expected material [%v] but have [%v]", ts.name, ts.expectedMaterial, have)
+		}
+
+		if have := aovs.Hits[i]; have != ts.expectedHits {
+			t.Fatalf("%s: expected hits [%v] but have [%v]", ts.name, ts.expectedHits, have)
+		}
+
+		if have := aovs.Motion[i]; math.Abs(have[0]-ts.expectedMotion[0]) > 1e-9 || math.Abs(have[1]-ts.expectedMotion[1]) > 1e-9 {
+			t.Fatalf("%s: expected motion [%v] but have [%v]", ts.name, ts.expectedMotion, have)
+		}
+
+		if have, expected := aovs.ObjectMask(ts.expectedObject).GrayAt(ts.inputX, ts.inputY).Y, uint8(255*math.Min(float64(ts.expectedObject), 1)); have != expected {
+			t.Fatalf("%s: expected mask [%v] but have [%v]", ts.name, expected, have)
+		}
+	}
+
+	// Both gray spheres have their own copy of the same material.
+	if len(aovs.Materials) != 2 {
+		t.Fatalf("expected [2] materials but have [%v]", len(aovs.Materials))
+	}
+
+	if _, _, _, err := RenderAOVs(context.Background(), aovScene(), &Options{Backend: RasterBackend}); err == nil {
+		t.Fatalf("expected an error for the raster backend")
+	}
+}
+
+// stripes is an image held by value that cannot be a map key, because it holds a slice.
+type stripes struct {
+	colors []color.Color
+}
+
+func (s stripes) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (s stripes) Bounds() image.Rectangle {
+	return image.Rect(0, 0, len(s.colors), 1)
+}
+
+func (s stripes) At(x, y int) color.Color {
+	return s.colors[x%len(s.colors)]
+}
+
+func TestMaterialIDs(t *testing.T) {
+	shared := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	byValue := stripes{[]color.Color{color.White, color.Black}}
+
+	tests := []struct {
+		name              string
+		inputTextures     [2]image.Image
+		expectedMaterials int
+	}{
+		{"no textures", [2]image.Image{nil, nil}, 1},
+		{"the same texture", [2]image.Image{shared, shared}, 1},
+		{"different textures", [2]image.Image{shared, image.NewRGBA(image.Rect(0, 0, 1, 1))}, 2},
+		// Images held by value cannot be compared, so every copy of a material holding one counts as another material.
+		{"textures held by value", [2]image.Image{byValue, byValue}, 2},
+	}
+
+	for _, ts := range tests {
+		scene := aovScene()
+		scene.Objects = []Object{
+			NewSphere(*linmath.NewVector3(-2, 0, 8), 1, *NewMaterial(*NewColor(200, 200, 200, 255), -1, 0, ts.inputTextures[0])),
+			NewSphere(*linmath.NewVector3(2, 0, 8), 1, *NewMaterial(*NewColor(200, 200, 200, 255), -1, 0, ts.inputTextures[1])),
+		}
+
+		_, aovs, _, err := RenderAOVs(context.Background(), scene, &Options{Width: 32, Height: 24})
+		if err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+
+		if len(aovs.Materials) != ts.expectedMaterials {
+			t.Fatalf("%s: expected [%v] materials but have [%v]", ts.name, ts.expectedMaterials, len(aovs.Materials))
+		}
+	}
+}
+
+func TestCameraProject(t *testing.T) {
+	camera := NewCamera(linmath.NewVector3(1, 2, -3), linmath.NewRotationY(linmath.Radians(30)).Multiply(linmath.NewRotationX(linmath.Radians(-10))), 0, 1, 0, 0, 0)
+	width, height := 64, 48
+
+	// Points along the ray of a pixel project back to the pixel.
+	for _, pixel := range [][2]int{{0, 0}, {32, 23}, {63, 47}, {10, 40}} {
+		y := height - pixel[1] - 1
+		ray := camera.Ray(float64(pixel[0])-float64(width)/2, float64(y)-float64(height)/2, width, height, NewSampler(RandomSampler, 0))
+
+		x, iy, ok := camera.project(ray.At(7), width, height)
+		if !ok || math.Abs(x-float64(pixel[0])) > 1e-9 || math.Abs(iy-float64(pixel[1])) > 1e-9 {
+			t.Fatalf("expected [%v] but have [%v %v %v]", pixel, x, iy, ok)
+		}
+	}
+
+	if _, _, ok := camera.project(linmath.NewVector3(1, 2, -10), width, height); ok {
+		t.Fatalf("expected a point behind the camera not to project")
+	}
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"math"
+	"sync"
+)
+
+// blueNoiseSize is the side of the tiled blue-noise texture.
+const blueNoiseSize = 64
+
+var (
+	blueNoiseOnce sync.Once
+	blueNoise     []float64
+)
+
+// blueNoiseTexture is a function that returns the blue-noise texture, generating it on first use.
+func blueNoiseTexture() []float64 {
+	blueNoiseOnce.Do(func() {
+		blueNoise = newBlueNoise(blueNoiseSize, 1.5)
+	})
+
+	return blueNoise
+}
+
+// blueNoiseSampler gives every pixel the same Owen-scrambled Sobol points and rotates each dimension modulo 1
+// by a value of a blue-noise texture shifted by a random offset per dimension. Neighbouring pixels then get
+// offsets far apart, so the error left by few samples looks like fine, high-frequency grain instead of blotches.
+type blueNoiseSampler struct {
+	seed      uint64
+	texture   []float64
+	x, y      int
+	index     uint32
+	dimension int
+}
+
+func (s *blueNoiseSampler) Start(x, y, index int) {
+	s.x, s.y, s.index, s.dimension = x, y, uint32(index), 0
+}
+
+func (s *blueNoiseSampler) Float64() float64 {
+	d := uint64(s.dimension)
+	s.dimension++
+
+	offset := hash(s.seed, d)
+	tx := (s.x + int(offset%blueNoiseSize)) % blueNoiseSize
+	ty := (s.y + int(offset/blueNoiseSize%blueNoiseSize)) % blueNoiseSize
+	v := owenSobol(s.index, int(d), s.seed) + s.texture[ty*blueNoiseSize+tx]
+
+	return v - math.Floor(v)
+}
+
+// newBlueNoise is a function that generates a tileable blue-noise texture with the void-and-cluster method
+// of Ulichney (1993): every texel gets a distinct rank in [0, 1), and texels of similar rank are spread evenly.
+// The energy of a texel sums a Gaussian of width sigma around every texel that is on, wrapping around the edges;
+// the tightest cluster is the one with the highest energy and the largest void is the free texel with the lowest.
+func newBlueNoise(side int, sigma float64) []float64 {
+	n := side * side
+
+	kernel := make([]float64, n)
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			dx, dy := float64(minInt(x, side-x)), float64(minInt(y, side-y))
+			kernel[y*side+x] = math.Exp(-(dx*dx + dy*dy) / (2 * sigma * sigma))
+		}
+	}
+
+	pattern, energy := make([]bool, n), make([]float64, n)
+	set := func(p int, on bool) {
+		pattern[p] = on
+
+		sign := 1.
+		if !on {
+			sign = -1
+		}
+
+		px, py := p%side, p/side
+		for y := 0; y < side; y++ {
+			row := (y - py + side) % side * side
+			for x := 0; x < side; x++ {
+				energy[y*side+x] += sign * kernel[row+(x-px+side)%side]
+			}
+		}
+	}
+
+	extreme := func(on bool, better func(a, b float64) bool) int {
+		best := -1
+		for p := range pattern {
+			if pattern[p] == on && (best < 0 || better(energy[p], energy[best])) {
+				best = p
+			}
+		}
+
+		return best
+	}
+	tightestCluster := func() int { return extreme(true, func(a, b float64) bool { return a > b }) }
+	largestVoid := func() int { return extreme(false, func(a, b float64) bool { return a < b }) }
+
+	// Start from a tenth of the texels on at random, then move the tightest cluster into the largest void
+	// until that would put it back where it was.
+	random := NewPCG(1, 1)
+	ones := 0
+	for ones < n/10 {
+		if p := int(random.Uint32() % uint32(n)); !pattern[p] {
+			set(p, true)
+			ones++
+		}
+	}
+
+	for {
+		cluster := tightestCluster()
+		set(cluster, false)
+
+		void := largestVoid()
+		set(void, true)
+
+		if void == cluster {
+			break
+		}
+	}
+
+	initialPattern := append([]bool(nil), pattern...)
+	initialEnergy := append([]float64(nil), energy...)
+	rank := make([]int, n)
+
+	// The texels of the initial pattern get the lowest ranks, the most clustered the highest among them.
+	for r := ones - 1; r >= 0; r-- {
+		cluster := tightestCluster()
+		set(cluster, false)
+		rank[cluster] = r
+	}
+
+	copy(pattern, initialPattern)
+	copy(energy, initialEnergy)
+
+	// The rest fill the largest voids in turn. Past half of the texels this is the same as picking
+	// the tightest cluster of the free texels, so one loop covers both phases of the method.
+	for r := ones; r < n; r++ {
+		void := largestVoid()
+		set(void, true)
+		rank[void] = r
+	}
+
+	texture := make([]float64, n)
+	for p, r := range rank {
+		texture[p] = (float64(r) + 0.5) / float64(n)
+	}
+
+	return texture
+}
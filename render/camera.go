@@ -0,0 +1,136 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// Camera is a thin-lens camera looking along +Z of its own space.
+// With a zero aperture radius it is a pinhole and everything is in perfect focus.
+// Rays are spread over the shutter interval, so objects moving during it are blurred.
+type Camera struct {
+	position       linmath.Vector3
+	rotation       linmath.Matrix4
+	apertureRadius float64
+	focusDistance  float64 // distance along the view axis to the plane that is in perfect focus
+	bladeCount     int     // number of aperture blades shaping the bokeh, less than 3 makes it round
+	shutterOpen    float64 // frame time of the first ray, objects move from time 0 to time 1
+	shutterClose   float64
+	fieldOfView    float64 // vertical, in radians; zero keeps the viewport of viewportSize at projectionPlaneZ
+}
+
+func NewCamera(
+	position *linmath.Vector3,
+	rotation *linmath.Matrix4,
+	apertureRadius, focusDistance float64,
+	bladeCount int,
+	shutterOpen, shutterClose float64,
+) *Camera {
+	return &Camera{*position, *rotation, apertureRadius, focusDistance, bladeCount, shutterOpen, shutterClose, 0}
+}
+
+// zoom is a method that returns how much wider than the default viewport the field of view of the camera is.
+func (c *Camera) zoom() float64 {
+	if c.fieldOfView <= 0 {
+		return 1
+	}
+
+	return math.Tan(c.fieldOfView/2) * projectionPlaneZ / (viewportSize / 2.)
+}
+
+// samples is a method that returns the number of primary rays per pixel: a pinhole camera with a closed shutter
+// sees the same point through every sample, so it needs only one.
+func (c *Camera) samples(samples int) int {
+	if c.apertureRadius > 0 || c.shutterOpen < c.shutterClose {
+		return samples
+	}
+
+	return 1
+}
+
+// Ray is a method that returns a primary ray through the point (x, y) of a canvas of the given size in world space.
+// The ray of a pinhole starts at the camera position. Otherwise the origin is sampled on the lens
+// and the ray is aimed at the point where the pinhole ray meets the focus plane, so only that plane is sharp.
+// The lens takes the first dimensions of the current sample of the sampler and the shutter the next one.
+func (c *Camera) Ray(x, y float64, width, height int, sampler Sampler) *linmath.Ray {
+	origin := linmath.NewVector3(0., 0., 0.)
+	direction := CanvasToViewPort(x, y, width, height)
+	if zoom := c.zoom(); zoom != 1 {
+		direction = linmath.NewVector3(direction.X()*zoom, direction.Y()*zoom, direction.Z())
+	}
+
+	if c.apertureRadius > 0 {
+		focus := direction.MultiplyOnScalar(c.focusDistance / direction.Z())
+		u, v := sampleAperture(c.bladeCount, sampler.Float64(), sampler.Float64(), sampler.Float64())
+		origin = linmath.NewVector3(u*c.apertureRadius, v*c.apertureRadius, 0.)
+		direction = focus.Subtraction(origin)
+	}
+
+	time := c.shutterOpen
+	if c.shutterOpen < c.shutterClose {
+		time += sampler.Float64() * (c.shutterClose - c.shutterOpen)
+	}
+
+	return linmath.NewRay(
+		c.position.Add(c.rotation.MultiplyDirection(origin)),
+		c.rotation.MultiplyDirection(direction),
+		time,
+	)
+}
+
+// sampleAperture is a function that maps uniform random numbers to a uniformly distributed point
+// on the unit lens, which is a disk or a regular polygon with bladeCount corners.
+func sampleAperture(bladeCount int, u1, u2, u3 float64) (x, y float64) {
+	if bladeCount < 3 {
+		return sampleDisk(u1, u2)
+	}
+
+	// Pick one of the triangles fanning out from the center, then a point inside it.
+	blade := math.Min(math.Floor(u3*float64(bladeCount)), float64(bladeCount-1))
+	angle := 2 * math.Pi / float64(bladeCount)
+	sin0, cos0 := math.Sincos(blade * angle)
+	sin1, cos1 := math.Sincos((blade + 1) * angle)
+
+	s := math.Sqrt(u1)
+	a, b := s*(1-u2), s*u2
+
+	return a*cos0 + b*cos1, a*sin0 + b*sin1
+}
+
+// sampleDisk is a function that maps the unit square to the unit disk with Shirley's concentric mapping,
+// which keeps neighbouring samples close together.
+func sampleDisk(u1, u2 float64) (x, y float64) {
+	a, b := 2*u1-1, 2*u2-1
+
+	if a == 0 && b == 0 {
+		return 0, 0
+	}
+
+	var r, phi float64
+	if math.Abs(a) > math.Abs(b) {
+		r, phi = a, math.Pi/4*(b/a)
+	} else {
+		r, phi = b, math.Pi/2-math.Pi/4*(a/b)
+	}
+
+	sin, cos := math.Sincos(phi)
+
+	return r * cos, r * sin
+}
+
+// project is a method that returns the image coordinates on a canvas of the given size of the point,
+// where the ray of a pinhole at the camera position through the point crosses the canvas. This undoes Ray
+// for a pinhole camera, so a pixel of the image projects back to itself. Points behind the camera are not on the canvas.
+func (c *Camera) project(point *linmath.Vector3, width, height int) (x, y float64, ok bool) {
+	local := c.rotation.Transpose().MultiplyDirection(point.Subtraction(&c.position))
+	if local.Z() <= 0 {
+		return 0, 0, false
+	}
+
+	viewportWidth := viewportSize * float64(width) / float64(height)
+	scale := projectionPlaneZ / (local.Z() * c.zoom())
+	canvasX := local.X() * scale * float64(width) / viewportWidth
+	canvasY := local.Y() * scale * float64(height) / viewportSize
+
+	return canvasX + float64(width)/2, float64(height)/2 - canvasY - 1, true
+}
@@ -0,0 +1,100 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestSampleAperture(t *testing.T) {
+	for _, blades := range []int{0, 3, 5, 6} {
+		n := 24
+		for i := 0; i <= n; i++ {
+			for j := 0; j <= n; j++ {
+				for _, u3 := range []float64{0, 0.3, 0.7, 0.999} {
+					x, y := sampleAperture(blades, float64(i)/float64(n), float64(j)/float64(n), u3)
+
+					if math.Hypot(x, y) > 1+1e-12 {
+						t.Fatalf("%v blades: expected a point inside the unit disk but have [%v %v]", blades, x, y)
+					}
+
+					// A polygon has its corners on the unit circle, and a point inside is left of every edge.
+					for k := 0; blades >= 3 && k < blades; k++ {
+						sin0, cos0 := math.Sincos(2 * math.Pi * float64(k) / float64(blades))
+						sin1, cos1 := math.Sincos(2 * math.Pi * float64(k+1) / float64(blades))
+
+						if cross := (cos1-cos0)*(y-sin0) - (sin1-sin0)*(x-cos0); cross < -1e-12 {
+							t.Fatalf("%v blades: expected a point inside the polygon but have [%v %v]", blades, x, y)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// The corners of the square land on the circle, and its center on the center of the disk.
+	tests := []struct {
+		name     string
+		inputU1  float64
+		inputU2  float64
+		expected float64
+	}{
+		{"center", 0.5, 0.5, 0},
+		{"corner", 1, 1, 1},
+		{"opposite corner", 0, 0, 1},
+		{"edge", 1, 0.5, 1},
+		{"halfway", 0.75, 0.5, 0.5},
+	}
+
+	for _, ts := range tests {
+		if have := math.Hypot(sampleDisk(ts.inputU1, ts.inputU2)); math.Abs(have-ts.expected) > 1e-12 {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expected, have)
+		}
+	}
+}
+
+func TestCameraRay(t *testing.T) {
+	position := linmath.NewVector3(1, 2, -3)
+	rotation := linmath.NewRotationY(linmath.Radians(30)).Multiply(linmath.NewRotationX(linmath.Radians(-10)))
+	width, height := 64, 48
+	focus := 4.
+
+	pinhole := NewCamera(position, rotation, 0, focus, 0, 0, 0)
+	sampler := NewSampler(RandomSampler, 1)
+
+	for _, pixel := range [][2]float64{{0, 0}, {-32, 23}, {31, -24}, {10, -5}} {
+		// Without an aperture the ray leaves the camera position through the point of the viewport.
+		ray := pinhole.Ray(pixel[0], pixel[1], width, height, sampler)
+		direction := rotation.MultiplyDirection(CanvasToViewPort(pixel[0], pixel[1], width, height))
+
+		if !equalVectors(&ray.Origin, position) || !equalVectors(&ray.Direction, direction) {
+			t.Fatalf("%v: expected the pinhole ray [%v %v] but have [%v %v]", pixel, position, direction, ray.Origin, ray.Direction)
+		}
+
+		// The pinhole ray crosses the focus plane at focus along the view axis, where the direction has a z of 1.
+		focused := ray.At(focus)
+
+		for _, blades := range []int{0, 6} {
+			lens := NewCamera(position, rotation, 0.5, focus, blades, 0, 0)
+
+			spread := 0.
+			for i := 0; i < 64; i++ {
+				sampler.Start(0, 0, i)
+				ray := lens.Ray(pixel[0], pixel[1], width, height, sampler)
+
+				// Every lens ray of the pixel passes through the same point of the focus plane.
+				local := rotation.Transpose().MultiplyDirection(&ray.Direction)
+				depth := rotation.Transpose().MultiplyDirection(ray.Origin.Subtraction(position)).Z()
+				if have := ray.At((focus - depth) / local.Z()); !equalVectors(have, focused) {
+					t.Fatalf("%v, %v blades: expected the ray through [%v] but have [%v]", pixel, blades, focused, have)
+				}
+
+				spread = math.Max(spread, ray.Origin.Subtraction(position).Length())
+			}
+
+			if spread == 0 || spread > 0.5+1e-9 {
+				t.Fatalf("%v, %v blades: expected origins spread over the lens but have [%v]", pixel, blades, spread)
+			}
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Canvas is the framebuffer the renderers draw into. It implements draw.Image, so the standard library
+// can encode or compose it directly; At and Set always take image coordinates, as draw.Image requires.
+//
+// PutPixel and the drawing primitives take canvas coordinates instead. A centered canvas puts the origin
+// in the middle with Y pointing up, as the renderers think of it; otherwise the origin is the top-left corner
+// with Y pointing down, like in image.Image.
+type Canvas struct {
+	width    int
+	height   int
+	pixels   []Color
+	centered bool
+}
+
+func NewCanvas(width, height int, centered bool) *Canvas {
+	return &Canvas{
+		width:    width,
+		height:   height,
+		pixels:   make([]Color, width*height),
+		centered: centered,
+	}
+}
+
+func (c *Canvas) Width() int {
+	return c.width
+}
+
+func (c *Canvas) Height() int {
+	return c.height
+}
+
+// SetCentered is a method that switches between centered and top-left canvas coordinates.
+func (c *Canvas) SetCentered(centered bool) {
+	c.centered = centered
+}
+
+// toImage is a method that converts canvas coordinates to image coordinates.
+func (c *Canvas) toImage(x, y int) (int, int) {
+	if c.centered {
+		return c.width/2 + x, c.height/2 - y - 1
+	}
+
+	return x, y
+}
+
+func (c *Canvas) inside(x, y int) bool {
+	return x >= 0 && x < c.width && y >= 0 && y < c.height
+}
+
+// set is a method that overwrites a pixel given in image coordinates, ignoring pixels outside of the canvas.
+func (c *Canvas) set(x, y int, color *Color) {
+	if c.inside(x, y) {
+		c.pixels[x+y*c.width] = *color
+	}
+}
+
+// blend is a method that composites a color over a pixel given in image coordinates using its alpha.
+func (c *Canvas) blend(x, y int, color *Color) {
+	if !c.inside(x, y) {
+		return
+	}
+
+	if color.a == 255 {
+		c.pixels[x+y*c.width] = *color
+		return
+	}
+
+	destination := &c.pixels[x+y*c.width]
+	alpha := float64(color.a) / 255
+	destinationAlpha := float64(destination.a) / 255 * (1 - alpha)
+	outAlpha := alpha + destinationAlpha
+
+	if outAlpha == 0 {
+		*destination = Color{}
+		return
+	}
+
+	mix := func(source, destination uint8) uint8 {
+		return uint8((float64(source)*alpha+float64(destination)*destinationAlpha)/outAlpha + 0.5)
+	}
+
+	*destination = Color{
+		mix(color.r, destination.r),
+		mix(color.g, destination.g),
+		mix(color.b, destination.b),
+		uint8(outAlpha*255 + 0.5),
+	}
+}
+
+// PutPixel is a method that overwrites the pixel at the canvas coordinates.
+func (c *Canvas) PutPixel(x, y int, color *Color) {
+	x, y = c.toImage(x, y)
+	c.set(x, y, color)
+}
+
+// Pixel is a method that returns the color at the canvas coordinates, or a transparent color outside of the canvas.
+func (c *Canvas) Pixel(x, y int) Color {
+	x, y = c.toImage(x, y)
+	if !c.inside(x, y) {
+		return Color{}
+	}
+
+	return c.pixels[x+y*c.width]
+}
+
+// BlendPixel is a method that composites the color over the pixel at the canvas coordinates.
+func (c *Canvas) BlendPixel(x, y int, color *Color) {
+	x, y = c.toImage(x, y)
+	c.blend(x, y, color)
+}
+
+func (c *Canvas) Clear(color *Color) {
+	for i := range c.pixels {
+		c.pixels[i] = *color
+	}
+}
+
+// DrawLine is a method that draws a line between two points with Bresenham's algorithm.
+func (c *Canvas) DrawLine(x0, y0, x1, y1 int, color *Color) {
+	x0, y0 = c.toImage(x0, y0)
+	x1, y1 = c.toImage(x1, y1)
+	c.drawLine(x0, y0, x1, y1, color)
+}
+
+// drawLine is a method that draws a line between two points given in image coordinates.
+func (c *Canvas) drawLine(x0, y0, x1, y1 int, color *Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+
+	if x1 < x0 {
+		sx = -1
+	}
+
+	if y1 < y0 {
+		sy = -1
+	}
+
+	for err := dx + dy; ; {
+		c.blend(x0, y0, color)
+
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * err
+
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// DrawCircle is a method that draws the outline of a circle with the midpoint algorithm.
+func (c *Canvas) DrawCircle(centerX, centerY, radius int, color *Color) {
+	cx, cy := c.toImage(centerX, centerY)
+	x, y := radius, 0
+	err := 1 - radius
+
+	for x >= y {
+		points := [8]image.Point{
+			{cx + x, cy + y}, {cx + y, cy + x}, {cx - y, cy + x}, {cx - x, cy + y},
+			{cx - x, cy - y}, {cx - y, cy - x}, {cx + y, cy - x}, {cx + x, cy - y},
+		}
+
+	octants:
+		for i, p := range points {
+			// Where octants meet they share points, which must be blended only once.
+			for _, q := range points[:i] {
+				if p == q {
+					continue octants
+				}
+			}
+
+			c.blend(p.X, p.Y, color)
+		}
+
+		y++
+
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+}
+
+// FillCircle is a method that fills the disk of a circle.
+func (c *Canvas) FillCircle(centerX, centerY, radius int, color *Color) {
+	cx, cy := c.toImage(centerX, centerY)
+
+	for dy := -radius; dy <= radius; dy++ {
+		dx := int(math.Sqrt(float64(radius*radius - dy*dy)))
+		for x := cx - dx; x <= cx+dx; x++ {
+			c.blend(x, cy+dy, color)
+		}
+	}
+}
+
+// DrawPolygon is a method that draws the closed outline through the points.
+func (c *Canvas) DrawPolygon(points []image.Point, color *Color) {
+	for i := range points {
+		next := points[(i+1)%len(points)]
+		c.DrawLine(points[i].X, points[i].Y, next.X, next.Y, color)
+	}
+}
+
+// FillPolygon is a method that fills a polygon, which may be concave or self-intersecting,
+// with the even-odd rule. A pixel is filled when its center lies inside or on a top or left edge,
+// so polygons sharing an edge do not overlap.
+func (c *Canvas) FillPolygon(points []image.Point, color *Color) {
+	if len(points) < 3 {
+		return
+	}
+
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	minY, maxY := math.Inf(1), math.Inf(-1)
+
+	for i, p := range points {
+		x, y := c.toImage(p.X, p.Y)
+		xs[i], ys[i] = float64(x), float64(y)
+		minY, maxY = math.Min(minY, ys[i]), math.Max(maxY, ys[i])
+	}
+
+	for y := math.Max(minY, 0); y <= math.Min(maxY, float64(c.height-1)); y++ {
+		var crossings []float64
+
+		for i := range points {
+			j := (i + 1) % len(points)
+			if (ys[i] <= y) != (ys[j] <= y) {
+				crossings = append(crossings, xs[i]+(y-ys[i])*(xs[j]-xs[i])/(ys[j]-ys[i]))
+			}
+		}
+
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			for x := math.Ceil(crossings[i]); x < crossings[i+1]; x++ {
+				c.blend(int(x), int(y), color)
+			}
+		}
+	}
+}
+
+func (c *Canvas) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (c *Canvas) Bounds() image.Rectangle {
+	return image.Rect(0, 0, c.width, c.height)
+}
+
+func (c *Canvas) At(x, y int) color.Color {
+	if !c.inside(x, y) {
+		return Color{}
+	}
+
+	return c.pixels[x+y*c.width]
+}
+
+func (c *Canvas) Set(x, y int, col color.Color) {
+	nrgba := color.NRGBAModel.Convert(col).(color.NRGBA)
+	c.set(x, y, NewColor(nrgba.R, nrgba.G, nrgba.B, nrgba.A))
+}
+
+// Image is a method that copies the canvas into a new image.NRGBA.
+func (c *Canvas) Image() *image.NRGBA {
+	img := image.NewNRGBA(c.Bounds())
+
+	for i, p := range c.pixels {
+		copy(img.Pix[4*i:], []uint8{p.r, p.g, p.b, p.a})
+	}
+
+	return img
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
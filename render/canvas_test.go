@@ -0,0 +1,124 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestCanvasCoordinates(t *testing.T) {
+	red := NewColor(255, 0, 0, 255)
+
+	tests := []struct {
+		inputCentered bool
+		inputX        int
+		inputY        int
+		expectedX     int
+		expectedY     int
+	}{
+		{true, 0, 0, 2, 1},
+		{true, -2, 1, 0, 0},
+		{true, 1, -2, 3, 3},
+		{false, 0, 0, 0, 0},
+		{false, 3, 2, 3, 2},
+	}
+
+	for _, ts := range tests {
+		canvas := NewCanvas(4, 4, ts.inputCentered)
+		canvas.PutPixel(ts.inputX, ts.inputY, red)
+
+		if at := canvas.At(ts.expectedX, ts.expectedY); at != *red {
+			t.Fatalf("expected [%v] at [%v %v] but have [%v]", *red, ts.expectedX, ts.expectedY, at)
+		}
+
+		if pixel := canvas.Pixel(ts.inputX, ts.inputY); pixel != *red {
+			t.Fatalf("expected [%v] but have [%v]", *red, pixel)
+		}
+	}
+}
+
+func TestCanvasBlendPixel(t *testing.T) {
+	tests := []struct {
+		inputBackground *Color
+		inputColor      *Color
+		expectedColor   Color
+	}{
+		{NewColor(0, 0, 0, 255), NewColor(255, 255, 255, 255), Color{255, 255, 255, 255}},
+		{NewColor(0, 0, 0, 255), NewColor(255, 255, 255, 0), Color{0, 0, 0, 255}},
+		{NewColor(0, 0, 255, 255), NewColor(255, 0, 0, 128), Color{128, 0, 127, 255}},
+		{NewColor(0, 0, 0, 0), NewColor(255, 0, 0, 128), Color{255, 0, 0, 128}},
+	}
+
+	for _, ts := range tests {
+		canvas := NewCanvas(1, 1, false)
+		canvas.Clear(ts.inputBackground)
+		canvas.BlendPixel(0, 0, ts.inputColor)
+
+		if pixel := canvas.Pixel(0, 0); pixel != ts.expectedColor {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedColor, pixel)
+		}
+	}
+}
+
+func TestCanvasDrawLine(t *testing.T) {
+	canvas := NewCanvas(5, 5, false)
+	canvas.DrawLine(0, 0, 4, 2, NewColor(255, 255, 255, 255))
+
+	expected := []image.Point{{0, 0}, {1, 1}, {2, 1}, {3, 2}, {4, 2}}
+	drawn := 0
+
+	for _, p := range expected {
+		if canvas.Pixel(p.X, p.Y).a != 255 {
+			t.Fatalf("expected the line to cover [%v]", p)
+		}
+	}
+
+	for _, p := range canvas.pixels {
+		if p.a != 0 {
+			drawn++
+		}
+	}
+
+	if drawn != len(expected) {
+		t.Fatalf("expected [%v] pixels but have [%v]", len(expected), drawn)
+	}
+}
+
+func TestCanvasFillPolygon(t *testing.T) {
+	canvas := NewCanvas(10, 10, false)
+	canvas.FillPolygon([]image.Point{{2, 2}, {7, 2}, {7, 7}, {2, 7}}, NewColor(0, 255, 0, 255))
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			expected := x >= 2 && x < 7 && y >= 2 && y < 7
+			if (canvas.Pixel(x, y).a != 0) != expected {
+				t.Fatalf("expected filled [%v] at [%v %v]", expected, x, y)
+			}
+		}
+	}
+}
+
+func TestCanvasDrawImage(t *testing.T) {
+	var _ draw.Image = (*Canvas)(nil)
+
+	canvas := NewCanvas(4, 4, true)
+	draw.Draw(canvas, image.Rect(1, 1, 3, 3), image.NewUniform(color.NRGBA{R: 10, G: 20, B: 30, A: 255}), image.Point{}, draw.Src)
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			expected := Color{}
+			if x >= 1 && x < 3 && y >= 1 && y < 3 {
+				expected = Color{10, 20, 30, 255}
+			}
+
+			if at := canvas.At(x, y); at != expected {
+				t.Fatalf("expected [%v] at [%v %v] but have [%v]", expected, x, y, at)
+			}
+		}
+	}
+
+	if img := canvas.Image(); img.NRGBAAt(1, 1) != (color.NRGBA{R: 10, G: 20, B: 30, A: 255}) {
+		t.Fatalf("expected the image to match the canvas but have [%v]", img.NRGBAAt(1, 1))
+	}
+}
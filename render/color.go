@@ -0,0 +1,33 @@
+package render
+
+import (
+	"image/color"
+	"math"
+)
+
+type Color struct {
+	r, g, b, a uint8
+}
+
+func NewColor(r, g, b, a uint8) *Color {
+	return &Color{r, g, b, a}
+}
+
+// RGBA is a method that implements color.Color, so a Color can be used with the image packages.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return color.NRGBA{R: c.r, G: c.g, B: c.b, A: c.a}.RGBA()
+}
+
+// MultiplyOnScalar is a method that scales the color channels, clamping them to the valid range.
+func (c *Color) MultiplyOnScalar(scalar float64) *Color {
+	return NewColor(clampChannel(float64(c.r)*scalar), clampChannel(float64(c.g)*scalar), clampChannel(float64(c.b)*scalar), c.a)
+}
+
+// Add is a method that sums the color channels, clamping them to the valid range.
+func (c *Color) Add(c2 *Color) *Color {
+	return NewColor(clampChannel(float64(c.r)+float64(c2.r)), clampChannel(float64(c.g)+float64(c2.g)), clampChannel(float64(c.b)+float64(c2.b)), c.a)
+}
+
+func clampChannel(value float64) uint8 {
+	return uint8(math.Min(math.Max(value, 0), 255) + 0.5)
+}
@@ -0,0 +1,158 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"sort"
+)
+
+// interval is a span of a ray that lies inside a solid.
+type interval struct {
+	enter Hit
+	exit  Hit
+}
+
+// solid is a closed object that can report every span of a ray lying inside it, not just the nearest hit.
+// Intervals are sorted by t, do not overlap and cover the whole line, including the part behind the origin.
+type solid interface {
+	Object
+	Intervals(ray *linmath.Ray) []interval
+}
+
+type operation int
+
+const (
+	union operation = iota
+	intersection
+	difference
+)
+
+// contains is a method that tells whether a point inside or outside of both operands is inside the result.
+func (op operation) contains(insideLeft, insideRight bool) bool {
+	switch op {
+	case union:
+		return insideLeft || insideRight
+	case intersection:
+		return insideLeft && insideRight
+	default:
+		return insideLeft && !insideRight
+	}
+}
+
+// CSG is a node of a constructive solid geometry tree combining two solids with a boolean operation.
+type CSG struct {
+	operation operation
+	left      solid
+	right     solid
+	bounds    linmath.AABB
+}
+
+func newCSG(operation operation, left, right solid) *CSG {
+	var bounds *linmath.AABB
+
+	switch operation {
+	case union:
+		bounds = left.Bounds().Union(right.Bounds())
+	case intersection:
+		bounds = left.Bounds().Intersection(right.Bounds())
+	default:
+		bounds = left.Bounds()
+	}
+
+	return &CSG{operation, left, right, *bounds}
+}
+
+func NewUnion(left, right solid) *CSG {
+	return newCSG(union, left, right)
+}
+
+func NewIntersection(left, right solid) *CSG {
+	return newCSG(intersection, left, right)
+}
+
+// NewDifference is a function that returns the part of left that is not inside right.
+func NewDifference(left, right solid) *CSG {
+	return newCSG(difference, left, right)
+}
+
+// Intervals is a method that sweeps the boundaries of both operands in order of t
+// and keeps the spans where the boolean operation holds.
+func (c *CSG) Intervals(ray *linmath.Ray) []interval {
+	if !c.bounds.IntersectRay(&ray.Origin, &ray.Direction, math.Inf(-1), math.Inf(1)) {
+		return nil
+	}
+
+	type event struct {
+		Hit
+		right    bool
+		entering bool
+	}
+
+	var events []event
+
+	for _, i := range c.left.Intervals(ray) {
+		events = append(events, event{i.enter, false, true}, event{i.exit, false, false})
+	}
+
+	for _, i := range c.right.Intervals(ray) {
+		events = append(events, event{i.enter, true, true}, event{i.exit, true, false})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].T < events[j].T
+	})
+
+	var (
+		result                  []interval
+		start                   Hit
+		insideLeft, insideRight bool
+		inside                  bool
+	)
+
+	for _, e := range events {
+		if e.right {
+			insideRight = e.entering
+		} else {
+			insideLeft = e.entering
+		}
+
+		// The surface of a subtracted solid faces into the result, so its normal is flipped.
+		if e.right && c.operation == difference {
+			e.Normal = e.Normal.Negative()
+		}
+
+		now := c.operation.contains(insideLeft, insideRight)
+
+		switch {
+		case now && !inside:
+			start = e.Hit
+		case !now && inside && start.T < e.T:
+			result = append(result, interval{start, e.Hit})
+		}
+
+		inside = now
+	}
+
+	return result
+}
+
+func (c *CSG) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	return nearestHit(c.Intervals(ray), minT, maxT)
+}
+
+func (c *CSG) Bounds() *linmath.AABB {
+	return linmath.NewAABB(c.bounds.Min(), c.bounds.Max())
+}
+
+// nearestHit is a function that finds the first boundary of the intervals strictly between minT and maxT.
+func nearestHit(intervals []interval, minT, maxT float64) (Hit, bool) {
+	for _, i := range intervals {
+		for _, h := range [2]Hit{i.enter, i.exit} {
+			if minT < h.T && h.T < maxT {
+				return h, true
+			}
+		}
+	}
+
+	return Hit{}, false
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestCSGIntervals(t *testing.T) {
+	// Unit spheres on the x axis, the ray along it crossing a from t = 4 to 6, b from 5 to 7 and far from 9 to 11.
+	a := NewSphere(*linmath.NewVector3(0, 0, 0), 1, Material{})
+	b := NewSphere(*linmath.NewVector3(1, 0, 0), 1, Material{})
+	far := NewSphere(*linmath.NewVector3(5, 0, 0), 1, Material{})
+	ray := linmath.NewRay(linmath.NewVector3(-5, 0, 0), linmath.NewVector3(1, 0, 0), 0)
+
+	left, right := linmath.NewVector3(-1, 0, 0), linmath.NewVector3(1, 0, 0)
+
+	tests := []struct {
+		name            string
+		inputCSG        *CSG
+		expectedSpans   [][2]float64
+		expectedNormals [][2]*linmath.Vector3 // normals at the enter and exit of every span
+	}{
+		{"union", NewUnion(a, b), [][2]float64{{4, 7}}, [][2]*linmath.Vector3{{left, right}}},
+		{"intersection", NewIntersection(a, b), [][2]float64{{5, 6}}, [][2]*linmath.Vector3{{left, right}}},
+		// The subtracted sphere is left through its surface, which then faces out of the result.
+		{"difference", NewDifference(a, b), [][2]float64{{4, 5}}, [][2]*linmath.Vector3{{left, right}}},
+		{"reversed difference", NewDifference(b, a), [][2]float64{{6, 7}}, [][2]*linmath.Vector3{{left, right}}},
+		{"disjoint union", NewUnion(a, far), [][2]float64{{4, 6}, {9, 11}}, [][2]*linmath.Vector3{{left, right}, {left, right}}},
+		{"disjoint intersection", NewIntersection(a, far), nil, nil},
+		{"disjoint difference", NewDifference(a, far), [][2]float64{{4, 6}}, [][2]*linmath.Vector3{{left, right}}},
+		{"nested", NewDifference(NewUnion(a, b), far), [][2]float64{{4, 7}}, [][2]*linmath.Vector3{{left, right}}},
+	}
+
+	for _, ts := range tests {
+		intervals := ts.inputCSG.Intervals(ray)
+		if len(intervals) != len(ts.expectedSpans) {
+			t.Fatalf("%s: expected [%v] spans but have [%v]", ts.name, len(ts.expectedSpans), len(intervals))
+		}
+
+		for i, span := range ts.expectedSpans {
+			have := intervals[i]
+			if math.Abs(have.enter.T-span[0]) > 1e-9 || math.Abs(have.exit.T-span[1]) > 1e-9 {
+				t.Fatalf("%s: expected [%v] but have [%v %v]", ts.name, span, have.enter.T, have.exit.T)
+			}
+
+			if !equalVectors(have.enter.Normal, ts.expectedNormals[i][0]) || !equalVectors(have.exit.Normal, ts.expectedNormals[i][1]) {
+				t.Fatalf("%s: expected normals [%v %v] but have [%v %v]", ts.name, ts.expectedNormals[i][0], ts.expectedNormals[i][1], have.enter.Normal, have.exit.Normal)
+			}
+		}
+	}
+}
+
+func TestCSGIntersectFromInside(t *testing.T) {
+	a := NewSphere(*linmath.NewVector3(0, 0, 0), 1, Material{})
+	b := NewSphere(*linmath.NewVector3(1, 0, 0), 1, Material{})
+
+	// The ray starts inside both spheres, where a spans t from -1.25 to 0.75 and b from -0.25 to 1.75.
+	ray := linmath.NewRay(linmath.NewVector3(0.25, 0, 0), linmath.NewVector3(1, 0, 0), 0)
+
+	tests := []struct {
+		name           string
+		inputCSG       *CSG
+		expectedHit    bool
+		expectedT      float64
+		expectedNormal *linmath.Vector3
+	}{
+		{"union", NewUnion(a, b), true, 1.75, linmath.NewVector3(1, 0, 0)},
+		{"intersection", NewIntersection(a, b), true, 0.75, linmath.NewVector3(1, 0, 0)},
+		{"difference", NewDifference(a, b), false, 0, nil},
+		{"reversed difference", NewDifference(b, a), true, 0.75, linmath.NewVector3(-1, 0, 0)},
+	}
+
+	for _, ts := range tests {
+		h, ok := ts.inputCSG.Intersect(ray, shadowBias, math.Inf(1))
+		if ok != ts.expectedHit {
+			t.Fatalf("%s: expected a hit [%v] but have [%v]", ts.name, ts.expectedHit, ok)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if math.Abs(h.T-ts.expectedT) > 1e-9 || !equalVectors(h.Normal, ts.expectedNormal) {
+			t.Fatalf("%s: expected [%v %v] but have [%v %v]", ts.name, ts.expectedT, ts.expectedNormal, h.T, h.Normal)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package render
+
+import (
+	"context"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"sort"
+)
+
+// cullingLeafSize is the number of objects below which the culling hierarchy stops splitting.
+const cullingLeafSize = 2
+
+// cullingNode is a node of the bounding volume hierarchy that the rasterizer tests against the view frustum,
+// so a whole group of objects outside of it is discarded with a single test.
+type cullingNode struct {
+	bounds      linmath.AABB
+	objects     []Object // set only in leaves
+	count       int      // objects in the subtree
+	left, right *cullingNode
+}
+
+// newCullingNode is a function that builds the hierarchy by splitting the objects in half along the longest axis of their centers.
+func newCullingNode(objects []Object) *cullingNode {
+	node := &cullingNode{bounds: *linmath.NewEmptyAABB(), count: len(objects)}
+	centers := linmath.NewEmptyAABB()
+
+	for _, o := range objects {
+		node.bounds = *node.bounds.Union(o.Bounds())
+		centers = centers.Extend(o.Bounds().Center())
+	}
+
+	if len(objects) <= cullingLeafSize {
+		node.objects = objects
+		return node
+	}
+
+	extent := centers.Max().Subtraction(centers.Min())
+	axis := func(v *linmath.Vector3) float64 { return v.X() }
+
+	if extent.Y() > extent.X() && extent.Y() >= extent.Z() {
+		axis = func(v *linmath.Vector3) float64 { return v.Y() }
+	} else if extent.Z() > extent.X() && extent.Z() > extent.Y() {
+		axis = func(v *linmath.Vector3) float64 { return v.Z() }
+	}
+
+	sorted := append([]Object(nil), objects...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return axis(sorted[i].Bounds().Center()) < axis(sorted[j].Bounds().Center())
+	})
+
+	node.left = newCullingNode(sorted[:len(sorted)/2])
+	node.right = newCullingNode(sorted[len(sorted)/2:])
+
+	return node
+}
+
+// cull is a method that classifies bounds in world space against the view frustum.
+// Bounds already known to be inside stay inside, so nothing below them is tested again.
+func (r *rasterizer) cull(bounds *linmath.AABB, classification linmath.Classification) linmath.Classification {
+	if classification == linmath.Inside {
+		return linmath.Inside
+	}
+
+	return r.frustum.ClassifyAABB(bounds)
+}
+
+// drawNode is a method that draws the objects of the hierarchy that may be visible.
+// It stops with the error of the context once it is canceled.
+func (r *rasterizer) drawNode(ctx context.Context, node *cullingNode, classification linmath.Classification) error {
+	classification = r.cull(&node.bounds, classification)
+	if classification == linmath.Outside {
+		r.finishObjects(node.count)
+		return nil
+	}
+
+	if node.left != nil {
+		if err := r.drawNode(ctx, node.left, classification); err != nil {
+			return err
+		}
+
+		return r.drawNode(ctx, node.right, classification)
+	}
+
+	for _, o := range node.objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.drawObject(o, linmath.NewIdentity(), nil, classification)
+		r.finishObjects(1)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+)
+
+// EncodeGIF is a function that writes the frames as a looping animated GIF playing at fps frames per second.
+// All frames share one median cut palette and are dithered into it with Floyd–Steinberg error diffusion.
+func EncodeGIF(w io.Writer, frames []image.Image, fps float64) error {
+	if len(frames) == 0 {
+		return errors.New("gif: no frames")
+	}
+
+	palette := MedianCut(frames, 256)
+
+	// GIF delays are in hundredths of a second, so frame rates above 100 cannot be represented.
+	delay := int(math.Max(math.Round(100/fps), 1))
+	animation := &gif.GIF{}
+
+	for _, frame := range frames {
+		paletted := image.NewPaletted(frame.Bounds(), palette)
+		draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, frame.Bounds().Min)
+
+		animation.Image = append(animation.Image, paletted)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	return gif.EncodeAll(w, animation)
+}
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngChunk is a chunk of a PNG stream without its length and checksum.
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+// EncodeAPNG is a function that writes the frames as a looping animated PNG playing at fps frames per second.
+// Each frame is compressed by image/png and its image data is moved into the animation chunks.
+// Paletted frames have to share one palette. Viewers without APNG support show the first frame.
+func EncodeAPNG(w io.Writer, frames []image.Image, fps float64) error {
+	if len(frames) == 0 {
+		return errors.New("apng: no frames")
+	}
+
+	var header []byte
+	var palette, chunks []pngChunk
+	sequence := uint32(0)
+
+	// Delays are a fraction of a second, kept in milliseconds.
+	delay := uint16(math.Min(math.Max(math.Round(1000/fps), 1), math.MaxUint16))
+
+	for i, frame := range frames {
+		encoded, err := encodeChunks(frame)
+		if err != nil {
+			return fmt.Errorf("apng: frame %d: %w", i+1, err)
+		}
+
+		// Every frame covers the whole image, so they all have to agree on the header written once at the start.
+		if i == 0 {
+			header = encoded[0].data
+		} else if !bytes.Equal(encoded[0].data, header) {
+			return fmt.Errorf("apng: frame %d differs in size or color type from the first", i+1)
+		}
+
+		// A paletted frame carries its palette, and transparency of the palette, ahead of the image data.
+		// The chunks of the first frame are written once after the header, so every frame has to share them.
+		var framePalette []pngChunk
+		for _, c := range encoded {
+			if c.kind == "PLTE" || c.kind == "tRNS" {
+				framePalette = append(framePalette, c)
+			}
+		}
+
+		if i == 0 {
+			palette = framePalette
+		} else if !equalChunks(framePalette, palette) {
+			return fmt.Errorf("apng: frame %d differs in palette from the first", i+1)
+		}
+
+		// The offsets, dispose and blend operations stay zero: frames replace each other at the origin.
+		control := make([]byte, 26)
+		binary.BigEndian.PutUint32(control[0:], sequence)
+		binary.BigEndian.PutUint32(control[4:], uint32(frame.Bounds().Dx()))
+		binary.BigEndian.PutUint32(control[8:], uint32(frame.Bounds().Dy()))
+		binary.BigEndian.PutUint16(control[20:], delay)
+		binary.BigEndian.PutUint16(control[22:], 1000)
+		chunks = append(chunks, pngChunk{"fcTL", control})
+		sequence++
+
+		for _, c := range encoded {
+			if c.kind != "IDAT" {
+				continue
+			}
+
+			if i == 0 {
+				chunks = append(chunks, c)
+				continue
+			}
+
+			data := make([]byte, 4+len(c.data))
+			binary.BigEndian.PutUint32(data, sequence)
+			copy(data[4:], c.data)
+			chunks = append(chunks, pngChunk{"fdAT", data})
+			sequence++
+		}
+	}
+
+	// The frame count is followed by a play count of zero, which loops forever.
+	animation := make([]byte, 8)
+	binary.BigEndian.PutUint32(animation, uint32(len(frames)))
+
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+
+	chunks = append(append([]pngChunk{{"IHDR", header}, {"acTL", animation}}, palette...), chunks...)
+	chunks = append(chunks, pngChunk{"IEND", nil})
+
+	for _, c := range chunks {
+		if err := writeChunk(w, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeChunks is a function that compresses the image with image/png and splits the result into chunks.
+func encodeChunks(img image.Image) ([]pngChunk, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+
+	data := buffer.Bytes()[len(pngSignature):]
+	var chunks []pngChunk
+
+	for len(data) >= 12 {
+		length := binary.BigEndian.Uint32(data)
+		chunks = append(chunks, pngChunk{string(data[4:8]), data[8 : 8+length]})
+		data = data[12+length:]
+	}
+
+	return chunks, nil
+}
+
+// equalChunks is a function that reports whether both lists hold the same chunks in the same order.
+func equalChunks(a, b []pngChunk) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].kind != b[i].kind || !bytes.Equal(a[i].data, b[i].data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeChunk(w io.Writer, c pngChunk) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(c.data)))
+	copy(header[4:], c.kind)
+
+	checksum := crc32.NewIEEE()
+	checksum.Write(header[4:])
+	checksum.Write(c.data)
+
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, checksum.Sum32())
+
+	for _, b := range [][]byte{header, c.data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
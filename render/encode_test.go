@@ -0,0 +1,134 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func testFrames() []image.Image {
+	var frames []image.Image
+
+	for i := 0; i < 3; i++ {
+		frame := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				frame.SetNRGBA(x, y, color.NRGBA{uint8(x * 32), uint8(y * 32), uint8(i * 100), 255})
+			}
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func TestEncodeGIF(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := EncodeGIF(&buffer, testFrames(), 25); err != nil {
+		t.Fatal(err)
+	}
+
+	animation, err := gif.DecodeAll(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(animation.Image) != 3 {
+		t.Fatalf("expected [3] frames but have [%v]", len(animation.Image))
+	}
+
+	for _, delay := range animation.Delay {
+		if delay != 4 {
+			t.Fatalf("expected delay [4] but have [%v]", delay)
+		}
+	}
+}
+
+func TestEncodeAPNG(t *testing.T) {
+	frames := testFrames()
+
+	var buffer bytes.Buffer
+	if err := EncodeAPNG(&buffer, frames, 25); err != nil {
+		t.Fatal(err)
+	}
+
+	// A decoder without APNG support still reads the first frame.
+	first, err := png.Decode(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := color.NRGBAModel.Convert(first.At(3, 5)); c != frames[0].At(3, 5) {
+		t.Fatalf("expected [%v] but have [%v]", frames[0].At(3, 5), c)
+	}
+
+	counts := map[string]int{}
+	var sequence []uint32
+
+	data := buffer.Bytes()[len(pngSignature):]
+	for len(data) >= 12 {
+		length := binary.BigEndian.Uint32(data)
+		kind, chunk := string(data[4:8]), data[8:8+length]
+		counts[kind]++
+
+		switch kind {
+		case "acTL":
+			if frames := binary.BigEndian.Uint32(chunk); frames != 3 {
+				t.Fatalf("expected [3] frames but have [%v]", frames)
+			}
+		case "fcTL", "fdAT":
+			sequence = append(sequence, binary.BigEndian.Uint32(chunk))
+		}
+
+		data = data[12+length:]
+	}
+
+	if counts["acTL"] != 1 || counts["fcTL"] != 3 || counts["fdAT"] < 2 || counts["IEND"] != 1 {
+		t.Fatalf("unexpected chunks [%v]", counts)
+	}
+
+	for i, s := range sequence {
+		if s != uint32(i) {
+			t.Fatalf("expected sequence numbers counting from 0 but have [%v]", sequence)
+		}
+	}
+}
+
+func TestEncodeAPNGPaletted(t *testing.T) {
+	frames := testFrames()
+	palette := MedianCut(frames, 16)
+
+	var paletted []image.Image
+	for _, frame := range frames {
+		p := image.NewPaletted(frame.Bounds(), palette)
+		draw.Draw(p, p.Bounds(), frame, image.Point{}, draw.Src)
+		paletted = append(paletted, p)
+	}
+
+	var buffer bytes.Buffer
+	if err := EncodeAPNG(&buffer, paletted, 25); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := png.Decode(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if have, expected := color.NRGBAModel.Convert(first.At(3, 5)), color.NRGBAModel.Convert(paletted[0].At(3, 5)); have != expected {
+		t.Fatalf("expected [%v] but have [%v]", expected, have)
+	}
+
+	// A frame with a palette of its own cannot share the palette written for the first frame.
+	changed := append(color.Palette{color.NRGBA{1, 2, 3, 255}}, palette[1:]...)
+	other := image.NewPaletted(frames[1].Bounds(), changed)
+	if err := EncodeAPNG(&buffer, []image.Image{paletted[0], other}, 25); err == nil {
+		t.Fatalf("expected an error for frames with different palettes")
+	}
+}
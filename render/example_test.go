@@ -0,0 +1,67 @@
+package render_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"github.com/UnTea/ComputerGraphics/render"
+)
+
+func ExampleRender() {
+	scene := &render.Scene{
+		Objects: []render.Object{
+			render.NewSphere(*linmath.NewVector3(0., 0., 3.), 1., *render.NewMaterial(*render.NewColor(255, 0, 0, 255), 500., 0., nil)),
+		},
+		Lights: []render.Light{
+			*render.NewAmbientLight(0.2),
+			*render.NewPointLight(0.8, linmath.NewVector3(2., 1., 0.)),
+		},
+		Camera: render.NewCamera(linmath.NewVector3(0., 0., 0.), linmath.NewIdentity(), 0., 1., 0, 0., 0.),
+	}
+
+	img, err := render.Render(scene, &render.Options{Width: 32, Height: 24})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(img.Bounds())
+	fmt.Println(img.At(0, 0))
+	// Output:
+	// (0,0)-(32,24)
+	// {255 255 255 255}
+}
+
+func ExampleRenderContext() {
+	scene := &render.Scene{
+		Objects: []render.Object{
+			render.NewSphere(*linmath.NewVector3(0., 0., 3.), 1., *render.NewMaterial(*render.NewColor(0, 0, 255, 255), -1., 0., nil)),
+		},
+		Lights: []render.Light{*render.NewDirectionalLight(1., linmath.NewVector3(0., 0., -1.))},
+		Camera: render.NewCamera(linmath.NewVector3(0., 0., 0.), linmath.NewIdentity(), 0., 1., 0, 0., 0.),
+	}
+
+	// A canceled context stops the render before it traces a single ray.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, stats, err := render.RenderContext(ctx, scene, &render.Options{Width: 16, Height: 16})
+	fmt.Println(err, stats.Rays())
+	// Output:
+	// context canceled 0
+}
+
+func ExampleNewInstance() {
+	// One geometry is shared by every instance; each instance only adds a transform and optionally a material.
+	unitSphere := render.NewSphere(*linmath.NewVector3(0., 0., 0.), 1., *render.NewMaterial(*render.NewColor(255, 255, 0, 255), 100., 0., nil))
+
+	var objects []render.Object
+	for i := 0; i < 3; i++ {
+		transform := linmath.NewTranslation(float64(i)-1., 0., 4.).Multiply(linmath.NewScale(0.4, 0.4, 0.4))
+		objects = append(objects, render.NewInstance(unitSphere, transform, nil))
+	}
+
+	fmt.Println(objects[2].Bounds().Min(), objects[2].Bounds().Max())
+	// Output:
+	// &{0.6 -0.4 3.6} &{1.4 0.4 4.4}
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image"
+	"io"
+	"math"
+	"sort"
+)
+
+// exrMagic opens every OpenEXR file.
+var exrMagic = []byte{0x76, 0x2f, 0x31, 0x01}
+
+// Pixel types of OpenEXR channels.
+const (
+	exrUint  = 0
+	exrFloat = 2
+)
+
+// exrChannel is a channel of an OpenEXR file and the value it holds for every pixel.
+type exrChannel struct {
+	name      string
+	pixelType int32
+	value     func(i int) float64
+}
+
+// EncodeEXR is a function that writes the image and its AOVs as the layers of an uncompressed scanline OpenEXR file
+// of 32-bit channels, the beauty image as R, G, B and A from 0 to 1 and the AOVs as:
+//
+//	depth.Z, distance.Z              the Depth and the Distance
+//	normal.XYZ, cameraNormal.XYZ     the Normal and the CameraNormal
+//	albedo.RGB                       the Albedo, from 0 to 1
+//	object.id, object.coverage       the ObjectID, an unsigned integer, and the ObjectCoverage
+//	material.id, material.coverage   the MaterialID, an unsigned integer, and the MaterialCoverage
+//	uv.U, uv.V                       the UV
+//	hits.Y                           the Hits
+//	motion.X, motion.Y               the Motion
+func EncodeEXR(w io.Writer, img image.Image, aovs *AOVs) error {
+	bounds := img.Bounds()
+	if bounds.Dx() != aovs.Width || bounds.Dy() != aovs.Height {
+		return errors.New("exr: image and AOVs differ in size")
+	}
+
+	if aovs.Width <= 0 || aovs.Height <= 0 {
+		return errors.New("exr: empty image")
+	}
+
+	channels := exrChannels(img, aovs)
+
+	var header bytes.Buffer
+	header.Write(exrMagic)
+	_ = binary.Write(&header, binary.LittleEndian, int32(2)) // version 2, a single part of scanlines with short names
+
+	var list bytes.Buffer
+	for _, c := range channels {
+		list.WriteString(c.name)
+		list.WriteByte(0)
+		// The pixel type, whether it is perceptually linear, three reserved bytes and the sampling along x and y.
+		_ = binary.Write(&list, binary.LittleEndian, c.pixelType)
+		list.Write([]byte{0, 0, 0, 0})
+		_ = binary.Write(&list, binary.LittleEndian, [2]int32{1, 1})
+	}
+	list.WriteByte(0)
+
+	window := [4]int32{0, 0, int32(aovs.Width - 1), int32(aovs.Height - 1)}
+
+	writeAttribute(&header, "channels", "chlist", list.Bytes())
+	writeAttribute(&header, "compression", "compression", []byte{0})
+	writeAttribute(&header, "dataWindow", "box2i", window)
+	writeAttribute(&header, "displayWindow", "box2i", window)
+	writeAttribute(&header, "lineOrder", "lineOrder", []byte{0})
+	writeAttribute(&header, "pixelAspectRatio", "float", float32(1))
+	writeAttribute(&header, "screenWindowCenter", "v2f", [2]float32{0, 0})
+	writeAttribute(&header, "screenWindowWidth", "float", float32(1))
+	header.WriteByte(0)
+
+	// Every scanline is a block of its own, found through a table of offsets from the start of the file.
+	// A block starts with its y and the size of its pixels, which list the scanline of every channel in turn.
+	size := 4 * aovs.Width * len(channels)
+	offsets := make([]uint64, aovs.Height)
+	for y := range offsets {
+		offsets[y] = uint64(header.Len() + 8*aovs.Height + y*(8+size))
+	}
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, offsets); err != nil {
+		return err
+	}
+
+	block := make([]byte, 8+size)
+	for y := 0; y < aovs.Height; y++ {
+		binary.LittleEndian.PutUint32(block, uint32(y))
+		binary.LittleEndian.PutUint32(block[4:], uint32(size))
+
+		pixels := block[8:]
+		for _, c := range channels {
+			for x := 0; x < aovs.Width; x++ {
+				v := c.value(x + y*aovs.Width)
+				if c.pixelType == exrUint {
+					binary.LittleEndian.PutUint32(pixels, uint32(v))
+				} else {
+					binary.LittleEndian.PutUint32(pixels, math.Float32bits(float32(v)))
+				}
+
+				pixels = pixels[4:]
+			}
+		}
+
+		if _, err := w.Write(block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exrChannels is a function that returns the channels of the image and its AOVs, sorted by name as OpenEXR requires.
+func exrChannels(img image.Image, aovs *AOVs) []exrChannel {
+	bounds := img.Bounds()
+	beauty := func(channel int) func(i int) float64 {
+		return func(i int) float64 {
+			r, g, b, a := img.At(bounds.Min.X+i%aovs.Width, bounds.Min.Y+i/aovs.Width).RGBA()
+			return float64([4]uint32{r, g, b, a}[channel]) / 0xffff
+		}
+	}
+
+	vector := func(vectors []linmath.Vector3, axis int) func(i int) float64 {
+		return func(i int) float64 {
+			return [3]float64{vectors[i].X(), vectors[i].Y(), vectors[i].Z()}[axis]
+		}
+	}
+
+	pair := func(pairs [][2]float64, index int) func(i int) float64 {
+		return func(i int) float64 {
+			return pairs[i][index]
+		}
+	}
+
+	albedo := func(channel int) func(i int) float64 {
+		return func(i int) float64 {
+			c := aovs.Albedo[i]
+			return float64([3]uint8{c.r, c.g, c.b}[channel]) / 255
+		}
+	}
+
+	values := func(values []float64) func(i int) float64 {
+		return func(i int) float64 {
+			return values[i]
+		}
+	}
+
+	ids := func(ids []int) func(i int) float64 {
+		return func(i int) float64 {
+			return float64(ids[i])
+		}
+	}
+
+	channels := []exrChannel{
+		{"R", exrFloat, beauty(0)},
+		{"G", exrFloat, beauty(1)},
+		{"B", exrFloat, beauty(2)},
+		{"A", exrFloat, beauty(3)},
+		{"depth.Z", exrFloat, values(aovs.Depth)},
+		{"distance.Z", exrFloat, values(aovs.Distance)},
+		{"normal.X", exrFloat, vector(aovs.Normal, 0)},
+		{"normal.Y", exrFloat, vector(aovs.Normal, 1)},
+		{"normal.Z", exrFloat, vector(aovs.Normal, 2)},
+		{"cameraNormal.X", exrFloat, vector(aovs.CameraNormal, 0)},
+		{"cameraNormal.Y", exrFloat, vector(aovs.CameraNormal, 1)},
+		{"cameraNormal.Z", exrFloat, vector(aovs.CameraNormal, 2)},
+		{"albedo.R", exrFloat, albedo(0)},
+		{"albedo.G", exrFloat, albedo(1)},
+		{"albedo.B", exrFloat, albedo(2)},
+		{"object.id", exrUint, ids(aovs.ObjectID)},
+		{"object.coverage", exrFloat, values(aovs.ObjectCoverage)},
+		{"material.id", exrUint, ids(aovs.MaterialID)},
+		{"material.coverage", exrFloat, values(aovs.MaterialCoverage)},
+		{"uv.U", exrFloat, pair(aovs.UV, 0)},
+		{"uv.V", exrFloat, pair(aovs.UV, 1)},
+		{"hits.Y", exrFloat, values(aovs.Hits)},
+		{"motion.X", exrFloat, pair(aovs.Motion, 0)},
+		{"motion.Y", exrFloat, pair(aovs.Motion, 1)},
+	}
+
+	sort.Slice(channels, func(i, j int) bool { return channels[i].name < channels[j].name })
+
+	return channels
+}
+
+// writeAttribute is a function that writes an attribute of an OpenEXR header: its name, its type, the size of its value and the value.
+func writeAttribute(header *bytes.Buffer, name, kind string, value interface{}) {
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.LittleEndian, value)
+
+	header.WriteString(name)
+	header.WriteByte(0)
+	header.WriteString(kind)
+	header.WriteByte(0)
+	_ = binary.Write(header, binary.LittleEndian, int32(data.Len()))
+	header.Write(data.Bytes())
+}
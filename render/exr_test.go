@@ -0,0 +1,125 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestEncodeEXR(t *testing.T) {
+	img, aovs, _, err := RenderAOVs(context.Background(), aovScene(), &Options{Width: 64, Height: 48})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err := EncodeEXR(&buffer, img, aovs); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.Bytes()
+	if !bytes.Equal(data[:4], exrMagic) || binary.LittleEndian.Uint32(data[4:]) != 2 {
+		t.Fatalf("expected the magic number and version 2 but have [%v]", data[:8])
+	}
+
+	// The header is a list of attributes ending with an empty name.
+	readString := func(at int) (string, int) {
+		end := at + bytes.IndexByte(data[at:], 0)
+		return string(data[at:end]), end + 1
+	}
+
+	attributes := make(map[string][]byte)
+	at := 8
+	for {
+		name, next := readString(at)
+		if name == "" {
+			at = next
+			break
+		}
+
+		_, next = readString(next)
+		size := int(binary.LittleEndian.Uint32(data[next:]))
+		attributes[name] = data[next+4 : next+4+size]
+		at = next + 4 + size
+	}
+
+	for _, name := range []string{"channels", "compression", "dataWindow", "displayWindow", "lineOrder", "pixelAspectRatio", "screenWindowCenter", "screenWindowWidth"} {
+		if _, ok := attributes[name]; !ok {
+			t.Fatalf("expected the attribute [%v]", name)
+		}
+	}
+
+	var channels []string
+	var types []uint32
+	for list := attributes["channels"]; list[0] != 0; {
+		end := bytes.IndexByte(list, 0)
+		channels = append(channels, string(list[:end]))
+		types = append(types, binary.LittleEndian.Uint32(list[end+1:]))
+		list = list[end+17:]
+	}
+
+	for i := 1; i < len(channels); i++ {
+		if channels[i-1] >= channels[i] {
+			t.Fatalf("expected sorted channels but have [%v]", channels)
+		}
+	}
+
+	// The pixel of a channel in a scanline follows the scanlines of the channels before it.
+	value := func(channel string, x, y int) float64 {
+		offset := int(binary.LittleEndian.Uint64(data[at+8*y:]))
+		if line := int(binary.LittleEndian.Uint32(data[offset:])); line != y {
+			t.Fatalf("expected the scanline [%v] but have [%v]", y, line)
+		}
+
+		for c := range channels {
+			if channels[c] != channel {
+				continue
+			}
+
+			bits := binary.LittleEndian.Uint32(data[offset+8+4*(c*aovs.Width+x):])
+			if types[c] == exrUint {
+				return float64(bits)
+			}
+
+			return float64(math.Float32frombits(bits))
+		}
+
+		t.Fatalf("expected the channel [%v] in [%v]", channel, channels)
+
+		return 0
+	}
+
+	tests := []struct {
+		name     string
+		inputX   int
+		inputY   int
+		expected float64
+	}{
+		{"A", 0, 0, 1},
+		{"R", 0, 0, 1},
+		{"depth.Z", 32, 23, 4},
+		{"depth.Z", 0, 0, math.Inf(1)},
+		{"normal.Z", 32, 23, -1},
+		{"object.id", 14, 23, 2},
+		{"material.id", 50, 17, 1},
+		{"hits.Y", 32, 23, 1},
+		{"motion.X", 32, 23, 12},
+		{"albedo.G", 50, 17, 0},
+	}
+
+	for _, ts := range tests {
+		if have := value(ts.name, ts.inputX, ts.inputY); math.Abs(have-ts.expected) > 1e-6 && have != ts.expected {
+			t.Fatalf("%s at [%v %v]: expected [%v] but have [%v]", ts.name, ts.inputX, ts.inputY, ts.expected, have)
+		}
+	}
+
+	if last := int(binary.LittleEndian.Uint64(data[at+8*(aovs.Height-1):])) + 8 + 4*aovs.Width*len(channels); last != len(data) {
+		t.Fatalf("expected the file to end after the last scanline at [%v] but have [%v]", last, len(data))
+	}
+
+	if err := EncodeEXR(&buffer, NewCanvas(2, 2, false), aovs); err == nil {
+		t.Fatalf("expected an error for AOVs of another size")
+	}
+}
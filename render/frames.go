@@ -0,0 +1,86 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// frameName is a function that returns the path of a frame; frames are numbered from 1.
+func frameName(directory string, frame int) string {
+	return filepath.Join(directory, fmt.Sprintf("frame_%04d.png", frame))
+}
+
+// RenderSequence is a function that renders numbered frames into PNG files in the directory.
+// Each frame is written to a temporary file and renamed once it is complete, so every frame file
+// on disk is whole and an interrupted sequence resumes after the frames that were already written.
+// It stops at the first frame that fails to render.
+func RenderSequence(directory string, frames int, render func(frame int) (image.Image, error)) error {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+
+	for frame := 1; frame <= frames; frame++ {
+		name := frameName(directory, frame)
+
+		if _, err := os.Stat(name); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		img, err := render(frame)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+
+		temporary := name + ".tmp"
+		if err := WritePNG(temporary, img); err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+
+		if err := os.Rename(temporary, name); err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadSequence is a function that reads back the numbered frames written by RenderSequence.
+func LoadSequence(directory string, frames int) ([]image.Image, error) {
+	images := make([]image.Image, frames)
+
+	for i := range images {
+		file, err := os.Open(frameName(directory, i+1))
+		if err != nil {
+			return nil, err
+		}
+
+		images[i], err = png.Decode(file)
+		_ = file.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i+1, err)
+		}
+	}
+
+	return images, nil
+}
+
+// WritePNG is a function that encodes the image into a new PNG file.
+func WritePNG(name string, img image.Image) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err = png.Encode(file, img); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
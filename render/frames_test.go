@@ -0,0 +1,45 @@
+package render
+
+import (
+	"image"
+	"os"
+	"testing"
+)
+
+func TestRenderSequenceResumes(t *testing.T) {
+	directory := t.TempDir()
+
+	var rendered []int
+	render := func(frame int) (image.Image, error) {
+		rendered = append(rendered, frame)
+		return image.NewNRGBA(image.Rect(0, 0, 2, 2)), nil
+	}
+
+	if err := RenderSequence(directory, 2, render); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unfinished frame left by an interruption is rendered again.
+	if err := os.WriteFile(frameName(directory, 3)+".tmp", []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RenderSequence(directory, 4, render); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{1, 2, 3, 4}
+	if len(rendered) != len(expected) {
+		t.Fatalf("expected frames [%v] but have [%v]", expected, rendered)
+	}
+
+	for i := range expected {
+		if rendered[i] != expected[i] {
+			t.Fatalf("expected frames [%v] but have [%v]", expected, rendered)
+		}
+
+		if _, err := os.Stat(frameName(directory, expected[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,1048 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image"
+	_ "image/jpeg" // glTF images are PNG or JPEG
+	_ "image/png"
+	"io/fs"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The subset of glTF 2.0 the importer reads. Indices that may be absent are pointers.
+type (
+	gltfDocument struct {
+		Asset struct {
+			Version string `json:"version"`
+		} `json:"asset"`
+		Scene  *int `json:"scene"`
+		Scenes []struct {
+			Nodes []int `json:"nodes"`
+		} `json:"scenes"`
+		Nodes       []gltfNode       `json:"nodes"`
+		Meshes      []gltfMesh       `json:"meshes"`
+		Accessors   []gltfAccessor   `json:"accessors"`
+		BufferViews []gltfBufferView `json:"bufferViews"`
+		Buffers     []gltfBuffer     `json:"buffers"`
+		Materials   []gltfMaterial   `json:"materials"`
+		Textures    []struct {
+			Source *int `json:"source"`
+		} `json:"textures"`
+		Images     []gltfImage  `json:"images"`
+		Cameras    []gltfCamera `json:"cameras"`
+		Extensions struct {
+			LightsPunctual struct {
+				Lights []gltfLight `json:"lights"`
+			} `json:"KHR_lights_punctual"`
+		} `json:"extensions"`
+		ExtensionsRequired []string `json:"extensionsRequired"`
+	}
+
+	gltfNode struct {
+		Children    []int     `json:"children"`
+		Matrix      []float64 `json:"matrix"` // column-major
+		Translation []float64 `json:"translation"`
+		Rotation    []float64 `json:"rotation"` // unit quaternion x, y, z, w
+		Scale       []float64 `json:"scale"`
+		Mesh        *int      `json:"mesh"`
+		Camera      *int      `json:"camera"`
+		Extensions  struct {
+			LightsPunctual *struct {
+				Light int `json:"light"`
+			} `json:"KHR_lights_punctual"`
+		} `json:"extensions"`
+	}
+
+	gltfMesh struct {
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	}
+
+	gltfAccessor struct {
+		BufferView    *int   `json:"bufferView"`
+		ByteOffset    int    `json:"byteOffset"`
+		ComponentType int    `json:"componentType"`
+		Normalized    bool   `json:"normalized"`
+		Count         int    `json:"count"`
+		Type          string `json:"type"`
+		Sparse        *struct {
+			Count   int `json:"count"`
+			Indices struct {
+				BufferView    int `json:"bufferView"`
+				ByteOffset    int `json:"byteOffset"`
+				ComponentType int `json:"componentType"`
+			} `json:"indices"`
+			Values struct {
+				BufferView int `json:"bufferView"`
+				ByteOffset int `json:"byteOffset"`
+			} `json:"values"`
+		} `json:"sparse"`
+	}
+
+	gltfBufferView struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	}
+
+	gltfBuffer struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	}
+
+	gltfTextureInfo struct {
+		Index int `json:"index"`
+	}
+
+	gltfMaterial struct {
+		PBRMetallicRoughness struct {
+			BaseColorFactor          []float64        `json:"baseColorFactor"`
+			BaseColorTexture         *gltfTextureInfo `json:"baseColorTexture"`
+			MetallicFactor           *float64         `json:"metallicFactor"`
+			RoughnessFactor          *float64         `json:"roughnessFactor"`
+			MetallicRoughnessTexture *gltfTextureInfo `json:"metallicRoughnessTexture"`
+		} `json:"pbrMetallicRoughness"`
+		NormalTexture *struct {
+			gltfTextureInfo
+			Scale *float64 `json:"scale"`
+		} `json:"normalTexture"`
+	}
+
+	gltfImage struct {
+		URI        string `json:"uri"`
+		BufferView *int   `json:"bufferView"`
+	}
+
+	gltfCamera struct {
+		Type        string `json:"type"`
+		Perspective *struct {
+			YFov float64 `json:"yfov"`
+		} `json:"perspective"`
+	}
+
+	gltfLight struct {
+		Type      string    `json:"type"`
+		Color     []float64 `json:"color"`
+		Intensity *float64  `json:"intensity"`
+	}
+)
+
+// Constants of the glTF and GLB specifications.
+const (
+	glbMagic     = 0x46546c67 // "glTF"
+	glbJSONChunk = 0x4e4f534a // "JSON"
+	glbBINChunk  = 0x004e4942 // "BIN\0"
+
+	gltfByte          = 5120
+	gltfUnsignedByte  = 5121
+	gltfShort         = 5122
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfFloat         = 5126
+
+	gltfTriangles     = 4
+	gltfTriangleStrip = 5
+	gltfTriangleFan   = 6
+)
+
+// gltfMaxZeros is the most elements an accessor without a buffer view may have, so that a broken count
+// fails to load instead of taking all the memory.
+const gltfMaxZeros = 1 << 24
+
+// gltfAmbient is the ambient light added to imported scenes; the punctual lights share the rest of the light.
+const gltfAmbient = 0.2
+
+// gltfComponents are the number of components of every accessor type.
+var gltfComponents = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16}
+
+// gltfExtensions are the required extensions the importer understands.
+var gltfExtensions = map[string]bool{"KHR_lights_punctual": true}
+
+// gltfDecoder turns a parsed document into a scene, loading every buffer, image, material and mesh once.
+type gltfDecoder struct {
+	fsys      fs.FS
+	directory string // of the document in fsys, relative URIs start there
+	document  gltfDocument
+	binary    []byte // BIN chunk of a .glb, the buffer without a URI
+
+	buffers   map[int][]byte
+	images    map[int]image.Image
+	materials map[int]*Material
+	meshes    map[int][]*Mesh
+	path      map[int]bool // nodes between the root and the node being added, to catch nodes that are their own ancestors
+
+	scene    *Scene
+	lights   []gltfPlacedLight
+	warnings []string // parts of the file that are approximated or left out
+}
+
+// gltfPlacedLight is a punctual light at the place of its node, before the intensities are balanced.
+type gltfPlacedLight struct {
+	light     Light
+	intensity float64
+}
+
+// LoadGLTF is a function that imports the default scene of a .gltf or .glb file. Buffers and images
+// are read from data URIs, the binary chunk of a .glb, or files relative to the file.
+func LoadGLTF(name string) (*Scene, []string, error) {
+	return DecodeGLTF(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+}
+
+// DecodeGLTF is a function that imports the default scene of a glTF 2.0 file, JSON or binary, from a file system.
+//
+// glTF is right-handed with the camera looking down -Z, and the renderer is left-handed with the camera looking down +Z,
+// so every position and direction is mirrored in Z and the triangles are wound the other way.
+// Nodes with meshes become instances sharing one mesh per primitive; metallic-roughness materials are approximated
+// with the Phong model, and every texture is looked up with TEXCOORD_0. The first camera of the scene is used,
+// or, without one, a camera looking at the whole scene. Lights have no color or falloff in the renderer, so the
+// KHR_lights_punctual lights keep only the brightness of their color times their intensity, scaled so that together
+// with an ambient light they add up to one; spot lights shine in every direction. Skins, morph targets,
+// animations and emission are ignored.
+//
+// Besides the scene it returns a warning for every part of the file it approximates or leaves out,
+// such as a primitive of points or lines, for the caller to show.
+func DecodeGLTF(fsys fs.FS, name string) (*Scene, []string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := &gltfDecoder{
+		fsys:      fsys,
+		directory: path.Dir(name),
+		buffers:   map[int][]byte{},
+		images:    map[int]image.Image{},
+		materials: map[int]*Material{},
+		meshes:    map[int][]*Mesh{},
+		path:      map[int]bool{},
+		scene:     &Scene{},
+	}
+
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic {
+		if data, d.binary, err = splitGLB(data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = json.Unmarshal(data, &d.document); err != nil {
+		return nil, nil, fmt.Errorf("gltf: %w", err)
+	}
+
+	if !strings.HasPrefix(d.document.Asset.Version, "2.") {
+		return nil, nil, fmt.Errorf("gltf: unsupported version %q", d.document.Asset.Version)
+	}
+
+	for _, extension := range d.document.ExtensionsRequired {
+		if !gltfExtensions[extension] {
+			return nil, nil, fmt.Errorf("gltf: unsupported required extension %s", extension)
+		}
+	}
+
+	for _, node := range d.rootNodes() {
+		if err = d.addNode(node, linmath.NewIdentity()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	d.finishCamera()
+	d.finishLights()
+
+	return d.scene, d.warnings, nil
+}
+
+// splitGLB is a function that returns the JSON and binary chunks of a .glb file.
+func splitGLB(data []byte) (document, bin []byte, err error) {
+	if len(data) < 12 || binary.LittleEndian.Uint32(data[4:]) != 2 {
+		return nil, nil, errors.New("gltf: not a version 2 binary glTF file")
+	}
+
+	if length := int(binary.LittleEndian.Uint32(data[8:])); length <= len(data) {
+		data = data[:length]
+	}
+
+	for offset := 12; offset+8 <= len(data); {
+		length, kind := int(binary.LittleEndian.Uint32(data[offset:])), binary.LittleEndian.Uint32(data[offset+4:])
+		if offset+8+length > len(data) {
+			return nil, nil, errors.New("gltf: truncated binary chunk")
+		}
+
+		chunk := data[offset+8 : offset+8+length]
+
+		switch {
+		case kind == glbJSONChunk && document == nil:
+			document = chunk
+		case kind == glbBINChunk && bin == nil:
+			bin = chunk
+		}
+
+		offset += 8 + length
+	}
+
+	if document == nil {
+		return nil, nil, errors.New("gltf: binary glTF file without a JSON chunk")
+	}
+
+	return document, bin, nil
+}
+
+// rootNodes is a method that returns the nodes of the default scene, or every node without a parent when there is no scene.
+func (d *gltfDecoder) rootNodes() []int {
+	scenes := d.document.Scenes
+	if len(scenes) > 0 {
+		scene := 0
+		if d.document.Scene != nil && *d.document.Scene < len(scenes) {
+			scene = *d.document.Scene
+		}
+
+		return scenes[scene].Nodes
+	}
+
+	child := make([]bool, len(d.document.Nodes))
+	for _, node := range d.document.Nodes {
+		for _, c := range node.Children {
+			if c >= 0 && c < len(child) {
+				child[c] = true
+			}
+		}
+	}
+
+	var roots []int
+	for i := range child {
+		if !child[i] {
+			roots = append(roots, i)
+		}
+	}
+
+	return roots
+}
+
+// addNode is a method that adds the mesh, camera and light of a node and its descendants to the scene.
+// The parent transform is in glTF space.
+func (d *gltfDecoder) addNode(index int, parent *linmath.Matrix4) error {
+	if index < 0 || index >= len(d.document.Nodes) {
+		return fmt.Errorf("gltf: node %d does not exist", index)
+	}
+
+	if d.path[index] {
+		return fmt.Errorf("gltf: node %d is its own ancestor", index)
+	}
+
+	d.path[index] = true
+	defer delete(d.path, index)
+
+	node := &d.document.Nodes[index]
+	world := parent.Multiply(node.transform())
+	transform := mirrorZ(world)
+
+	if node.Mesh != nil {
+		meshes, err := d.mesh(*node.Mesh)
+		if err != nil {
+			return err
+		}
+
+		// A node scaled to nothing, as animations do to hide it, would never be hit, so it is left out of the scene.
+		if _, ok := transform.Inverse(); ok {
+			for _, m := range meshes {
+				d.scene.Objects = append(d.scene.Objects, NewInstance(m, transform, nil))
+			}
+		}
+	}
+
+	if node.Camera != nil && d.scene.Camera == nil {
+		if *node.Camera < 0 || *node.Camera >= len(d.document.Cameras) {
+			return fmt.Errorf("gltf: camera %d does not exist", *node.Camera)
+		}
+
+		d.scene.Camera = d.camera(&d.document.Cameras[*node.Camera], transform)
+	}
+
+	if l := node.Extensions.LightsPunctual; l != nil {
+		lights := d.document.Extensions.LightsPunctual.Lights
+		if l.Light < 0 || l.Light >= len(lights) {
+			return fmt.Errorf("gltf: light %d does not exist", l.Light)
+		}
+
+		d.lights = append(d.lights, placeLight(&lights[l.Light], transform))
+	}
+
+	for _, child := range node.Children {
+		if err := d.addNode(child, world); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transform is a method that returns the local transform of a node, from its matrix or from T * R * S.
+func (n *gltfNode) transform() *linmath.Matrix4 {
+	if len(n.Matrix) == 16 {
+		var rows [4][4]float64
+		for column := 0; column < 4; column++ {
+			for row := 0; row < 4; row++ {
+				rows[row][column] = n.Matrix[column*4+row]
+			}
+		}
+
+		return linmath.NewMatrix4(rows)
+	}
+
+	transform := linmath.NewIdentity()
+
+	if len(n.Translation) == 3 {
+		transform = linmath.NewTranslation(n.Translation[0], n.Translation[1], n.Translation[2])
+	}
+
+	if len(n.Rotation) == 4 {
+		transform = transform.Multiply(linmath.NewQuaternionRotation(n.Rotation[0], n.Rotation[1], n.Rotation[2], n.Rotation[3]))
+	}
+
+	if len(n.Scale) == 3 {
+		transform = transform.Multiply(linmath.NewScale(n.Scale[0], n.Scale[1], n.Scale[2]))
+	}
+
+	return transform
+}
+
+// mirrorZ is a function that converts a transform between glTF space and the space of the renderer,
+// mirroring its input and its output in Z. The result is a rotation again whenever the transform is one.
+func mirrorZ(m *linmath.Matrix4) *linmath.Matrix4 {
+	sign := [4]float64{1, 1, -1, 1}
+
+	var rows [4][4]float64
+	for row := range rows {
+		for column := range rows[row] {
+			rows[row][column] = sign[row] * sign[column] * m.At(row, column)
+		}
+	}
+
+	return linmath.NewMatrix4(rows)
+}
+
+// camera is a method that returns a camera placed by the transform of its node in the space of the renderer,
+// where it looks down +Z of the node.
+func (d *gltfDecoder) camera(c *gltfCamera, transform *linmath.Matrix4) *Camera {
+	position := transform.MultiplyPoint(linmath.NewVector3(0, 0, 0))
+
+	// The node may be scaled, but the camera only takes its rotation.
+	x := transform.MultiplyDirection(linmath.NewVector3(1, 0, 0)).Normal()
+	y := transform.MultiplyDirection(linmath.NewVector3(0, 1, 0)).Normal()
+	z := transform.MultiplyDirection(linmath.NewVector3(0, 0, 1)).Normal()
+	rotation := linmath.NewMatrix4([4][4]float64{
+		{x.X(), y.X(), z.X(), 0},
+		{x.Y(), y.Y(), z.Y(), 0},
+		{x.Z(), y.Z(), z.Z(), 0},
+		{0, 0, 0, 1},
+	})
+
+	camera := NewCamera(position, rotation, 0, 1, 0, 0, 0)
+
+	if c.Type == "perspective" && c.Perspective != nil {
+		camera.fieldOfView = c.Perspective.YFov
+	} else {
+		d.warnings = append(d.warnings, fmt.Sprintf("gltf: %s cameras are not supported, using a perspective camera", c.Type))
+	}
+
+	return camera
+}
+
+// finishCamera is a method that gives a scene without a camera one that sees all of it from the front, which in glTF is +Z.
+func (d *gltfDecoder) finishCamera() {
+	if d.scene.Camera != nil {
+		return
+	}
+
+	bounds := linmath.NewEmptyAABB()
+	for _, o := range d.scene.Objects {
+		bounds = bounds.Union(o.Bounds())
+	}
+
+	center, radius := linmath.NewVector3(0, 0, 0), 1.
+	if len(d.scene.Objects) > 0 {
+		center = bounds.Min().Add(bounds.Max()).DivideOnScalar(2)
+		radius = math.Max(bounds.Max().Subtraction(bounds.Min()).Length()/2, 1e-3)
+	}
+
+	distance := 1.1 * radius * projectionPlaneZ / (viewportSize / 2.)
+	position := center.Subtraction(linmath.NewVector3(0, 0, distance))
+
+	d.scene.Camera = NewCamera(position, linmath.NewIdentity(), 0, 1, 0, 0, 0)
+}
+
+// placeLight is a function that returns a punctual light at the place of its node in the space of the renderer,
+// with the brightness it will be balanced by.
+func placeLight(l *gltfLight, transform *linmath.Matrix4) gltfPlacedLight {
+	intensity := 1.
+	if l.Intensity != nil {
+		intensity = *l.Intensity
+	}
+
+	if len(l.Color) == 3 {
+		intensity *= 0.2126*l.Color[0] + 0.7152*l.Color[1] + 0.0722*l.Color[2]
+	}
+
+	if l.Type == "directional" {
+		// The light shines down -Z of its node in glTF, which is +Z here; the renderer wants the way back to the light.
+		direction := transform.MultiplyDirection(linmath.NewVector3(0, 0, -1)).Normal()
+
+		return gltfPlacedLight{*NewDirectionalLight(0, direction), intensity}
+	}
+
+	return gltfPlacedLight{*NewPointLight(0, transform.MultiplyPoint(linmath.NewVector3(0, 0, 0))), intensity}
+}
+
+// finishLights is a method that adds the ambient light and the punctual lights, sharing the light left by the ambient
+// one in proportion to their brightness. A scene without lights gets a directional light from above the camera.
+func (d *gltfDecoder) finishLights() {
+	d.scene.Lights = append(d.scene.Lights, *NewAmbientLight(gltfAmbient))
+
+	total := 0.
+	for _, l := range d.lights {
+		total += math.Max(l.intensity, 0)
+	}
+
+	if total == 0 {
+		direction := d.scene.Camera.rotation.MultiplyDirection(linmath.NewVector3(0.5, 1, -1))
+		d.scene.Lights = append(d.scene.Lights, *NewDirectionalLight(1-gltfAmbient, direction))
+
+		return
+	}
+
+	for _, l := range d.lights {
+		l.light.intensity = (1 - gltfAmbient) * math.Max(l.intensity, 0) / total
+		d.scene.Lights = append(d.scene.Lights, l.light)
+	}
+}
+
+// mesh is a method that returns a renderer mesh for every triangle primitive of a glTF mesh.
+func (d *gltfDecoder) mesh(index int) ([]*Mesh, error) {
+	if meshes, ok := d.meshes[index]; ok {
+		return meshes, nil
+	}
+
+	if index < 0 || index >= len(d.document.Meshes) {
+		return nil, fmt.Errorf("gltf: mesh %d does not exist", index)
+	}
+
+	var meshes []*Mesh
+
+	for i, p := range d.document.Meshes[index].Primitives {
+		mode := gltfTriangles
+		if p.Mode != nil {
+			mode = *p.Mode
+		}
+
+		if mode != gltfTriangles && mode != gltfTriangleStrip && mode != gltfTriangleFan {
+			d.warnings = append(d.warnings, fmt.Sprintf("gltf: skipping primitive %d of mesh %d, mode %d is not made of triangles", i, index, mode))
+			continue
+		}
+
+		position, ok := p.Attributes["POSITION"]
+		if !ok {
+			return nil, fmt.Errorf("gltf: primitive %d of mesh %d has no positions", i, index)
+		}
+
+		values, _, err := d.accessor(position, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		vertices := make([]linmath.Vector3, len(values)/3)
+		for v := range vertices {
+			vertices[v] = *linmath.NewVector3(values[3*v], values[3*v+1], -values[3*v+2])
+		}
+
+		var normals []linmath.Vector3
+		if a, ok := p.Attributes["NORMAL"]; ok {
+			if values, _, err = d.vertexAccessor(a, 3, len(vertices)); err != nil {
+				return nil, err
+			}
+
+			normals = make([]linmath.Vector3, len(vertices))
+			for v := range normals {
+				normals[v] = *linmath.NewVector3(values[3*v], values[3*v+1], -values[3*v+2]).Normal()
+			}
+		}
+
+		var uvs [][2]float64
+		if a, ok := p.Attributes["TEXCOORD_0"]; ok {
+			if values, _, err = d.vertexAccessor(a, 2, len(vertices)); err != nil {
+				return nil, err
+			}
+
+			uvs = make([][2]float64, len(vertices))
+			for v := range uvs {
+				uvs[v] = [2]float64{values[2*v], values[2*v+1]}
+			}
+		}
+
+		var tangents []linmath.Vector4
+		if a, ok := p.Attributes["TANGENT"]; ok {
+			if values, _, err = d.vertexAccessor(a, 4, len(vertices)); err != nil {
+				return nil, err
+			}
+
+			// Mirroring flips the handedness of the tangent frame along with Z.
+			tangents = make([]linmath.Vector4, len(vertices))
+			for v := range tangents {
+				tangents[v] = *linmath.NewVector4(values[4*v], values[4*v+1], -values[4*v+2], -values[4*v+3])
+			}
+		}
+
+		material := d.defaultMaterial()
+		if p.Material != nil {
+			if material, err = d.material(*p.Material); err != nil {
+				return nil, err
+			}
+		}
+
+		var colors []Color
+		if a, ok := p.Attributes["COLOR_0"]; ok {
+			var components int
+			if values, components, err = d.accessor(a, 0); err != nil {
+				return nil, err
+			}
+
+			if (components != 3 && components != 4) || len(values)/components != len(vertices) {
+				return nil, fmt.Errorf("gltf: accessor %d does not hold a color for every vertex", a)
+			}
+
+			// Vertex colors replace the material color in the renderer, so they take its factor along.
+			factor := d.baseColorFactor(p.Material)
+			colors = make([]Color, len(vertices))
+			for v := range colors {
+				c := values[components*v:]
+				colors[v] = *NewColor(toSRGB(c[0]*factor[0]), toSRGB(c[1]*factor[1]), toSRGB(c[2]*factor[2]), 255)
+			}
+		}
+
+		triangles, err := d.triangles(p.Indices, mode, len(vertices))
+		if err != nil {
+			return nil, err
+		}
+
+		m := NewMesh(vertices, normals, uvs, colors, triangles, *material)
+		m.tangents = tangents
+		meshes = append(meshes, m)
+	}
+
+	d.meshes[index] = meshes
+
+	return meshes, nil
+}
+
+// triangles is a method that returns the triangles of a primitive, wound the way the renderer expects after mirroring.
+func (d *gltfDecoder) triangles(indices *int, mode, vertices int) ([][3]int, error) {
+	var order []int
+
+	if indices == nil {
+		order = make([]int, vertices)
+		for i := range order {
+			order[i] = i
+		}
+	} else {
+		values, _, err := d.accessor(*indices, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		order = make([]int, len(values))
+		for i, v := range values {
+			if order[i] = int(v); order[i] < 0 || order[i] >= vertices {
+				return nil, fmt.Errorf("gltf: index %d of accessor %d is out of range", order[i], *indices)
+			}
+		}
+	}
+
+	var triangles [][3]int
+
+	switch mode {
+	case gltfTriangleStrip:
+		for i := 0; i+2 < len(order); i++ {
+			// Every other triangle of a strip is wound backwards.
+			if i%2 == 0 {
+				triangles = append(triangles, [3]int{order[i], order[i+2], order[i+1]})
+			} else {
+				triangles = append(triangles, [3]int{order[i], order[i+1], order[i+2]})
+			}
+		}
+	case gltfTriangleFan:
+		for i := 1; i+1 < len(order); i++ {
+			triangles = append(triangles, [3]int{order[0], order[i+1], order[i]})
+		}
+	default:
+		for i := 0; i+2 < len(order); i += 3 {
+			triangles = append(triangles, [3]int{order[i], order[i+2], order[i+1]})
+		}
+	}
+
+	return triangles, nil
+}
+
+// defaultMaterial is a method that returns the material of primitives without one: white, fully rough and metallic.
+func (d *gltfDecoder) defaultMaterial() *Material {
+	if m, ok := d.materials[-1]; ok {
+		return m
+	}
+
+	d.materials[-1] = newPBRMaterial(*NewColor(255, 255, 255, 255), 1, 1, nil, nil)
+
+	return d.materials[-1]
+}
+
+// baseColorFactor is a method that returns the linear base color factor of a material, white when there is none.
+func (d *gltfDecoder) baseColorFactor(material *int) []float64 {
+	if material != nil && *material >= 0 && *material < len(d.document.Materials) {
+		if f := d.document.Materials[*material].PBRMetallicRoughness.BaseColorFactor; len(f) == 4 {
+			return f
+		}
+	}
+
+	return []float64{1, 1, 1, 1}
+}
+
+// material is a method that converts a glTF material to the Phong model of the renderer.
+func (d *gltfDecoder) material(index int) (*Material, error) {
+	if m, ok := d.materials[index]; ok {
+		return m, nil
+	}
+
+	if index < 0 || index >= len(d.document.Materials) {
+		return nil, fmt.Errorf("gltf: material %d does not exist", index)
+	}
+
+	g := &d.document.Materials[index]
+	pbr := &g.PBRMetallicRoughness
+
+	factor := d.baseColorFactor(&index)
+	color := *NewColor(toSRGB(factor[0]), toSRGB(factor[1]), toSRGB(factor[2]), 255)
+
+	metallic, roughness := 1., 1.
+	if pbr.MetallicFactor != nil {
+		metallic = *pbr.MetallicFactor
+	}
+
+	if pbr.RoughnessFactor != nil {
+		roughness = *pbr.RoughnessFactor
+	}
+
+	var texture, metallicRoughness image.Image
+	var err error
+
+	if pbr.BaseColorTexture != nil {
+		if texture, err = d.texture(pbr.BaseColorTexture.Index); err != nil {
+			return nil, err
+		}
+	}
+
+	if pbr.MetallicRoughnessTexture != nil {
+		if metallicRoughness, err = d.texture(pbr.MetallicRoughnessTexture.Index); err != nil {
+			return nil, err
+		}
+	}
+
+	m := newPBRMaterial(color, metallic, roughness, texture, metallicRoughness)
+
+	if g.NormalTexture != nil {
+		if m.normalMap, err = d.texture(g.NormalTexture.Index); err != nil {
+			return nil, err
+		}
+
+		m.normalScale = 1
+		if g.NormalTexture.Scale != nil {
+			m.normalScale = *g.NormalTexture.Scale
+		}
+	}
+
+	d.materials[index] = m
+
+	return m, nil
+}
+
+// toSRGB is a function that encodes a linear color channel from 0 to 1 with the sRGB transfer function, as the renderer stores colors.
+func toSRGB(linear float64) uint8 {
+	linear = math.Min(math.Max(linear, 0), 1)
+
+	if linear <= 0.0031308 {
+		return uint8(12.92*linear*255 + 0.5)
+	}
+
+	return uint8((1.055*math.Pow(linear, 1/2.4)-0.055)*255 + 0.5)
+}
+
+// texture is a method that returns the image of a texture, decoding it once.
+func (d *gltfDecoder) texture(index int) (image.Image, error) {
+	if index < 0 || index >= len(d.document.Textures) || d.document.Textures[index].Source == nil {
+		return nil, fmt.Errorf("gltf: texture %d does not exist or has no image", index)
+	}
+
+	source := *d.document.Textures[index].Source
+	if img, ok := d.images[source]; ok {
+		return img, nil
+	}
+
+	if source < 0 || source >= len(d.document.Images) {
+		return nil, fmt.Errorf("gltf: image %d does not exist", source)
+	}
+
+	var data []byte
+	var err error
+
+	if i := &d.document.Images[source]; i.BufferView != nil {
+		data, err = d.bufferView(*i.BufferView)
+	} else {
+		data, err = d.uri(i.URI)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gltf: image %d: %w", source, err)
+	}
+
+	d.images[source] = img
+
+	return img, nil
+}
+
+// uri is a method that returns the data of a data URI or of a file relative to the document.
+func (d *gltfDecoder) uri(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 {
+			return nil, errors.New("gltf: malformed data URI")
+		}
+
+		if strings.HasSuffix(uri[:comma], ";base64") {
+			return base64.StdEncoding.DecodeString(uri[comma+1:])
+		}
+
+		data, err := url.PathUnescape(uri[comma+1:])
+
+		return []byte(data), err
+	}
+
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, fmt.Errorf("gltf: %w", err)
+	}
+
+	return fs.ReadFile(d.fsys, path.Join(d.directory, name))
+}
+
+// buffer is a method that returns the data of a buffer, loading it once.
+func (d *gltfDecoder) buffer(index int) ([]byte, error) {
+	if data, ok := d.buffers[index]; ok {
+		return data, nil
+	}
+
+	if index < 0 || index >= len(d.document.Buffers) {
+		return nil, fmt.Errorf("gltf: buffer %d does not exist", index)
+	}
+
+	b := &d.document.Buffers[index]
+
+	data := d.binary
+	if b.URI != "" {
+		var err error
+		if data, err = d.uri(b.URI); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) < b.ByteLength {
+		return nil, fmt.Errorf("gltf: buffer %d is shorter than its %d bytes", index, b.ByteLength)
+	}
+
+	d.buffers[index] = data
+
+	return data, nil
+}
+
+// bufferView is a method that returns the bytes of a buffer view.
+func (d *gltfDecoder) bufferView(index int) ([]byte, error) {
+	if index < 0 || index >= len(d.document.BufferViews) {
+		return nil, fmt.Errorf("gltf: buffer view %d does not exist", index)
+	}
+
+	v := &d.document.BufferViews[index]
+
+	data, err := d.buffer(v.Buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.ByteOffset < 0 || v.ByteLength < 0 || v.ByteOffset+v.ByteLength > len(data) {
+		return nil, fmt.Errorf("gltf: buffer view %d is out of the bounds of its buffer", index)
+	}
+
+	return data[v.ByteOffset : v.ByteOffset+v.ByteLength], nil
+}
+
+// vertexAccessor is a method that reads an accessor that must hold an element of the given size for every vertex.
+func (d *gltfDecoder) vertexAccessor(index, components, vertices int) ([]float64, int, error) {
+	values, n, err := d.accessor(index, components)
+	if err == nil && len(values) != components*vertices {
+		err = fmt.Errorf("gltf: accessor %d has %d elements for %d vertices", index, len(values)/n, vertices)
+	}
+
+	return values, n, err
+}
+
+// accessor is a method that reads every component of an accessor as a float64, mapping normalized integers to [0, 1]
+// or [-1, 1]. When components is not zero the accessor must have that many components per element.
+// It also returns the number of components.
+func (d *gltfDecoder) accessor(index, components int) ([]float64, int, error) {
+	if index < 0 || index >= len(d.document.Accessors) {
+		return nil, 0, fmt.Errorf("gltf: accessor %d does not exist", index)
+	}
+
+	a := &d.document.Accessors[index]
+
+	n, ok := gltfComponents[a.Type]
+	if !ok || (components != 0 && n != components) {
+		return nil, 0, fmt.Errorf("gltf: accessor %d has unexpected type %s", index, a.Type)
+	}
+
+	if a.Count < 0 {
+		return nil, 0, fmt.Errorf("gltf: accessor %d has a negative count", index)
+	}
+
+	// An accessor without a buffer view is all zeros, unless sparse values replace some of them.
+	var values []float64
+	if a.BufferView != nil {
+		view, err := d.bufferView(*a.BufferView)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		stride := d.document.BufferViews[*a.BufferView].ByteStride
+		if values, err = readComponents(view, a.ByteOffset, stride, a.Count, n, a.ComponentType, a.Normalized); err != nil {
+			return nil, 0, fmt.Errorf("gltf: accessor %d: %w", index, err)
+		}
+	} else if a.Count > gltfMaxZeros {
+		return nil, 0, fmt.Errorf("gltf: accessor %d has %d elements without a buffer view", index, a.Count)
+	} else {
+		values = make([]float64, a.Count*n)
+	}
+
+	if s := a.Sparse; s != nil {
+		if s.Count < 0 || s.Count > a.Count {
+			return nil, 0, fmt.Errorf("gltf: sparse accessor %d replaces %d of %d elements", index, s.Count, a.Count)
+		}
+
+		var indices, sparse []float64
+
+		view, err := d.bufferView(s.Indices.BufferView)
+		if err == nil {
+			indices, err = readComponents(view, s.Indices.ByteOffset, 0, s.Count, 1, s.Indices.ComponentType, false)
+		}
+
+		if err == nil {
+			if view, err = d.bufferView(s.Values.BufferView); err == nil {
+				sparse, err = readComponents(view, s.Values.ByteOffset, 0, s.Count, n, a.ComponentType, a.Normalized)
+			}
+		}
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("gltf: sparse accessor %d: %w", index, err)
+		}
+
+		for i, element := range indices {
+			if element < 0 || int(element) >= a.Count {
+				return nil, 0, fmt.Errorf("gltf: sparse accessor %d replaces element %v of %d", index, element, a.Count)
+			}
+
+			copy(values[int(element)*n:(int(element)+1)*n], sparse[i*n:(i+1)*n])
+		}
+	}
+
+	return values, n, nil
+}
+
+// readComponents is a function that decodes count elements of n components each from a buffer view,
+// starting at the offset and stride bytes apart, or tightly packed when the stride is zero.
+// The elements are checked to lie in the view before any memory is allocated for them.
+func readComponents(view []byte, offset, stride, count, n, componentType int, normalized bool) ([]float64, error) {
+	var size int
+	switch componentType {
+	case gltfByte, gltfUnsignedByte:
+		size = 1
+	case gltfShort, gltfUnsignedShort:
+		size = 2
+	case gltfUnsignedInt, gltfFloat:
+		size = 4
+	default:
+		return nil, fmt.Errorf("unknown component type %d", componentType)
+	}
+
+	if stride == 0 {
+		stride = size * n
+	}
+
+	if count < 0 || offset < 0 || stride < 0 {
+		return nil, errors.New("negative count, offset or stride")
+	}
+
+	// The last element has to end in the view; dividing instead of multiplying keeps huge counts from overflowing.
+	if last := len(view) - offset - size*n; count > 0 && (last < 0 || count-1 > last/stride) {
+		return nil, errors.New("elements are out of the bounds of the buffer view")
+	}
+
+	values := make([]float64, count*n)
+
+	for e := 0; e < count; e++ {
+		for c := 0; c < n; c++ {
+			b := view[offset+e*stride+c*size:]
+
+			var v float64
+			switch componentType {
+			case gltfByte:
+				if v = float64(int8(b[0])); normalized {
+					v = math.Max(v/127, -1)
+				}
+			case gltfUnsignedByte:
+				if v = float64(b[0]); normalized {
+					v /= 255
+				}
+			case gltfShort:
+				if v = float64(int16(binary.LittleEndian.Uint16(b))); normalized {
+					v = math.Max(v/32767, -1)
+				}
+			case gltfUnsignedShort:
+				if v = float64(binary.LittleEndian.Uint16(b)); normalized {
+					v /= 65535
+				}
+			case gltfUnsignedInt:
+				if v = float64(binary.LittleEndian.Uint32(b)); normalized {
+					v /= math.MaxUint32
+				}
+			case gltfFloat:
+				v = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+			}
+
+			values[e*n+c] = v
+		}
+	}
+
+	return values, nil
+}
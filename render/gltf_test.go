@@ -0,0 +1,349 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// equalVectors is a function that tells whether two vectors are equal up to rounding.
+func equalVectors(v1, v2 *linmath.Vector3) bool {
+	return v1.Subtraction(v2).Length() < 1e-9
+}
+
+// gltfQuad is a function that returns a document with a red textured quad facing the camera five units away, the quad
+// being scaled by two by its node, a point light and a directional light, and the binary buffer it refers to.
+func gltfQuad(t *testing.T) (map[string]interface{}, []byte) {
+	var buffer bytes.Buffer
+
+	write := func(values ...interface{}) {
+		for _, v := range values {
+			if err := binary.Write(&buffer, binary.LittleEndian, v); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Positions, normals and UVs of four vertices, then six indices, wound counterclockwise seen from +Z.
+	write([]float32{-0.5, -0.5, 0, 0.5, -0.5, 0, 0.5, 0.5, 0, -0.5, 0.5, 0})
+	write([]float32{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1})
+	write([]float32{0, 1, 1, 1, 1, 0, 0, 0})
+	write([]uint16{0, 1, 2, 0, 2, 3})
+
+	texture := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for i := range texture.Pix {
+		texture.Pix[i] = 255
+	}
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, texture); err != nil {
+		t.Fatal(err)
+	}
+
+	document := map[string]interface{}{
+		"asset":              map[string]interface{}{"version": "2.0"},
+		"extensionsUsed":     []string{"KHR_lights_punctual"},
+		"extensionsRequired": []string{"KHR_lights_punctual"},
+		"scene":              0,
+		"scenes":             []interface{}{map[string]interface{}{"nodes": []int{0, 2, 3, 4}}},
+		"nodes": []interface{}{
+			map[string]interface{}{"translation": []float64{0, 0, -5}, "children": []int{1}},
+			map[string]interface{}{"scale": []float64{2, 2, 2}, "mesh": 0},
+			map[string]interface{}{"camera": 0},
+			map[string]interface{}{"translation": []float64{0, 2, 0}, "extensions": map[string]interface{}{"KHR_lights_punctual": map[string]int{"light": 0}}},
+			map[string]interface{}{"extensions": map[string]interface{}{"KHR_lights_punctual": map[string]int{"light": 1}}},
+		},
+		"meshes": []interface{}{map[string]interface{}{"primitives": []interface{}{map[string]interface{}{
+			"attributes": map[string]int{"POSITION": 0, "NORMAL": 1, "TEXCOORD_0": 2},
+			"indices":    3,
+			"material":   0,
+		}}}},
+		"materials": []interface{}{map[string]interface{}{"pbrMetallicRoughness": map[string]interface{}{
+			"baseColorFactor":  []float64{1, 0, 0, 1},
+			"baseColorTexture": map[string]int{"index": 0},
+			"metallicFactor":   0,
+			"roughnessFactor":  0.5,
+		}}},
+		"textures": []interface{}{map[string]int{"source": 0}},
+		"images":   []interface{}{map[string]string{"uri": "data:image/png;base64," + base64.StdEncoding.EncodeToString(encoded.Bytes())}},
+		"cameras":  []interface{}{map[string]interface{}{"type": "perspective", "perspective": map[string]float64{"yfov": 1, "znear": 0.1}}},
+		"accessors": []interface{}{
+			map[string]interface{}{"bufferView": 0, "componentType": gltfFloat, "count": 4, "type": "VEC3"},
+			map[string]interface{}{"bufferView": 0, "byteOffset": 48, "componentType": gltfFloat, "count": 4, "type": "VEC3"},
+			map[string]interface{}{"bufferView": 0, "byteOffset": 96, "componentType": gltfFloat, "count": 4, "type": "VEC2"},
+			map[string]interface{}{"bufferView": 1, "componentType": gltfUnsignedShort, "count": 6, "type": "SCALAR"},
+		},
+		"bufferViews": []interface{}{
+			map[string]int{"buffer": 0, "byteLength": 128},
+			map[string]int{"buffer": 0, "byteOffset": 128, "byteLength": 12},
+		},
+		"extensions": map[string]interface{}{"KHR_lights_punctual": map[string]interface{}{"lights": []interface{}{
+			map[string]interface{}{"type": "point", "intensity": 10},
+			map[string]interface{}{"type": "directional", "intensity": 30, "color": []float64{1, 1, 1}},
+		}}},
+	}
+
+	return document, buffer.Bytes()
+}
+
+// glb is a function that packs a document and its binary buffer into a binary glTF file.
+func glb(t *testing.T, document map[string]interface{}, bin []byte) []byte {
+	document["buffers"] = []interface{}{map[string]int{"byteLength": len(bin)}}
+
+	text, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for len(text)%4 != 0 {
+		text = append(text, ' ')
+	}
+
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+
+	var file bytes.Buffer
+	_ = binary.Write(&file, binary.LittleEndian, []uint32{glbMagic, 2, uint32(12 + 8 + len(text) + 8 + len(bin))})
+	_ = binary.Write(&file, binary.LittleEndian, []uint32{uint32(len(text)), glbJSONChunk})
+	file.Write(text)
+	_ = binary.Write(&file, binary.LittleEndian, []uint32{uint32(len(bin)), glbBINChunk})
+	file.Write(bin)
+
+	return file.Bytes()
+}
+
+func TestDecodeGLTF(t *testing.T) {
+	embedded, bin := gltfQuad(t)
+	embedded["buffers"] = []interface{}{map[string]interface{}{
+		"uri":        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(bin),
+		"byteLength": len(bin),
+	}}
+
+	external, _ := gltfQuad(t)
+	external["buffers"] = []interface{}{map[string]interface{}{"uri": "quad%20data.bin", "byteLength": len(bin)}}
+
+	binaryDocument, _ := gltfQuad(t)
+
+	files := fstest.MapFS{
+		"models/quad data.bin": {Data: bin},
+		"quad.glb":             {Data: glb(t, binaryDocument, bin)},
+	}
+
+	for name, document := range map[string]map[string]interface{}{"embedded.gltf": embedded, "models/external.gltf": external} {
+		data, err := json.Marshal(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[name] = &fstest.MapFile{Data: data}
+	}
+
+	for _, name := range []string{"embedded.gltf", "models/external.gltf", "quad.glb"} {
+		scene, warnings, err := DecodeGLTF(files, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(warnings) != 0 {
+			t.Fatalf("%s: expected no warnings but have [%v]", name, warnings)
+		}
+
+		if len(scene.Objects) != 1 {
+			t.Fatalf("%s: expected [1] object but have [%v]", name, len(scene.Objects))
+		}
+
+		// The quad is scaled to two units across and moved from -Z in glTF to +Z in the renderer.
+		bounds := scene.Objects[0].Bounds()
+		if !equalVectors(bounds.Min(), linmath.NewVector3(-1, -1, 5)) || !equalVectors(bounds.Max(), linmath.NewVector3(1, 1, 5)) {
+			t.Fatalf("%s: expected bounds from [-1 -1 5] to [1 1 5] but have [%v]", name, bounds)
+		}
+
+		if scene.Camera.fieldOfView != 1 || !equalVectors(&scene.Camera.position, linmath.NewVector3(0, 0, 0)) {
+			t.Fatalf("%s: unexpected camera [%+v]", name, *scene.Camera)
+		}
+
+		expectedLights := []Light{
+			*NewAmbientLight(0.2),
+			*NewPointLight(0.2, linmath.NewVector3(0, 2, 0)),
+			*NewDirectionalLight(0.6, linmath.NewVector3(0, 0, -1)),
+		}
+
+		if len(scene.Lights) != len(expectedLights) {
+			t.Fatalf("%s: expected [%v] lights but have [%v]", name, len(expectedLights), len(scene.Lights))
+		}
+
+		for i, l := range scene.Lights {
+			e := expectedLights[i]
+			if l.lightType != e.lightType || math.Abs(l.intensity-e.intensity) > 1e-9 ||
+				!equalVectors(&l.position, &e.position) || !equalVectors(&l.direction, &e.direction) {
+				t.Fatalf("%s: expected light [%+v] but have [%+v]", name, e, l)
+			}
+		}
+
+		material := scene.Objects[0].(*Instance).geometry.(*Mesh).material
+		if material.color != *NewColor(255, 0, 0, 255) || material.specular != 30 || material.reflective != 0 || material.texture == nil {
+			t.Fatalf("%s: unexpected material [%+v]", name, material)
+		}
+
+		// The quad faces the camera, so neither backend culls it.
+		for _, backend := range []Backend{RayTraceBackend, RasterBackend} {
+			img, err := Render(scene, &Options{Backend: backend, Width: 20, Height: 20})
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			if r, g, _, _ := img.At(10, 10).RGBA(); r <= g {
+				t.Fatalf("%s: expected the red quad in the middle but have [%v]", name, img.At(10, 10))
+			}
+		}
+	}
+}
+
+func TestDecodeGLTFErrors(t *testing.T) {
+	_, bin := gltfQuad(t)
+
+	tests := []struct {
+		name          string
+		inputChange   func(document map[string]interface{})
+		expectedError string
+	}{
+		{"version", func(d map[string]interface{}) { d["asset"] = map[string]string{"version": "1.0"} }, "unsupported version"},
+		{"extension", func(d map[string]interface{}) { d["extensionsRequired"] = []string{"KHR_draco_mesh_compression"} }, "unsupported required extension"},
+		{"missing buffer", func(d map[string]interface{}) {
+			d["buffers"] = []interface{}{map[string]interface{}{"uri": "missing.bin", "byteLength": 140}}
+		}, "missing.bin"},
+		{"short buffer", func(d map[string]interface{}) {
+			d["buffers"] = []interface{}{map[string]interface{}{"uri": "data:application/octet-stream;base64,AAAA", "byteLength": 140}}
+		}, "shorter"},
+		{"accessor out of bounds", func(d map[string]interface{}) {
+			d["accessors"].([]interface{})[0].(map[string]interface{})["count"] = 40
+		}, "out of the bounds"},
+		{"negative count", func(d map[string]interface{}) {
+			d["accessors"].([]interface{})[0].(map[string]interface{})["count"] = -1
+		}, "negative count"},
+		{"huge count", func(d map[string]interface{}) {
+			d["accessors"].([]interface{})[0].(map[string]interface{})["count"] = int64(1) << 61
+		}, "out of the bounds"},
+		{"huge count without a buffer view", func(d map[string]interface{}) {
+			accessor := d["accessors"].([]interface{})[0].(map[string]interface{})
+			delete(accessor, "bufferView")
+			accessor["count"] = int64(1) << 61
+		}, "without a buffer view"},
+		{"negative sparse count", func(d map[string]interface{}) {
+			d["accessors"].([]interface{})[0].(map[string]interface{})["sparse"] = map[string]interface{}{
+				"count":   -1,
+				"indices": map[string]int{"bufferView": 1, "componentType": gltfUnsignedShort},
+				"values":  map[string]int{"bufferView": 0},
+			}
+		}, "replaces -1 of 4 elements"},
+		{"node its own child", func(d map[string]interface{}) {
+			d["nodes"].([]interface{})[1].(map[string]interface{})["children"] = []int{1}
+		}, "its own ancestor"},
+		{"node child of its child", func(d map[string]interface{}) {
+			d["nodes"].([]interface{})[1].(map[string]interface{})["children"] = []int{0}
+		}, "its own ancestor"},
+	}
+
+	for _, ts := range tests {
+		document, _ := gltfQuad(t)
+		document["buffers"] = []interface{}{map[string]interface{}{
+			"uri":        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(bin),
+			"byteLength": len(bin),
+		}}
+		ts.inputChange(document)
+
+		data, err := json.Marshal(document)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = DecodeGLTF(fstest.MapFS{"scene.gltf": {Data: data}}, "scene.gltf")
+		if err == nil || !strings.Contains(err.Error(), ts.expectedError) {
+			t.Fatalf("%s: expected an error containing [%v] but have [%v]", ts.name, ts.expectedError, err)
+		}
+	}
+}
+
+func TestDecodeGLTFWarnings(t *testing.T) {
+	document, bin := gltfQuad(t)
+	document["buffers"] = []interface{}{map[string]interface{}{
+		"uri":        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(bin),
+		"byteLength": len(bin),
+	}}
+
+	// A second primitive made of points and an orthographic camera are approximated or left out.
+	mesh := document["meshes"].([]interface{})[0].(map[string]interface{})
+	mesh["primitives"] = append(mesh["primitives"].([]interface{}), map[string]interface{}{"attributes": map[string]int{"POSITION": 0}, "mode": 0})
+	document["cameras"] = []interface{}{map[string]interface{}{"type": "orthographic"}}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scene, warnings, err := DecodeGLTF(fstest.MapFS{"scene.gltf": {Data: data}}, "scene.gltf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(scene.Objects) != 1 {
+		t.Fatalf("expected [1] object but have [%v]", len(scene.Objects))
+	}
+
+	expected := []string{"skipping primitive 1 of mesh 0", "orthographic cameras are not supported"}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected [%v] warnings but have [%v]", len(expected), warnings)
+	}
+
+	for i, warning := range warnings {
+		if !strings.Contains(warning, expected[i]) {
+			t.Fatalf("expected a warning containing [%v] but have [%v]", expected[i], warning)
+		}
+	}
+}
+
+func TestNormalMap(t *testing.T) {
+	// A normal map tilted halfway towards +U on a quad facing -Z, with U along +X.
+	tilted := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	tilted.SetNRGBA(0, 0, color.NRGBA{R: 218, G: 128, B: 218, A: 255})
+
+	vertices := []linmath.Vector3{
+		*linmath.NewVector3(-1, -1, 0),
+		*linmath.NewVector3(-1, 1, 0),
+		*linmath.NewVector3(1, 1, 0),
+		*linmath.NewVector3(1, -1, 0),
+	}
+	uvs := [][2]float64{{0, 1}, {0, 0}, {1, 0}, {1, 1}}
+	tangents := []linmath.Vector4{*linmath.NewVector4(1, 0, 0, 1), *linmath.NewVector4(1, 0, 0, 1), *linmath.NewVector4(1, 0, 0, 1), *linmath.NewVector4(1, 0, 0, 1)}
+
+	material := NewMaterial(*NewColor(255, 255, 255, 255), -1, 0, nil)
+	material.normalMap, material.normalScale = tilted, 1
+
+	expected := linmath.NewVector3(math.Sqrt2/2, 0, -math.Sqrt2/2)
+
+	for _, withTangents := range []bool{true, false} {
+		mesh := NewMesh(vertices, nil, uvs, nil, [][3]int{{0, 1, 2}, {0, 2, 3}}, *material)
+		if withTangents {
+			mesh.tangents = tangents
+		}
+
+		h, ok := mesh.Intersect(linmath.NewRay(linmath.NewVector3(0.2, 0.3, -1), linmath.NewVector3(0, 0, 1), 0), 0, math.Inf(1))
+		if !ok {
+			t.Fatalf("expected the ray to hit the quad")
+		}
+
+		if math.Abs(h.Normal.X()-expected.X()) > 0.01 || math.Abs(h.Normal.Y()) > 0.01 || math.Abs(h.Normal.Z()-expected.Z()) > 0.01 {
+			t.Fatalf("tangents %v: expected [%v] but have [%v]", withTangents, expected, h.Normal)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package render_test
+
+import (
+	"flag"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"github.com/UnTea/ComputerGraphics/render"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden images in testdata/golden")
+
+const (
+	goldenDirectory  = "testdata/golden"
+	failureDirectory = "testdata/failures"
+	goldenWidth      = 64
+	goldenHeight     = 48
+	// pixelTolerance is the largest difference of a channel that still counts as the same pixel,
+	// enough to absorb rounding that differs between platforms.
+	pixelTolerance = 3
+	// maxDifferentPixels is the fraction of pixels that may exceed pixelTolerance.
+	maxDifferentPixels = 0.002
+	// minSSIM is the lowest structural similarity to the golden image that passes.
+	minSSIM = 0.995
+	// ssimWindow is the side of the square window the structural similarity is averaged over.
+	ssimWindow = 7
+)
+
+// goldenMaterial is a function that returns an untextured material of the given color.
+func goldenMaterial(r, g, b uint8, specular, reflective float64) render.Material {
+	return *render.NewMaterial(*render.NewColor(r, g, b, 255), specular, reflective, nil)
+}
+
+// goldenFloor is a function that returns a checkered square at y = -1 under the reference scenes.
+func goldenFloor() *render.Mesh {
+	return render.NewMesh(
+		[]linmath.Vector3{
+			*linmath.NewVector3(-10, -1, -10),
+			*linmath.NewVector3(-10, -1, 10),
+			*linmath.NewVector3(10, -1, 10),
+			*linmath.NewVector3(10, -1, -10),
+		},
+		nil,
+		[][2]float64{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+		nil,
+		[][3]int{{0, 1, 2}, {0, 2, 3}},
+		*render.NewMaterial(*render.NewColor(255, 255, 0, 255), 1000, 0.3, render.NewCheckerTexture(8, 2)),
+	)
+}
+
+// goldenLights is a function that returns the lights shared by the reference scenes.
+func goldenLights() []render.Light {
+	return []render.Light{
+		*render.NewAmbientLight(0.2),
+		*render.NewPointLight(0.6, linmath.NewVector3(2, 1, 0)),
+		*render.NewDirectionalLight(0.2, linmath.NewVector3(1, 4, 4)),
+	}
+}
+
+// goldenSpheres is a function that returns a reference scene of shiny, reflective and textured spheres on a floor.
+func goldenSpheres() *render.Scene {
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewSphere(*linmath.NewVector3(0, -0.5, 3), 0.5, goldenMaterial(255, 0, 0, 500, 0.2)),
+			render.NewSphere(*linmath.NewVector3(1.5, 0, 4), 1, goldenMaterial(0, 0, 255, 500, 0.4)),
+			render.NewSphere(
+				*linmath.NewVector3(-1.5, 0, 4),
+				1,
+				*render.NewMaterial(*render.NewColor(0, 255, 0, 255), 10, 0, render.NewCheckerTexture(16, 8)),
+			),
+			goldenFloor(),
+		},
+		Lights: goldenLights(),
+		Camera: render.NewCamera(linmath.NewVector3(0, 0.5, 0), linmath.NewRotationX(linmath.Radians(8)), 0, 1, 0, 0, 0),
+	}
+}
+
+// goldenInstances is a function that returns a reference scene of a CSG difference and
+// instances of one sphere, one of them moving while the shutter is open.
+func goldenInstances() *render.Scene {
+	unitSphere := render.NewSphere(*linmath.NewVector3(0, 0, 0), 1, goldenMaterial(255, 255, 0, 1000, 0))
+	ellipsoid := linmath.NewRotationZ(linmath.Radians(30)).Multiply(linmath.NewScale(0.8, 0.3, 0.3))
+
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewDifference(
+				render.NewSphere(*linmath.NewVector3(0, 0, 4), 1, goldenMaterial(0, 255, 255, 50, 0)),
+				render.NewSphere(*linmath.NewVector3(0.6, 0.4, 3.2), 0.7, goldenMaterial(255, 128, 0, -1, 0)),
+			),
+			render.NewInstance(unitSphere, linmath.NewTranslation(-1.5, 1, 5).Multiply(linmath.NewScale(1, 0.3, 0.3)), nil),
+			render.NewMovingInstance(
+				unitSphere,
+				linmath.NewTranslation(1, 1, 5).Multiply(ellipsoid),
+				linmath.NewTranslation(1.6, 1, 5).Multiply(ellipsoid),
+				render.NewMaterial(*render.NewColor(255, 0, 255, 255), 100, 0, nil),
+			),
+			goldenFloor(),
+		},
+		Lights: goldenLights(),
+		Camera: render.NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 1),
+	}
+}
+
+// goldenImplicit is a function that returns a reference scene of signed distance fields next to an analytic sphere:
+// a blob of three spheres, a twisted box standing in a torus and a row of capsules.
+func goldenImplicit() *render.Scene {
+	blob := render.NewSmoothUnion(
+		render.NewSmoothUnion(
+			render.NewSDFSphere(linmath.NewVector3(-1.9, 0, 4.5), 0.6),
+			render.NewSDFSphere(linmath.NewVector3(-1.2, 0.3, 4.5), 0.5),
+			0.5,
+		),
+		render.NewSDFSphere(linmath.NewVector3(-1.5, -0.4, 4), 0.4),
+		0.5,
+	)
+
+	// The twist turns around the Y axis, so the box is twisted at the origin and moved into place by an instance.
+	twisted := render.NewImplicitSurface(
+		render.NewTwist(render.NewSDFBox(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0.6, 1.4, 0.6), 0.05), math.Pi/3),
+		goldenMaterial(255, 128, 0, 100, 0),
+	)
+
+	capsules := render.NewRepetition(
+		render.NewSDFCapsule(linmath.NewVector3(0, -0.3, 0), linmath.NewVector3(0, 0.3, 0), 0.15),
+		linmath.NewVector3(0.5, 0, 0),
+		[3]int{2, 0, 0},
+	)
+
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewImplicitSurface(blob, goldenMaterial(0, 255, 0, 50, 0)),
+			render.NewInstance(twisted, linmath.NewTranslation(0, -0.3, 5), nil),
+			render.NewImplicitSurface(render.NewSDFTorus(linmath.NewVector3(0, -0.95, 5), 0.9, 0.05), goldenMaterial(255, 255, 0, 500, 0)),
+			render.NewInstance(render.NewImplicitSurface(capsules, goldenMaterial(255, 0, 255, 100, 0)), linmath.NewTranslation(1.6, 0.9, 5), nil),
+			render.NewSphere(*linmath.NewVector3(1.6, -0.4, 4.5), 0.6, goldenMaterial(0, 0, 255, 500, 0.4)),
+			goldenFloor(),
+		},
+		Lights: goldenLights(),
+		Camera: render.NewCamera(linmath.NewVector3(0, 0.5, 0), linmath.NewRotationX(linmath.Radians(8)), 0, 1, 0, 0, 0),
+	}
+}
+
+// goldenQuadrics is a function that returns a reference scene of an ellipsoid, a paraboloid, a hyperboloid and a torus.
+func goldenQuadrics() *render.Scene {
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewEllipsoid(*linmath.NewVector3(-1.7, -0.2, 4.5), *linmath.NewVector3(0.5, 0.8, 0.5), goldenMaterial(0, 255, 0, 50, 0)),
+			render.NewParaboloid(*linmath.NewVector3(-0.5, -1, 4), 0.5, 1, goldenMaterial(255, 0, 0, 500, 0)),
+			render.NewHyperboloid(*linmath.NewVector3(0.7, 0, 5), 0.3, 0.6, 1, goldenMaterial(0, 255, 255, 100, 0)),
+			render.NewTorus(*linmath.NewVector3(1.8, -0.6, 4), 0.6, 0.2, goldenMaterial(255, 255, 0, 500, 0.3)),
+			goldenFloor(),
+		},
+		Lights: goldenLights(),
+		Camera: render.NewCamera(linmath.NewVector3(0, 0.5, 0), linmath.NewRotationX(linmath.Radians(8)), 0, 1, 0, 0, 0),
+	}
+}
+
+// goldenVolumes is a function that returns the spheres in fog, with a thin cloud around the red sphere
+// and a puff of smoke of varying density over the green one.
+func goldenVolumes() *render.Scene {
+	n := 8
+	smoke := make([]float64, n*n*n)
+	for z := 0; z < n; z++ {
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				dx, dy, dz := float64(x)/float64(n-1)-0.5, float64(y)/float64(n-1)-0.5, float64(z)/float64(n-1)-0.5
+				smoke[(z*n+y)*n+x] = math.Max(1-4*(dx*dx+dy*dy+dz*dz), 0)
+			}
+		}
+	}
+
+	density, err := render.NewDensityGrid(n, n, n, smoke)
+	if err != nil {
+		panic(err)
+	}
+
+	scene := goldenSpheres()
+	scene.Fog = render.NewFog(*render.NewColor(200, 210, 230, 255), 0.08)
+	scene.Volumes = []render.Volume{
+		*render.NewVolumeSphere(*linmath.NewVector3(0, -0.5, 3), 0.9, render.NewMedium(*render.NewColor(255, 230, 200, 255), 0.05, 0.8, 0.6, nil)),
+		*render.NewVolumeBox(*linmath.NewVector3(-2.5, 0.5, 3), *linmath.NewVector3(-0.5, 2, 5), render.NewMedium(*render.NewColor(120, 120, 120, 255), 1, 3, 0, density)),
+	}
+
+	return scene
+}
+
+// goldenSubsurface is a function that returns a sphere of wax, one of skin and a thin leaf of soap between them
+// with a light behind them that shines through the leaf and the edges of the spheres.
+func goldenSubsurface() *render.Scene {
+	leaf := render.NewSphere(*linmath.NewVector3(0, 0, 0), 1, *render.NewSubsurfaceMaterial(*render.NewColor(180, 230, 200, 255), 0.1, 200))
+
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewSphere(*linmath.NewVector3(-1.3, -0.2, 4), 0.8, *render.NewSubsurfaceMaterial(*render.NewColor(240, 220, 160, 255), 0.1, 50)),
+			render.NewSphere(*linmath.NewVector3(1.3, -0.2, 4), 0.8, *render.NewSubsurfaceMaterial(*render.NewColor(230, 160, 130, 255), 0.05, 20)),
+			render.NewInstance(leaf, linmath.NewTranslation(0, 0.2, 5.5).Multiply(linmath.NewScale(0.6, 1.2, 0.08)), nil),
+			goldenFloor(),
+		},
+		Lights: []render.Light{
+			*render.NewAmbientLight(0.15),
+			*render.NewPointLight(0.8, linmath.NewVector3(0, 1.5, 8)),
+			*render.NewDirectionalLight(0.5, linmath.NewVector3(-1, 2, -2)),
+		},
+		Camera: render.NewCamera(linmath.NewVector3(0, 0.5, 0), linmath.NewRotationX(linmath.Radians(8)), 0, 1, 0, 0, 0),
+	}
+}
+
+// goldenDepthOfField is a function that returns the spheres seen through a wide hexagonal aperture focused on the red sphere.
+func goldenDepthOfField() *render.Scene {
+	scene := goldenSpheres()
+	scene.Camera = render.NewCamera(linmath.NewVector3(0, 0.5, 0), linmath.NewRotationX(linmath.Radians(8)), 0.1, 3, 6, 0, 0)
+
+	return scene
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name         string
+		inputScene   func() *render.Scene
+		inputOptions render.Options
+	}{
+		{"raytrace-spheres", goldenSpheres, render.Options{}},
+		{"raytrace-instances", goldenInstances, render.Options{Samples: 8}},
+		{"raytrace-depth-of-field", goldenDepthOfField, render.Options{Samples: 8}},
+		{"raytrace-implicit", goldenImplicit, render.Options{}},
+		{"raytrace-quadrics", goldenQuadrics, render.Options{}},
+		{"raytrace-volumes", goldenVolumes, render.Options{}},
+		{"raytrace-subsurface", goldenSubsurface, render.Options{}},
+		{"raster-flat", goldenSpheres, render.Options{Backend: render.RasterBackend, Shading: render.FlatShading}},
+		{"raster-gouraud", goldenSpheres, render.Options{Backend: render.RasterBackend, Shading: render.GouraudShading}},
+		{"raster-phong", goldenSpheres, render.Options{Backend: render.RasterBackend, Shading: render.PhongShading}},
+		{"raster-instances", goldenInstances, render.Options{Backend: render.RasterBackend, Shading: render.PhongShading}},
+		{"raster-wireframe", goldenSpheres, render.Options{Backend: render.RasterBackend, Wireframe: true}},
+	}
+
+	for _, ts := range tests {
+		ts := ts
+
+		t.Run(ts.name, func(t *testing.T) {
+			options := ts.inputOptions
+			options.Width, options.Height = goldenWidth, goldenHeight
+
+			img, err := render.Render(ts.inputScene(), &options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join(goldenDirectory, ts.name+".png")
+			if *update {
+				if err = os.MkdirAll(goldenDirectory, 0o755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err = render.WritePNG(golden, img); err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			expected, err := loadPNG(golden)
+			if err != nil {
+				t.Fatalf("%v; run go test ./render -run TestGolden -update to create it", err)
+			}
+
+			difference, err := compareImages(expected, img)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if difference.passes() {
+				return
+			}
+
+			if err = writeFailure(ts.name, img, difference.image); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Fatalf(
+				"%s differs from %s: %.2f%% of pixels off by more than %v (at most %v%%), SSIM [%.4f] (at least %v); see %s",
+				ts.name, golden, 100*difference.differentPixels, pixelTolerance, 100*maxDifferentPixels,
+				difference.ssim, minSSIM, failureDirectory,
+			)
+		})
+	}
+}
+
+func TestCompareImages(t *testing.T) {
+	gradient := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	shifted := image.NewRGBA(gradient.Bounds())
+	speckled := image.NewRGBA(gradient.Bounds())
+	inverted := image.NewRGBA(gradient.Bounds())
+
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			v := uint8(x * 8)
+			gradient.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+			shifted.SetRGBA(x, y, color.RGBA{R: v + 2, G: v + 2, B: v + 2, A: 255})
+			speckled.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+			inverted.SetRGBA(x, y, color.RGBA{R: 255 - v, G: 255 - v, B: 255 - v, A: 255})
+		}
+	}
+
+	// A few wrong pixels barely change the structure but exceed the per-pixel tolerance.
+	for i := 0; i < 4; i++ {
+		speckled.SetRGBA(4+i*8, 16, color.RGBA{R: 255, A: 255})
+	}
+
+	tests := []struct {
+		name           string
+		inputImage     image.Image
+		expectedPasses bool
+	}{
+		{"identical", gradient, true},
+		{"within tolerance", shifted, true},
+		{"wrong pixels", speckled, false},
+		{"inverted", inverted, false},
+	}
+
+	for _, ts := range tests {
+		difference, err := compareImages(gradient, ts.inputImage)
+		if err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+
+		if passes := difference.passes(); passes != ts.expectedPasses {
+			t.Fatalf("%s: expected [%v] but have [%v] with [%+v]", ts.name, ts.expectedPasses, passes, *difference)
+		}
+	}
+
+	if _, err := compareImages(gradient, image.NewRGBA(image.Rect(0, 0, 16, 16))); err == nil {
+		t.Fatalf("expected an error for images of different sizes")
+	}
+}
+
+// imageDifference is the result of comparing a rendered image with its golden image.
+type imageDifference struct {
+	// differentPixels is the fraction of pixels with a channel off by more than pixelTolerance.
+	differentPixels float64
+	// ssim is the mean structural similarity of the luma of both images, 1 for identical images.
+	ssim float64
+	// image shows the golden image faded to gray with the pixels over the tolerance in red.
+	image *image.RGBA
+}
+
+// passes is a method that tells whether the difference is small enough for the images to count as the same.
+func (d *imageDifference) passes() bool {
+	return d.differentPixels <= maxDifferentPixels && d.ssim >= minSSIM
+}
+
+// compareImages is a function that compares the rendered image with the expected one pixel by pixel and perceptually.
+func compareImages(expected, actual image.Image) (*imageDifference, error) {
+	bounds := expected.Bounds()
+	if bounds.Size() != actual.Bounds().Size() {
+		return nil, fmt.Errorf("expected an image of size %v but have %v", bounds.Size(), actual.Bounds().Size())
+	}
+
+	width, height := bounds.Dx(), bounds.Dy()
+	offset := actual.Bounds().Min.Sub(bounds.Min)
+	diff := image.NewRGBA(image.Rect(0, 0, width, height))
+	expectedLuma, actualLuma := make([]float64, width*height), make([]float64, width*height)
+	different := 0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := image.Pt(bounds.Min.X+x, bounds.Min.Y+y)
+			e, a := color.RGBAModel.Convert(expected.At(p.X, p.Y)).(color.RGBA), color.RGBAModel.Convert(actual.At(p.X+offset.X, p.Y+offset.Y)).(color.RGBA)
+			expectedLuma[y*width+x], actualLuma[y*width+x] = luma(e), luma(a)
+
+			gray := uint8(128 + expectedLuma[y*width+x]/4)
+			diff.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+
+			largest := maxChannelDifference(e, a)
+			if largest > pixelTolerance {
+				different++
+				diff.SetRGBA(x, y, color.RGBA{R: uint8(128 + largest/2), A: 255})
+			}
+		}
+	}
+
+	return &imageDifference{
+		differentPixels: float64(different) / float64(width*height),
+		ssim:            ssim(expectedLuma, actualLuma, width, height),
+		image:           diff,
+	}, nil
+}
+
+// luma is a function that returns the Rec. 601 luma of the color on a scale from 0 to 255.
+func luma(c color.RGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+// maxChannelDifference is a function that returns the largest difference between the channels of two colors.
+func maxChannelDifference(a, b color.RGBA) int {
+	largest := 0
+
+	for _, d := range [4]int{int(a.R) - int(b.R), int(a.G) - int(b.G), int(a.B) - int(b.B), int(a.A) - int(b.A)} {
+		if d < 0 {
+			d = -d
+		}
+
+		if d > largest {
+			largest = d
+		}
+	}
+
+	return largest
+}
+
+// ssim is a function that computes the mean structural similarity of two luma planes
+// over every ssimWindow-sized square window, following Wang et al. (2004).
+func ssim(a, b []float64, width, height int) float64 {
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+
+	window := ssimWindow
+	if width < window || height < window {
+		window = int(math.Min(float64(width), float64(height)))
+	}
+
+	n := float64(window * window)
+	sum, count := 0., 0
+
+	for y := 0; y+window <= height; y++ {
+		for x := 0; x+window <= width; x++ {
+			var meanA, meanB, varianceA, varianceB, covariance float64
+
+			for j := y; j < y+window; j++ {
+				for i := x; i < x+window; i++ {
+					meanA += a[j*width+i]
+					meanB += b[j*width+i]
+				}
+			}
+
+			meanA, meanB = meanA/n, meanB/n
+
+			for j := y; j < y+window; j++ {
+				for i := x; i < x+window; i++ {
+					da, db := a[j*width+i]-meanA, b[j*width+i]-meanB
+					varianceA += da * da
+					varianceB += db * db
+					covariance += da * db
+				}
+			}
+
+			varianceA, varianceB, covariance = varianceA/(n-1), varianceB/(n-1), covariance/(n-1)
+			sum += (2*meanA*meanB + c1) * (2*covariance + c2) / ((meanA*meanA + meanB*meanB + c1) * (varianceA + varianceB + c2))
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 1
+	}
+
+	return sum / float64(count)
+}
+
+// loadPNG is a function that decodes the PNG file with the given name.
+func loadPNG(name string) (image.Image, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := png.Decode(file)
+	_ = file.Close()
+
+	return img, err
+}
+
+// writeFailure is a function that saves the rendered image and its difference from the golden image next to each other.
+func writeFailure(name string, actual, diff image.Image) error {
+	if err := os.MkdirAll(failureDirectory, 0o755); err != nil {
+		return err
+	}
+
+	if err := render.WritePNG(filepath.Join(failureDirectory, name+".actual.png"), actual); err != nil {
+		return err
+	}
+
+	return render.WritePNG(filepath.Join(failureDirectory, name+".diff.png"), diff)
+}
@@ -0,0 +1,46 @@
+package render
+
+import "math"
+
+// haltonPrimes are the bases of the Halton dimensions; later dimensions fall back to random numbers,
+// because the points of larger bases are strongly correlated.
+var haltonPrimes = [...]uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53}
+
+// haltonSampler uses the sample index as the index into the Halton sequence and decorrelates the pixels
+// with a Cranley–Patterson rotation: every dimension of a pixel is shifted by its own random offset modulo 1.
+type haltonSampler struct {
+	seed      uint64
+	x, y      uint64
+	index     uint64
+	dimension int
+}
+
+func (s *haltonSampler) Start(x, y, index int) {
+	s.x, s.y, s.index, s.dimension = uint64(x), uint64(y), uint64(index), 0
+}
+
+func (s *haltonSampler) Float64() float64 {
+	d := s.dimension
+	s.dimension++
+
+	if d >= len(haltonPrimes) {
+		return hashFloat64(s.seed, s.x, s.y, s.index, uint64(d))
+	}
+
+	v := radicalInverse(haltonPrimes[d], s.index) + hashFloat64(s.seed, s.x, s.y, uint64(d))
+
+	return v - math.Floor(v)
+}
+
+// radicalInverse is a function that mirrors the digits of the index in the given base around the radix point,
+// so 1, 2, 3 in base 2 become 0.1, 0.01 and 0.11.
+func radicalInverse(base, index uint64) float64 {
+	inverse, scale := 0., 1.
+
+	for ; index > 0; index /= base {
+		scale /= float64(base)
+		inverse += float64(index%base) * scale
+	}
+
+	return math.Min(inverse, 1-1./(1<<53))
+}
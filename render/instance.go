@@ -0,0 +1,154 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// Instance places a shared geometry in the scene with its own affine transform.
+// Only the transforms and cached bounds are stored per instance, so a large geometry
+// can be placed many times without copying it.
+//
+// A moving instance interpolates between a start transform at time 0 and an end transform at time 1.
+// A transform that is not invertible, such as a scale of zero, flattens the geometry to nothing,
+// so the instance is never hit while it has one.
+type Instance struct {
+	geometry     Object
+	transform    linmath.Matrix4
+	inverse      *linmath.Matrix4 // nil when the transform is not invertible
+	normalMatrix *linmath.Matrix4 // inverse transpose, maps object space normals to world space
+	end          *linmath.Matrix4
+	objectBounds linmath.AABB
+	worldBounds  linmath.AABB
+	material     *Material // overrides the material of the geometry when not nil
+}
+
+func NewInstance(geometry Object, transform *linmath.Matrix4, material *Material) *Instance {
+	inverse, normalMatrix := invertTransform(transform)
+	objectBounds := geometry.Bounds()
+
+	return &Instance{
+		geometry:     geometry,
+		transform:    *transform,
+		inverse:      inverse,
+		normalMatrix: normalMatrix,
+		objectBounds: *objectBounds,
+		worldBounds:  *objectBounds.Transform(transform),
+		material:     material,
+	}
+}
+
+// NewMovingInstance is a function that returns an instance moving from the start to the end transform.
+// Every point moves along a straight line between its start and end positions, so the union
+// of the bounds at both ends encloses the whole motion.
+func NewMovingInstance(geometry Object, start, end *linmath.Matrix4, material *Material) *Instance {
+	i := NewInstance(geometry, start, material)
+	i.end = end
+	i.worldBounds = *i.worldBounds.Union(i.objectBounds.Transform(end))
+
+	return i
+}
+
+// invertTransform is a function that returns the inverse and normal matrices of a transform,
+// or nil matrices when the transform is not invertible.
+func invertTransform(transform *linmath.Matrix4) (inverse, normalMatrix *linmath.Matrix4) {
+	inverse, ok := transform.Inverse()
+	if !ok {
+		return nil, nil
+	}
+
+	return inverse, inverse.Transpose()
+}
+
+// transformsAt is a method that returns the inverse and normal matrices of the instance at the given time,
+// or nil matrices when its transform at that time is not invertible.
+func (i *Instance) transformsAt(time float64) (inverse, normalMatrix *linmath.Matrix4) {
+	if i.end == nil {
+		return i.inverse, i.normalMatrix
+	}
+
+	return invertTransform(i.transform.Lerp(i.end, math.Min(math.Max(time, 0), 1)))
+}
+
+// travel is a method that returns where the point of the instance at the time is at the times 0 and 1.
+func (i *Instance) travel(point *linmath.Vector3, time float64) (*linmath.Vector3, *linmath.Vector3) {
+	if i.end == nil {
+		return point, point
+	}
+
+	inverse, _ := i.transformsAt(time)
+	if inverse == nil {
+		return point, point
+	}
+
+	local := inverse.MultiplyPoint(point)
+
+	return i.transform.MultiplyPoint(local), i.end.MultiplyPoint(local)
+}
+
+// Intersect is a method that transforms the ray into object space and intersects it with the geometry.
+// The direction is not normalized, so t is the same in both spaces.
+func (i *Instance) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	if !i.worldBounds.IntersectRay(&ray.Origin, &ray.Direction, minT, maxT) {
+		return Hit{}, false
+	}
+
+	inverse, normalMatrix := i.transformsAt(ray.Time)
+	if inverse == nil {
+		return Hit{}, false
+	}
+
+	local := linmath.NewRay(inverse.MultiplyPoint(&ray.Origin), inverse.MultiplyDirection(&ray.Direction), ray.Time)
+
+	if !i.objectBounds.IntersectRay(&local.Origin, &local.Direction, minT, maxT) {
+		return Hit{}, false
+	}
+
+	h, ok := i.geometry.Intersect(local, minT, maxT)
+	if !ok {
+		return Hit{}, false
+	}
+
+	return i.toWorld(ray, h, normalMatrix), true
+}
+
+// Intervals is a method that transforms the ray into object space and returns the spans inside the geometry.
+// A geometry that is not a solid has no inside, so it reports no spans.
+func (i *Instance) Intervals(ray *linmath.Ray) []interval {
+	geometry, ok := i.geometry.(solid)
+	if !ok {
+		return nil
+	}
+
+	inverse, normalMatrix := i.transformsAt(ray.Time)
+	if inverse == nil {
+		return nil
+	}
+
+	local := linmath.NewRay(inverse.MultiplyPoint(&ray.Origin), inverse.MultiplyDirection(&ray.Direction), ray.Time)
+	intervals := geometry.Intervals(local)
+
+	for j := range intervals {
+		intervals[j].enter = i.toWorld(ray, intervals[j].enter, normalMatrix)
+		intervals[j].exit = i.toWorld(ray, intervals[j].exit, normalMatrix)
+	}
+
+	return intervals
+}
+
+// toWorld is a method that moves a hit found in object space back into world space.
+func (i *Instance) toWorld(ray *linmath.Ray, h Hit, normalMatrix *linmath.Matrix4) Hit {
+	h.Point = ray.At(h.T)
+	h.Normal = normalMatrix.MultiplyDirection(h.Normal).Normal()
+
+	if i.material != nil {
+		h.Material = i.material
+		h.Color = i.material.albedo(i.material.color, h.U, h.V)
+	}
+
+	return h
+}
+
+func (i *Instance) Bounds() *linmath.AABB {
+	return linmath.NewAABB(i.worldBounds.Min(), i.worldBounds.Max())
+}
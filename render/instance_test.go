@@ -0,0 +1,95 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestInstance(t *testing.T) {
+	// A unit sphere stretched twice as long along x, turned 30 degrees around z and moved five units away.
+	angle := linmath.Radians(30)
+	rotation := linmath.NewRotationZ(angle)
+	transform := linmath.NewTranslation(0, 0, 5).Multiply(rotation).Multiply(linmath.NewScale(2, 1, 1))
+	instance := NewInstance(NewSphere(*linmath.NewVector3(0, 0, 0), 1, Material{}), transform, nil)
+
+	// The turned ellipse x^2 / 4 + y^2 = 1 reaches sx along the x axis and sy along the y axis.
+	sin, cos := math.Sincos(angle)
+	sx := 1 / math.Sqrt(cos*cos/4+sin*sin)
+	sy := 1 / math.Sqrt(sin*sin/4+cos*cos)
+
+	// normal is a function that returns the world normal at a world point on the x-y plane through the center,
+	// the gradient of the ellipse taken back through the rotation.
+	normal := func(x, y float64) *linmath.Vector3 {
+		local := rotation.Transpose().MultiplyDirection(linmath.NewVector3(x, y, 0))
+		return rotation.MultiplyDirection(linmath.NewVector3(local.X()/4, local.Y(), 0)).Normal()
+	}
+
+	tests := []struct {
+		name           string
+		inputRay       *linmath.Ray
+		expectedHit    bool
+		expectedT      float64
+		expectedNormal *linmath.Vector3
+	}{
+		{"head on", linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 1), 0), true, 4, linmath.NewVector3(0, 0, -1)},
+		// The direction is not normalized and t stays a multiple of it.
+		{"long direction", linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 2), 0), true, 2, linmath.NewVector3(0, 0, -1)},
+		{"along the stretched axis", linmath.NewRay(linmath.NewVector3(-10, 0, 5), linmath.NewVector3(1, 0, 0), 0), true, 10 - sx, normal(-sx, 0)},
+		{"from above", linmath.NewRay(linmath.NewVector3(0, 10, 5), linmath.NewVector3(0, -1, 0), 0), true, 10 - sy, normal(0, sy)},
+		{"past the side", linmath.NewRay(linmath.NewVector3(0, 1.5, 0), linmath.NewVector3(0, 0, 1), 0), false, 0, nil},
+		{"inside the side", linmath.NewRay(linmath.NewVector3(0, 1, 0), linmath.NewVector3(0, 0, 1), 0), true, 0, nil},
+	}
+
+	for _, ts := range tests {
+		h, ok := instance.Intersect(ts.inputRay, 0, math.Inf(1))
+		if ok != ts.expectedHit {
+			t.Fatalf("%s: expected a hit [%v] but have [%v]", ts.name, ts.expectedHit, ok)
+		}
+
+		if !ok || ts.expectedNormal == nil {
+			continue
+		}
+
+		if math.Abs(h.T-ts.expectedT) > 1e-9 {
+			t.Fatalf("%s: expected t [%v] but have [%v]", ts.name, ts.expectedT, h.T)
+		}
+
+		if !equalVectors(h.Point, ts.inputRay.At(h.T)) || !equalVectors(h.Normal, ts.expectedNormal) {
+			t.Fatalf("%s: expected [%v %v] but have [%v %v]", ts.name, ts.inputRay.At(h.T), ts.expectedNormal, h.Point, h.Normal)
+		}
+	}
+
+	// The bounds enclose the turned box around the stretched sphere.
+	bounds := instance.Bounds()
+	extent := linmath.NewVector3(2*cos+sin, 2*sin+cos, 1)
+	expectedMin, expectedMax := linmath.NewVector3(0, 0, 5).Subtraction(extent), linmath.NewVector3(0, 0, 5).Add(extent)
+
+	if !equalVectors(bounds.Min(), expectedMin) || !equalVectors(bounds.Max(), expectedMax) {
+		t.Fatalf("expected bounds [%v %v] but have [%v %v]", expectedMin, expectedMax, bounds.Min(), bounds.Max())
+	}
+}
+
+func TestInstanceSingular(t *testing.T) {
+	sphere := NewSphere(*linmath.NewVector3(0, 0, 0), 1, Material{})
+	ray := linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 1), 0.5)
+
+	// The moving instance turns inside out, flattening to nothing halfway.
+	tests := []struct {
+		name          string
+		inputInstance *Instance
+	}{
+		{"scaled to nothing", NewInstance(sphere, linmath.NewTranslation(0, 0, 5).Multiply(linmath.NewScale(1, 1, 0)), nil)},
+		{"flattened while moving", NewMovingInstance(sphere, linmath.NewTranslation(0, 0, 5), linmath.NewTranslation(0, 0, 5).Multiply(linmath.NewScale(-1, 1, 1)), nil)},
+	}
+
+	for _, ts := range tests {
+		if _, ok := ts.inputInstance.Intersect(ray, 0, math.Inf(1)); ok {
+			t.Fatalf("%s: expected no hit", ts.name)
+		}
+
+		if intervals := ts.inputInstance.Intervals(ray); len(intervals) != 0 {
+			t.Fatalf("%s: expected no spans but have [%v]", ts.name, len(intervals))
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+type lightType int
+
+const (
+	ambientLight lightType = iota
+	pointLight
+	directionalLight
+)
+
+// Light is a light source shared by the ray tracer and the rasterizer.
+type Light struct {
+	lightType lightType
+	intensity float64
+	position  linmath.Vector3 // of a point light
+	direction linmath.Vector3 // of a directional light, pointing towards the light
+}
+
+func NewAmbientLight(intensity float64) *Light {
+	return &Light{lightType: ambientLight, intensity: intensity}
+}
+
+func NewPointLight(intensity float64, position *linmath.Vector3) *Light {
+	return &Light{lightType: pointLight, intensity: intensity, position: *position}
+}
+
+func NewDirectionalLight(intensity float64, direction *linmath.Vector3) *Light {
+	return &Light{lightType: directionalLight, intensity: intensity, direction: *direction}
+}
+
+// ComputeLighting is a function that computes the light intensity at a point with diffuse and specular reflection.
+// The view vector points from the point towards the viewer. Points are tested for shadows
+// against the objects, so the rasterizer, which has no shadows, passes none and no statistics.
+func ComputeLighting(point, normal, view *linmath.Vector3, specular float64, lights []Light, objects []Object, time float64, stats *Statistics) float64 {
+	return computeLighting(point, normal, view, specular, lights, objects, nil, time, nil, stats)
+}
+
+// computeLighting is a function that computes the light intensity at a point like ComputeLighting,
+// with the light that reaches it dimmed by the volumes it crosses, which may draw on the sampler.
+func computeLighting(point, normal, view *linmath.Vector3, specular float64, lights []Light, objects []Object, volumes []Volume, time float64, sampler Sampler, stats *Statistics) float64 {
+	ambient, diffuse, highlight := lightingTerms(point, normal, view, specular, lights, objects, volumes, time, sampler, stats)
+
+	return ambient + diffuse + highlight
+}
+
+// lightingTerms is a function that returns the ambient, diffuse and specular parts of the light intensity
+// computed by computeLighting.
+func lightingTerms(point, normal, view *linmath.Vector3, specular float64, lights []Light, objects []Object, volumes []Volume, time float64, sampler Sampler, stats *Statistics) (ambient, diffuse, highlight float64) {
+	for _, l := range lights {
+		if l.lightType == ambientLight {
+			ambient += l.intensity
+			continue
+		}
+
+		direction, maxT := l.toward(point)
+
+		visibility := lightVisibility(point, direction, maxT, objects, volumes, time, sampler, stats)
+		if visibility == 0 {
+			continue
+		}
+
+		// Diffuse
+		if nDotL := normal.Dot(direction); nDotL > 0 {
+			diffuse += visibility * l.intensity * nDotL / (normal.Length() * direction.Length())
+		}
+
+		// Specular
+		if specular != -1 {
+			reflected := ReflectRay(direction, normal)
+
+			if rDotV := reflected.Dot(view); rDotV > 0 {
+				highlight += visibility * l.intensity * math.Pow(rDotV/(reflected.Length()*view.Length()), specular)
+			}
+		}
+	}
+
+	return ambient, diffuse, highlight
+}
+
+// toward is a method that returns the direction from the point towards a point or directional light
+// and the parameter of a ray in that direction at which it reaches the light.
+func (l *Light) toward(point *linmath.Vector3) (*linmath.Vector3, float64) {
+	if l.lightType == pointLight {
+		return l.position.Subtraction(point), 1
+	}
+
+	return &l.direction, math.Inf(1)
+}
+
+// lightVisibility is a function that returns the share of light that reaches the point from the direction
+// up to maxT: none when an object is in the way, otherwise what the volumes in the way let through.
+func lightVisibility(point, direction *linmath.Vector3, maxT float64, objects []Object, volumes []Volume, time float64, sampler Sampler, stats *Statistics) float64 {
+	ray := linmath.NewRay(point, direction, time)
+
+	stats.countShadowRay()
+	if _, shadowed := ClosestIntersection(ray, shadowBias, maxT, objects, stats); shadowed {
+		return 0
+	}
+
+	visibility := 1.
+	for i := range volumes {
+		if t0, t1, ok := volumes[i].segment(ray, shadowBias, maxT); ok {
+			visibility *= volumes[i].transmittance(ray, t0, t1, sampler)
+		}
+	}
+
+	return visibility
+}
+
+// ReflectRay is a function that mirrors the vector around the normal.
+func ReflectRay(v, normal *linmath.Vector3) *linmath.Vector3 {
+	return normal.MultiplyOnScalar(2 * normal.Dot(v)).Subtraction(v)
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Material describes how a surface responds to light.
+type Material struct {
+	color      Color
+	specular   float64     // Phong exponent of the highlight, -1 for a matte surface
+	reflective float64     // share of the color that comes from the mirror reflection, from 0 to 1
+	texture    image.Image // multiplies the color when not nil, looked up by the UV of the hit
+	// normalMap holds tangent-space normals of a mesh with UVs, scaled in x and y by normalScale.
+	normalMap   image.Image
+	normalScale float64
+	// metallicRoughness holds the roughness in green and the metalness in blue, scaling the factors below;
+	// the ray tracer derives specular and reflective from them at every hit.
+	metallicRoughness   image.Image
+	metallic, roughness float64
+	// meanFreePath is how far light travels under the surface before it scatters; when it is positive
+	// the ray tracer scatters the light under the surface and the color is the color of the scattered light.
+	meanFreePath float64
+}
+
+func NewMaterial(color Color, specular, reflective float64, texture image.Image) *Material {
+	return &Material{color: color, specular: specular, reflective: reflective, texture: texture}
+}
+
+// NewSubsurfaceMaterial is a function that returns a translucent material like wax, soap or skin, whose light
+// spreads under the surface about the mean free path before it leaves in the subsurface color.
+// The rasterizer draws it as a plain surface of that color.
+func NewSubsurfaceMaterial(color Color, meanFreePath, specular float64) *Material {
+	return &Material{color: color, specular: specular, meanFreePath: meanFreePath}
+}
+
+// newPBRMaterial is a function that approximates a metallic-roughness material with the Phong model of the renderer.
+func newPBRMaterial(color Color, metallic, roughness float64, texture, metallicRoughness image.Image) *Material {
+	specular, reflective := phongFromPBR(metallic, roughness)
+
+	return &Material{
+		color:             color,
+		specular:          specular,
+		reflective:        reflective,
+		texture:           texture,
+		metallicRoughness: metallicRoughness,
+		metallic:          metallic,
+		roughness:         roughness,
+	}
+}
+
+// phongFromPBR is a function that converts a roughness to the Phong exponent of a highlight of about
+// the same width, using the relation of Walter et al. between Beckmann and Phong lobes, and lets smooth metals mirror.
+func phongFromPBR(metallic, roughness float64) (specular, reflective float64) {
+	alpha := roughness * roughness
+	specular = 1000
+
+	if alpha > 0 {
+		specular = math.Min(2/(alpha*alpha)-2, specular)
+	}
+
+	if specular < 1 {
+		specular = -1
+	}
+
+	return specular, metallic * (1 - roughness) * (1 - roughness)
+}
+
+// finish is a method that returns the Phong exponent and reflectivity of the surface at the texture coordinates.
+func (m *Material) finish(u, v float64) (specular, reflective float64) {
+	if m.metallicRoughness == nil {
+		return m.specular, m.reflective
+	}
+
+	_, g, b := texel(m.metallicRoughness, u, v)
+
+	return phongFromPBR(m.metallic*b, m.roughness*g)
+}
+
+// albedo is a method that returns the base color of the surface at the texture coordinates,
+// tinting the given color, which is the material color or an interpolated vertex color, with the texture.
+func (m *Material) albedo(base Color, u, v float64) Color {
+	if m.texture == nil {
+		return base
+	}
+
+	r, g, b := texel(m.texture, u, v)
+
+	return Color{
+		uint8(float64(base.r) * r),
+		uint8(float64(base.g) * g),
+		uint8(float64(base.b) * b),
+		base.a,
+	}
+}
+
+// texel is a function that returns the channels of the texel at the texture coordinates, from 0 to 1,
+// repeating the texture in both directions.
+func texel(texture image.Image, u, v float64) (r, g, b float64) {
+	bounds := texture.Bounds()
+	x := bounds.Min.X + wrap(int(math.Floor(u*float64(bounds.Dx()))), bounds.Dx())
+	y := bounds.Min.Y + wrap(int(math.Floor(v*float64(bounds.Dy()))), bounds.Dy())
+	r16, g16, b16, _ := texture.At(x, y).RGBA()
+
+	return float64(r16) / 0xffff, float64(g16) / 0xffff, float64(b16) / 0xffff
+}
+
+// wrap is a function that repeats a texel index over a texture of size n.
+func wrap(i, n int) int {
+	if i %= n; i < 0 {
+		i += n
+	}
+
+	return i
+}
+
+// NewCheckerTexture is a function that returns a texture of squares x squares black and white cells of size pixels each.
+func NewCheckerTexture(size, squares int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size*squares, size*squares))
+
+	for y := 0; y < size*squares; y++ {
+		for x := 0; x < size*squares; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if (x/size+y/size)%2 == 1 {
+				c = color.NRGBA{R: 64, G: 64, B: 64, A: 255}
+			}
+
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
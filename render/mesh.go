@@ -0,0 +1,318 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"github.com/UnTea/ComputerGraphics/mesh"
+	"math"
+)
+
+// Mesh is an indexed triangle mesh. Triangles are wound so that (v1 - v0) x (v2 - v0) points out of the surface.
+type Mesh struct {
+	vertices  []linmath.Vector3
+	normals   []linmath.Vector3 // per vertex, the face normal is used when empty
+	uvs       [][2]float64      // per vertex texture coordinates, optional
+	tangents  []linmath.Vector4 // per vertex direction of increasing u and, in w, the sign of the bitangent; optional
+	colors    []Color           // per vertex, the material color is used when empty
+	triangles [][3]int
+	polygons  [][]int // faces as the file lists them before they are split into triangles, optional
+	material  Material
+	bounds    linmath.AABB
+}
+
+func NewMesh(vertices, normals []linmath.Vector3, uvs [][2]float64, colors []Color, triangles [][3]int, material Material) *Mesh {
+	bounds := linmath.NewEmptyAABB()
+	for i := range vertices {
+		bounds = bounds.Extend(&vertices[i])
+	}
+
+	return &Mesh{vertices, normals, uvs, nil, colors, triangles, nil, material, *bounds}
+}
+
+// NormalMode selects how a loaded mesh is shaded.
+type NormalMode int
+
+const (
+	SmoothNormals NormalMode = iota // normals of the file, or averaged over the triangles around each vertex
+	FlatNormals                     // the normal of each triangle, so the facets show
+)
+
+// newLoadedMesh is a function that returns a mesh read from a file with the normals the mode asks for.
+// Smooth normals missing from the file are computed.
+func newLoadedMesh(vertices, normals []linmath.Vector3, colors []Color, triangles [][3]int, material Material, mode NormalMode) *Mesh {
+	switch {
+	case mode == FlatNormals:
+		normals = nil
+	case len(normals) != len(vertices):
+		smooth, origins := (&mesh.Mesh{Vertices: vertices, Triangles: triangles}).GenerateNormals(math.Pi)
+		vertices, normals, triangles = smooth.Vertices, smooth.Normals, smooth.Triangles
+
+		if len(colors) > 0 {
+			carried := make([]Color, len(origins))
+			for i, o := range origins {
+				carried[i] = colors[o]
+			}
+
+			colors = carried
+		}
+	}
+
+	return NewMesh(vertices, normals, nil, colors, triangles, material)
+}
+
+// SubdivisionScheme selects how Subdivide refines a mesh.
+type SubdivisionScheme int
+
+const (
+	LoopSubdivision         SubdivisionScheme = iota // every triangle split into four, for triangle meshes
+	CatmullClarkSubdivision                          // every face split into quads, for quad meshes
+)
+
+// SubdivisionSchemes maps the names of the subdivision schemes to their values, for command line flags.
+var SubdivisionSchemes = map[string]SubdivisionScheme{
+	"loop":          LoopSubdivision,
+	"catmull-clark": CatmullClarkSubdivision,
+}
+
+// Subdivide is a method that returns the mesh smoothed by levels of subdivision of the scheme, with creases where its
+// faces meet at more than creaseAngle radians and along its boundary. Catmull–Clark subdivides the faces of the file
+// the mesh was read from, so the quads of a quad mesh stay quads, or the triangles of a mesh without them.
+// Texture coordinates, tangents and colors are interpolated like the positions. A smooth shaded mesh gets its normals
+// generated again with the same crease angle, so sharp edges stay sharp in the shading too. Vertices split along
+// texture seams make seams creases. Subdivide before the mesh is placed in a scene: the bounds are computed
+// for the finer triangles.
+func (m *Mesh) Subdivide(scheme SubdivisionScheme, levels int, creaseAngle float64) *Mesh {
+	var fine *mesh.Mesh
+	var weights [][]mesh.Weight
+
+	switch scheme {
+	case CatmullClarkSubdivision:
+		faces := m.polygons
+		if faces == nil {
+			faces = make([][]int, len(m.triangles))
+			for i, t := range m.triangles {
+				faces[i] = []int{t[0], t[1], t[2]}
+			}
+		}
+
+		fine, weights = mesh.CatmullClark(m.vertices, faces, levels, creaseAngle)
+	default:
+		fine, weights = (&mesh.Mesh{Vertices: m.vertices, Triangles: m.triangles}).Loop(levels, creaseAngle)
+	}
+
+	var normals []linmath.Vector3
+	if len(m.normals) > 0 {
+		var origins []int
+		fine, origins = fine.GenerateNormals(creaseAngle)
+		normals = fine.Normals
+
+		// Vertices split at creases take the weights of the vertex they were split from.
+		split := make([][]mesh.Weight, len(origins))
+		for i, o := range origins {
+			split[i] = weights[o]
+		}
+
+		weights = split
+	}
+
+	var uvs [][2]float64
+	if len(m.uvs) > 0 {
+		uvs = make([][2]float64, len(weights))
+		for i, ws := range weights {
+			for _, w := range ws {
+				uvs[i][0] += w.Weight * m.uvs[w.Vertex][0]
+				uvs[i][1] += w.Weight * m.uvs[w.Vertex][1]
+			}
+		}
+	}
+
+	var tangents []linmath.Vector4
+	if len(m.tangents) > 0 {
+		tangents = make([]linmath.Vector4, len(weights))
+		for i, ws := range weights {
+			var x, y, z, sign float64
+			for _, w := range ws {
+				t := &m.tangents[w.Vertex]
+				x, y, z, sign = x+w.Weight*t.X(), y+w.Weight*t.Y(), z+w.Weight*t.Z(), sign+w.Weight*t.W()
+			}
+
+			tangents[i] = *linmath.NewVector4(x, y, z, math.Copysign(1, sign))
+		}
+	}
+
+	var colors []Color
+	if len(m.colors) > 0 {
+		colors = make([]Color, len(weights))
+		for i, ws := range weights {
+			var r, g, b, a float64
+			for _, w := range ws {
+				c := m.colors[w.Vertex]
+				r, g, b, a = r+w.Weight*float64(c.r), g+w.Weight*float64(c.g), b+w.Weight*float64(c.b), a+w.Weight*float64(c.a)
+			}
+
+			colors[i] = Color{clampChannel(r), clampChannel(g), clampChannel(b), clampChannel(a)}
+		}
+	}
+
+	result := NewMesh(fine.Vertices, normals, uvs, colors, fine.Triangles, m.material)
+	result.tangents = tangents
+
+	return result
+}
+
+// Intersect is a method that tests the ray against every triangle and keeps the nearest hit.
+func (m *Mesh) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	if !m.bounds.IntersectRay(&ray.Origin, &ray.Direction, minT, maxT) {
+		return Hit{}, false
+	}
+
+	closest, closestU, closestV := -1, 0., 0.
+
+	for i := range m.triangles {
+		if t, u, v, ok := m.intersectTriangle(ray, i); ok && minT < t && t < maxT {
+			maxT, closest, closestU, closestV = t, i, u, v
+		}
+	}
+
+	if closest < 0 {
+		return Hit{}, false
+	}
+
+	return m.hit(ray, maxT, closest, closestU, closestV), true
+}
+
+// intersectTriangle is a method that intersects the ray with the plane of a triangle
+// using the Möller–Trumbore algorithm. Returns t and the barycentric coordinates of v1 and v2.
+func (m *Mesh) intersectTriangle(ray *linmath.Ray, triangle int) (t, u, v float64, ok bool) {
+	v0, v1, v2 := m.corners(triangle)
+	edge1 := v1.Subtraction(v0)
+	edge2 := v2.Subtraction(v0)
+
+	p := ray.Direction.Cross(edge2)
+	determinant := edge1.Dot(p)
+
+	if math.Abs(determinant) < 1e-12 {
+		return 0, 0, 0, false
+	}
+
+	invert := 1. / determinant
+	s := ray.Origin.Subtraction(v0)
+
+	u = s.Dot(p) * invert
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+
+	q := s.Cross(edge1)
+
+	v = ray.Direction.Dot(q) * invert
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+
+	return edge2.Dot(q) * invert, u, v, true
+}
+
+func (m *Mesh) corners(triangle int) (v0, v1, v2 *linmath.Vector3) {
+	t := m.triangles[triangle]
+
+	return &m.vertices[t[0]], &m.vertices[t[1]], &m.vertices[t[2]]
+}
+
+func (m *Mesh) hit(ray *linmath.Ray, t float64, triangle int, u, v float64) Hit {
+	indices := m.triangles[triangle]
+	w := 1 - u - v
+
+	var normal *linmath.Vector3
+	if len(m.normals) > 0 {
+		normal = m.normals[indices[0]].MultiplyOnScalar(w).
+			Add(m.normals[indices[1]].MultiplyOnScalar(u)).
+			Add(m.normals[indices[2]].MultiplyOnScalar(v)).
+			Normal()
+	} else {
+		v0, v1, v2 := m.corners(triangle)
+		normal = v1.Subtraction(v0).Cross(v2.Subtraction(v0)).Normal()
+	}
+
+	color := m.material.color
+	if len(m.colors) > 0 {
+		c0, c1, c2 := m.colors[indices[0]], m.colors[indices[1]], m.colors[indices[2]]
+		color = Color{
+			uint8(w*float64(c0.r) + u*float64(c1.r) + v*float64(c2.r) + 0.5),
+			uint8(w*float64(c0.g) + u*float64(c1.g) + v*float64(c2.g) + 0.5),
+			uint8(w*float64(c0.b) + u*float64(c1.b) + v*float64(c2.b) + 0.5),
+			255,
+		}
+	}
+
+	var textureU, textureV float64
+	if len(m.uvs) > 0 {
+		uv0, uv1, uv2 := m.uvs[indices[0]], m.uvs[indices[1]], m.uvs[indices[2]]
+		textureU = w*uv0[0] + u*uv1[0] + v*uv2[0]
+		textureV = w*uv0[1] + u*uv1[1] + v*uv2[1]
+
+		if m.material.normalMap != nil {
+			normal = m.mapNormal(triangle, normal, w, u, v, textureU, textureV)
+		}
+	}
+
+	return Hit{t, ray.At(t), normal, textureU, textureV, m.material.albedo(color, textureU, textureV), &m.material}
+}
+
+// mapNormal is a method that bends the interpolated normal by the normal map of the material. The tangent frame
+// comes from the vertex tangents, or from how the UVs stretch across the triangle when the mesh has none.
+func (m *Mesh) mapNormal(triangle int, normal *linmath.Vector3, w, u, v, textureU, textureV float64) *linmath.Vector3 {
+	indices := m.triangles[triangle]
+
+	var tangent *linmath.Vector3
+	var sign float64
+
+	if len(m.tangents) > 0 {
+		t0, t1, t2 := &m.tangents[indices[0]], &m.tangents[indices[1]], &m.tangents[indices[2]]
+		tangent = linmath.NewVector3(
+			w*t0.X()+u*t1.X()+v*t2.X(),
+			w*t0.Y()+u*t1.Y()+v*t2.Y(),
+			w*t0.Z()+u*t1.Z()+v*t2.Z(),
+		)
+		sign = t0.W()
+	} else {
+		v0, v1, v2 := m.corners(triangle)
+		uv0, uv1, uv2 := m.uvs[indices[0]], m.uvs[indices[1]], m.uvs[indices[2]]
+		edge1, edge2 := v1.Subtraction(v0), v2.Subtraction(v0)
+		du1, dv1, du2, dv2 := uv1[0]-uv0[0], uv1[1]-uv0[1], uv2[0]-uv0[0], uv2[1]-uv0[1]
+
+		determinant := du1*dv2 - du2*dv1
+		if math.Abs(determinant) < 1e-12 {
+			return normal
+		}
+
+		tangent = edge1.MultiplyOnScalar(dv2 / determinant).Subtraction(edge2.MultiplyOnScalar(dv1 / determinant))
+		bitangent := edge2.MultiplyOnScalar(du1 / determinant).Subtraction(edge1.MultiplyOnScalar(du2 / determinant))
+
+		sign = 1
+		if normal.Cross(tangent).Dot(bitangent) < 0 {
+			sign = -1
+		}
+	}
+
+	// Gram–Schmidt keeps the frame orthonormal where the interpolated normal and tangent drift apart.
+	tangent = tangent.Subtraction(normal.MultiplyOnScalar(normal.Dot(tangent)))
+	if tangent.Length() < 1e-12 {
+		return normal
+	}
+
+	tangent = tangent.Normal()
+	bitangent := normal.Cross(tangent).MultiplyOnScalar(sign)
+
+	r, g, b := texel(m.material.normalMap, textureU, textureV)
+	x, y, z := (2*r-1)*m.material.normalScale, (2*g-1)*m.material.normalScale, 2*b-1
+
+	return tangent.MultiplyOnScalar(x).Add(bitangent.MultiplyOnScalar(y)).Add(normal.MultiplyOnScalar(z)).Normal()
+}
+
+func (m *Mesh) Bounds() *linmath.AABB {
+	return linmath.NewAABB(m.bounds.Min(), m.bounds.Max())
+}
+
+func (m *Mesh) Tessellate() *Mesh {
+	return m
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"bytes"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMeshSubdivide(t *testing.T) {
+	material := *NewMaterial(*NewColor(128, 128, 128, 255), -1, 0, nil)
+
+	pyramid, err := DecodePLY(bytes.NewReader(asciiPLY()), material, SmoothNormals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		inputCrease      float64
+		expectedVertices int
+		expectedFlatBase bool
+	}{
+		// The pyramid has 5 vertices and 9 edges, the first level adds a vertex on each edge and makes 36 edges.
+		{"smooth", math.Pi, 5 + 9 + 36, false},
+		// The sides meet at 60 degrees and the base at 135: creasing the rim splits its 16 vertices
+		// into one for the base and one for the sides.
+		{"creased", linmath.Radians(90), 5 + 9 + 36 + 16, true},
+	}
+
+	for _, ts := range tests {
+		fine := pyramid.Subdivide(LoopSubdivision, 2, ts.inputCrease)
+
+		if len(fine.triangles) != 16*len(pyramid.triangles) || len(fine.vertices) != ts.expectedVertices {
+			t.Fatalf("%s: expected [%v] triangles and [%v] vertices but have [%v] and [%v]", ts.name, 16*len(pyramid.triangles), ts.expectedVertices, len(fine.triangles), len(fine.vertices))
+		}
+
+		if len(fine.normals) != len(fine.vertices) || len(fine.colors) != len(fine.vertices) {
+			t.Fatalf("%s: expected a normal and a color for each vertex but have [%v] and [%v]", ts.name, len(fine.normals), len(fine.colors))
+		}
+
+		// The apex is lowered towards the base, but a ray straight down still hits it from above.
+		h, ok := fine.Intersect(linmath.NewRay(linmath.NewVector3(0, 5, 0), linmath.NewVector3(0, -1, 0), 0), 0, math.Inf(1))
+		if !ok || h.Point.Y() >= 1 || h.Point.Y() <= 0 || !equalVectors(h.Normal, linmath.NewVector3(0, 1, 0)) {
+			t.Fatalf("%s: expected to hit the rounded apex facing up but have [%v] [%v]", ts.name, ok, h)
+		}
+
+		// The creased base stays flat while the smooth one bulges.
+		if bounds := fine.Bounds(); (math.Abs(bounds.Min().Y()) < 1e-9) != ts.expectedFlatBase {
+			t.Fatalf("%s: expected a flat base [%v] but have [%v]", ts.name, ts.expectedFlatBase, bounds)
+		}
+	}
+}
+
+func TestMeshSubdivideCatmullClark(t *testing.T) {
+	// A cube from 0 to 1 made of six quads, wound counterclockwise seen from outside.
+	cube := `ply
+format ascii 1.0
+element vertex 8
+property float x
+property float y
+property float z
+element face 6
+property list uchar int vertex_indices
+end_header
+0 0 0
+1 0 0
+1 1 0
+0 1 0
+0 0 1
+1 0 1
+1 1 1
+0 1 1
+4 0 3 2 1
+4 4 5 6 7
+4 0 1 5 4
+4 2 3 7 6
+4 1 2 6 5
+4 0 4 7 3
+`
+
+	m, err := DecodePLY(strings.NewReader(cube), *NewMaterial(*NewColor(128, 128, 128, 255), -1, 0, nil), SmoothNormals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Catmull–Clark splits every quad into four, adding a vertex on each face and edge, where Loop splits its triangles.
+	tests := []struct {
+		name              string
+		inputScheme       SubdivisionScheme
+		expectedVertices  int
+		expectedTriangles int
+	}{
+		{"catmull-clark", CatmullClarkSubdivision, 8 + 6 + 12, 6 * 4 * 2},
+		{"loop", LoopSubdivision, 8 + 18, 12 * 4},
+	}
+
+	for _, ts := range tests {
+		fine := m.Subdivide(ts.inputScheme, 1, math.Pi)
+
+		if len(fine.vertices) != ts.expectedVertices || len(fine.triangles) != ts.expectedTriangles {
+			t.Fatalf("%s: expected [%v] vertices and [%v] triangles but have [%v] and [%v]", ts.name, ts.expectedVertices, ts.expectedTriangles, len(fine.vertices), len(fine.triangles))
+		}
+	}
+
+	// A corner of the cube moves to 5/9 of the way from the center to where it was.
+	fine := m.Subdivide(CatmullClarkSubdivision, 1, math.Pi)
+	if expected := linmath.NewVector3(0.5-2.5/9, 0.5-2.5/9, 0.5-2.5/9); !equalVectors(&fine.vertices[0], expected) {
+		t.Fatalf("expected [%v] but have [%v]", expected, fine.vertices[0])
+	}
+}
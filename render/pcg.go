@@ -0,0 +1,36 @@
+package render
+
+import "math/bits"
+
+// PCG is the PCG32 generator of O'Neill (2014): a 64-bit linear congruential state whose output
+// is permuted by a xorshift and a random rotation. Generators with different streams are independent.
+type PCG struct {
+	state     uint64
+	increment uint64
+}
+
+// NewPCG is a function that returns a generator seeded the same way as pcg32_srandom_r of the reference implementation.
+func NewPCG(seed, stream uint64) *PCG {
+	p := &PCG{increment: stream<<1 | 1}
+	p.Uint32()
+	p.state += seed
+	p.Uint32()
+
+	return p
+}
+
+// Uint32 is a method that returns the next 32 random bits.
+func (p *PCG) Uint32() uint32 {
+	old := p.state
+	p.state = old*6364136223846793005 + p.increment
+
+	shifted := uint32(((old >> 18) ^ old) >> 27)
+	rotation := int(old >> 59)
+
+	return bits.RotateLeft32(shifted, -rotation)
+}
+
+// Float64 is a method that returns a random number in [0, 1) with 32 bits of precision.
+func (p *PCG) Float64() float64 {
+	return float64(p.Uint32()) / (1 << 32)
+}
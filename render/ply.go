@@ -0,0 +1,311 @@
+package render
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// plyProperty is a property of a PLY element. A list property has the type of its count and of its items.
+type plyProperty struct {
+	name      string
+	kind      string
+	list      bool
+	countKind string
+}
+
+// plyElement is an element of a PLY header, such as vertex or face, with the number of them in the body.
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// plySizes are the sizes in bytes of the scalar types of PLY, by both of their names.
+var plySizes = map[string]int{
+	"char": 1, "int8": 1, "uchar": 1, "uint8": 1,
+	"short": 2, "int16": 2, "ushort": 2, "uint16": 2,
+	"int": 4, "int32": 4, "uint": 4, "uint32": 4,
+	"float": 4, "float32": 4, "double": 8, "float64": 8,
+}
+
+// LoadPLY is a function that reads a mesh from a PLY file.
+func LoadPLY(name string, material Material, normals NormalMode) (*Mesh, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return DecodePLY(file, material, normals)
+}
+
+// DecodePLY is a function that reads a mesh from PLY data, ASCII or binary of either byte order.
+// Vertices take their position, normal and color (red, green, blue) properties; faces with more than
+// three vertices are split into fans of triangles, and kept whole for Catmull–Clark subdivision.
+// Other elements and properties are skipped.
+// Coordinates are used as they are, so the mesh may need an Instance to be placed in the scene.
+func DecodePLY(r io.Reader, material Material, normals NormalMode) (*Mesh, error) {
+	reader := bufio.NewReader(r)
+
+	format, elements, err := readPLYHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var read func(kind string) (float64, error)
+	switch format {
+	case "ascii":
+		scanner := bufio.NewScanner(reader)
+		scanner.Split(bufio.ScanWords)
+
+		read = func(string) (float64, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return 0, err
+				}
+
+				return 0, io.ErrUnexpectedEOF
+			}
+
+			return strconv.ParseFloat(scanner.Text(), 64)
+		}
+	case "binary_little_endian":
+		read = plyBinaryReader(reader, binary.LittleEndian)
+	case "binary_big_endian":
+		read = plyBinaryReader(reader, binary.BigEndian)
+	default:
+		return nil, fmt.Errorf("ply: unknown format %q", format)
+	}
+
+	var vertices, fileNormals []linmath.Vector3
+	var colors []Color
+	var triangles [][3]int
+	var polygons [][]int
+
+	for _, e := range elements {
+		values := map[string]float64{}
+		var indices []int
+
+		for i := 0; i < e.count; i++ {
+			// Only the vertex indices make triangles; other lists of the face, like texture coordinates, are skipped.
+			indices = indices[:0]
+
+			for _, p := range e.properties {
+				if !p.list {
+					if values[p.name], err = read(p.kind); err != nil {
+						return nil, plyError(e, i, err)
+					}
+
+					continue
+				}
+
+				count, err := read(p.countKind)
+				if err != nil {
+					return nil, plyError(e, i, err)
+				}
+
+				for j := 0; j < int(count); j++ {
+					v, err := read(p.kind)
+					if err != nil {
+						return nil, plyError(e, i, err)
+					}
+
+					if p.name == "vertex_indices" || p.name == "vertex_index" {
+						indices = append(indices, int(v))
+					}
+				}
+			}
+
+			switch e.name {
+			case "vertex":
+				vertices = append(vertices, *linmath.NewVector3(values["x"], values["y"], values["z"]))
+
+				if e.has("nx", "ny", "nz") {
+					fileNormals = append(fileNormals, *linmath.NewVector3(values["nx"], values["ny"], values["nz"]).Normal())
+				}
+
+				if e.has("red", "green", "blue") {
+					colors = append(colors, *NewColor(
+						plyColor(values["red"], e.kind("red")),
+						plyColor(values["green"], e.kind("green")),
+						plyColor(values["blue"], e.kind("blue")),
+						255,
+					))
+				}
+			case "face":
+				polygons = append(polygons, append([]int(nil), indices...))
+
+				for j := 1; j+1 < len(indices); j++ {
+					triangles = append(triangles, [3]int{indices[0], indices[j], indices[j+1]})
+				}
+			}
+		}
+	}
+
+	for _, t := range triangles {
+		for _, i := range t {
+			if i < 0 || i >= len(vertices) {
+				return nil, fmt.Errorf("ply: face refers to vertex %d of %d", i, len(vertices))
+			}
+		}
+	}
+
+	m := newLoadedMesh(vertices, fileNormals, colors, triangles, material, normals)
+	m.polygons = polygons
+
+	return m, nil
+}
+
+// readPLYHeader is a function that reads the header up to end_header and returns the format and the elements.
+func readPLYHeader(reader *bufio.Reader) (string, []plyElement, error) {
+	var format string
+	var elements []plyElement
+
+	for first := true; ; first = false {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", nil, fmt.Errorf("ply: reading the header: %w", err)
+		}
+
+		fields := strings.Fields(line)
+
+		if first {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return "", nil, errors.New("ply: not a PLY file")
+			}
+
+			continue
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return "", nil, errors.New("ply: malformed format line")
+			}
+
+			format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return "", nil, fmt.Errorf("ply: malformed element line %q", strings.TrimSpace(line))
+			}
+
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return "", nil, fmt.Errorf("ply: malformed element count %q", fields[2])
+			}
+
+			elements = append(elements, plyElement{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return "", nil, errors.New("ply: property before any element")
+			}
+
+			var p plyProperty
+			switch {
+			case len(fields) == 3:
+				p = plyProperty{name: fields[2], kind: fields[1]}
+			case len(fields) == 5 && fields[1] == "list":
+				p = plyProperty{name: fields[4], kind: fields[3], list: true, countKind: fields[2]}
+			default:
+				return "", nil, fmt.Errorf("ply: malformed property line %q", strings.TrimSpace(line))
+			}
+
+			if plySizes[p.kind] == 0 || (p.list && plySizes[p.countKind] == 0) {
+				return "", nil, fmt.Errorf("ply: unknown type in %q", strings.TrimSpace(line))
+			}
+
+			e := &elements[len(elements)-1]
+			e.properties = append(e.properties, p)
+		case "end_header":
+			return format, elements, nil
+		}
+	}
+}
+
+// has is a method that tells whether the element has all the scalar properties.
+func (e *plyElement) has(names ...string) bool {
+	for _, name := range names {
+		if e.kind(name) == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// kind is a method that returns the type of a scalar property, or an empty string when the element has no such property.
+func (e *plyElement) kind(name string) string {
+	for _, p := range e.properties {
+		if p.name == name && !p.list {
+			return p.kind
+		}
+	}
+
+	return ""
+}
+
+// plyColor is a function that converts a color channel to 8 bits: floating point channels go from 0 to 1
+// and integer ones over the range of their type.
+func plyColor(value float64, kind string) uint8 {
+	switch kind {
+	case "float", "float32", "double", "float64":
+		value *= 255
+	case "ushort", "uint16":
+		value /= 257
+	}
+
+	return uint8(math.Min(math.Max(value, 0), 255) + 0.5)
+}
+
+// plyError is a function that says where in the body the data ended or went wrong.
+func plyError(e plyElement, i int, err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return fmt.Errorf("ply: %s %d: %w", e.name, i, err)
+}
+
+// plyBinaryReader is a function that returns a reader of the scalar types of PLY in the byte order.
+func plyBinaryReader(r io.Reader, order binary.ByteOrder) func(kind string) (float64, error) {
+	var buffer [8]byte
+
+	return func(kind string) (float64, error) {
+		b := buffer[:plySizes[kind]]
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, err
+		}
+
+		switch kind {
+		case "char", "int8":
+			return float64(int8(b[0])), nil
+		case "uchar", "uint8":
+			return float64(b[0]), nil
+		case "short", "int16":
+			return float64(int16(order.Uint16(b))), nil
+		case "ushort", "uint16":
+			return float64(order.Uint16(b)), nil
+		case "int", "int32":
+			return float64(int32(order.Uint32(b))), nil
+		case "uint", "uint32":
+			return float64(order.Uint32(b)), nil
+		case "float", "float32":
+			return float64(math.Float32frombits(order.Uint32(b))), nil
+		default:
+			return math.Float64frombits(order.Uint64(b)), nil
+		}
+	}
+}
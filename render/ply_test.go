@@ -0,0 +1,214 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// plyPyramid is the header of a square pyramid with colored vertices: a quad base and four triangle sides.
+const plyPyramid = `ply
+format %s 1.0
+comment a square pyramid
+element vertex 5
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+element face 5
+property list uchar int vertex_indices
+element edge 1
+property int vertex1
+property int vertex2
+end_header
+`
+
+// plyPyramidVertices and plyPyramidFaces are the body of plyPyramid. The faces are wound
+// counterclockwise seen from outside, which the renderer takes as their front.
+var (
+	plyPyramidVertices = [][6]float64{
+		{-1, 0, -1, 255, 0, 0},
+		{1, 0, -1, 0, 255, 0},
+		{1, 0, 1, 0, 0, 255},
+		{-1, 0, 1, 255, 255, 255},
+		{0, 1, 0, 0, 0, 0},
+	}
+	plyPyramidFaces = [][]int{{0, 1, 2, 3}, {0, 4, 1}, {1, 4, 2}, {2, 4, 3}, {3, 4, 0}}
+)
+
+// binaryPLY is a function that encodes the pyramid in a binary PLY file of the given byte order.
+func binaryPLY(t *testing.T, format string, order binary.ByteOrder) []byte {
+	var b bytes.Buffer
+	b.WriteString(strings.Replace(plyPyramid, "%s", format, 1))
+
+	write := func(v interface{}) {
+		if err := binary.Write(&b, order, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, v := range plyPyramidVertices {
+		write([3]float32{float32(v[0]), float32(v[1]), float32(v[2])})
+		write([3]uint8{uint8(v[3]), uint8(v[4]), uint8(v[5])})
+	}
+
+	for _, f := range plyPyramidFaces {
+		write(uint8(len(f)))
+		for _, i := range f {
+			write(int32(i))
+		}
+	}
+
+	write([2]int32{0, 1})
+
+	return b.Bytes()
+}
+
+// asciiPLY is a function that encodes the pyramid in an ASCII PLY file.
+func asciiPLY() []byte {
+	var b strings.Builder
+	b.WriteString(strings.Replace(plyPyramid, "%s", "ascii", 1))
+
+	for _, v := range plyPyramidVertices {
+		for _, c := range v {
+			b.WriteString(strconv.FormatFloat(c, 'g', -1, 64) + " ")
+		}
+
+		b.WriteString("\n")
+	}
+
+	for _, f := range plyPyramidFaces {
+		b.WriteString(strconv.Itoa(len(f)))
+		for _, i := range f {
+			b.WriteString(" " + strconv.Itoa(i))
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString("0 1\n")
+
+	return []byte(b.String())
+}
+
+func TestDecodePLY(t *testing.T) {
+	files := map[string][]byte{
+		"ascii":         asciiPLY(),
+		"little endian": binaryPLY(t, "binary_little_endian", binary.LittleEndian),
+		"big endian":    binaryPLY(t, "binary_big_endian", binary.BigEndian),
+	}
+
+	material := *NewMaterial(*NewColor(128, 128, 128, 255), -1, 0, nil)
+
+	for name, data := range files {
+		mesh, err := DecodePLY(bytes.NewReader(data), material, SmoothNormals)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// The quad base is split into two triangles.
+		if len(mesh.vertices) != 5 || len(mesh.triangles) != 6 {
+			t.Fatalf("%s: expected [5] vertices and [6] triangles but have [%v] and [%v]", name, len(mesh.vertices), len(mesh.triangles))
+		}
+
+		if mesh.colors[1] != *NewColor(0, 255, 0, 255) || mesh.colors[3] != *NewColor(255, 255, 255, 255) {
+			t.Fatalf("%s: unexpected colors [%v]", name, mesh.colors)
+		}
+
+		// The apex is shared by the four sides only, so its smooth normal points straight up.
+		if !equalVectors(&mesh.normals[4], linmath.NewVector3(0, 1, 0)) {
+			t.Fatalf("%s: expected the apex normal [0 1 0] but have [%v]", name, mesh.normals[4])
+		}
+
+		// A corner of the base leans out and down.
+		if n := mesh.normals[0]; n.X() >= 0 || n.Y() >= 0 || n.Z() >= 0 {
+			t.Fatalf("%s: expected the corner normal to point out of the pyramid but have [%v]", name, n)
+		}
+
+		flat, err := DecodePLY(bytes.NewReader(data), material, FlatNormals)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		h, ok := flat.Intersect(linmath.NewRay(linmath.NewVector3(0.2, 0.3, -5), linmath.NewVector3(0, 0, 1), 0), 0, math.Inf(1))
+		if !ok || len(flat.normals) != 0 {
+			t.Fatalf("%s: expected a hit with flat normals", name)
+		}
+
+		// The side facing -Z leans towards -Z and up.
+		expected := linmath.NewVector3(0, 1, -1).Normal()
+		if !equalVectors(h.Normal, expected) {
+			t.Fatalf("%s: expected [%v] but have [%v]", name, expected, h.Normal)
+		}
+	}
+}
+
+func TestDecodePLYFaceLists(t *testing.T) {
+	vertices := "ply\nformat ascii 1.0\nelement vertex 4\nproperty float x\nproperty float y\nproperty float z\nelement face 1\n"
+	body := "0 0 0\n1 0 0\n1 1 0\n0 1 0\n"
+
+	// MeshLab writes the texture coordinates of every corner of a face as another list.
+	tests := []struct {
+		name      string
+		inputData string
+		expected  [][3]int
+	}{
+		{
+			"indices only",
+			vertices + "property list uchar int vertex_indices\nend_header\n" + body + "4 0 1 2 3\n",
+			[][3]int{{0, 1, 2}, {0, 2, 3}},
+		},
+		{
+			"texture coordinates after the indices",
+			vertices + "property list uchar int vertex_indices\nproperty list uchar float texcoord\nend_header\n" + body + "4 0 1 2 3 8 0 0 1 0 1 1 0 1\n",
+			[][3]int{{0, 1, 2}, {0, 2, 3}},
+		},
+		{
+			"texture coordinates before the indices",
+			vertices + "property list uchar float texcoord\nproperty list uchar int vertex_indices\nend_header\n" + body + "8 0 0 1 0 1 1 0 1 4 0 1 2 3\n",
+			[][3]int{{0, 1, 2}, {0, 2, 3}},
+		},
+	}
+
+	for _, ts := range tests {
+		mesh, err := DecodePLY(strings.NewReader(ts.inputData), Material{}, FlatNormals)
+		if err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+
+		if !reflect.DeepEqual(mesh.triangles, ts.expected) {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expected, mesh.triangles)
+		}
+	}
+}
+
+func TestDecodePLYErrors(t *testing.T) {
+	complete := asciiPLY()
+
+	tests := []struct {
+		name          string
+		inputData     string
+		expectedError string
+	}{
+		{"not ply", "obj\n", "not a PLY file"},
+		{"no end", "ply\nformat ascii 1.0\n", "header"},
+		{"unknown format", "ply\nformat binary_middle_endian 1.0\nend_header\n", "unknown format"},
+		{"unknown type", "ply\nformat ascii 1.0\nelement vertex 1\nproperty quad x\nend_header\n", "unknown type"},
+		{"truncated", string(complete[:len(complete)-20]), "unexpected EOF"},
+		{"bad index", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0\n3 0 1 2\n", "vertex 1 of 1"},
+	}
+
+	for _, ts := range tests {
+		_, err := DecodePLY(strings.NewReader(ts.inputData), Material{}, SmoothNormals)
+		if err == nil || !strings.Contains(err.Error(), ts.expectedError) {
+			t.Fatalf("%s: expected an error containing [%v] but have [%v]", ts.name, ts.expectedError, err)
+		}
+	}
+}
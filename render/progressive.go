@@ -0,0 +1,164 @@
+package render
+
+import (
+	"context"
+	"image"
+	"math"
+	"sync"
+	"time"
+)
+
+type renderState string
+
+const (
+	renderRunning  renderState = "rendering"
+	renderDone     renderState = "done"
+	renderCanceled renderState = "canceled"
+)
+
+// progress is a snapshot of how far a progressive render has come.
+type progress struct {
+	Pass     int         `json:"pass"` // passes completed so far, each one adds a sample to every pixel
+	Passes   int         `json:"passes"`
+	Fraction float64     `json:"fraction"`
+	Elapsed  float64     `json:"elapsedSeconds"`
+	ETA      float64     `json:"etaSeconds"` // estimated time left, -1 until the first row is done
+	State    renderState `json:"state"`
+}
+
+// progressiveRender ray traces an image in passes of one sample per pixel and keeps the running average in a canvas,
+// so a preview is available long before the last sample. It is safe to read while it renders.
+type progressiveRender struct {
+	scene         *Scene
+	width, height int
+	passes        int
+	sampler       Sampler
+	stats         Statistics // written only by the rendering goroutine, read once it is done
+
+	mu      sync.Mutex
+	sums    []float64 // red, green and blue sums of the samples of each pixel in image order
+	canvas  *Canvas
+	pass    int
+	rows    int // rows of the current pass that are done
+	started time.Time
+	elapsed time.Duration // set once the render stops
+	state   renderState
+}
+
+// newProgressiveRender is a function that prepares a render of the size of the options with a pass for each of their samples.
+// A camera without an aperture or an open shutter sees the same image in every pass, so unless volumes are sampled it gets only one.
+func newProgressiveRender(scene *Scene, options *Options) *progressiveRender {
+	options = options.withDefaults()
+	canvas := NewCanvas(options.Width, options.Height, false)
+	canvas.Clear(&backgroundColor)
+
+	return &progressiveRender{
+		scene:   scene,
+		width:   options.Width,
+		height:  options.Height,
+		passes:  scene.samples(options.Samples),
+		sampler: NewSampler(options.Sampler, options.Seed),
+		sums:    make([]float64, 3*options.Width*options.Height),
+		canvas:  canvas,
+		started: time.Now(),
+		state:   renderRunning,
+	}
+}
+
+// Run is a method that renders every pass unless the context is canceled first; it stops between rows.
+func (p *progressiveRender) Run(ctx context.Context) {
+	p.mu.Lock()
+	p.started = time.Now()
+	p.mu.Unlock()
+
+	row := make([]float64, 3*p.width)
+
+	for pass := 0; pass < p.passes; pass++ {
+		for iy := 0; iy < p.height; iy++ {
+			if ctx.Err() != nil {
+				p.stop(renderCanceled)
+				return
+			}
+
+			y := p.height - iy - 1
+			for x := 0; x < p.width; x++ {
+				p.stats.countPrimaryRay()
+				p.sampler.Start(x, iy, pass)
+				ray := p.scene.Camera.Ray(float64(x)-float64(p.width)/2, float64(y)-float64(p.height)/2, p.width, p.height, p.sampler)
+				sample := TraceRay(ray, 0., math.Inf(1), p.scene, recursionDepth, p.sampler, &p.stats).Color
+				row[3*x], row[3*x+1], row[3*x+2] = float64(sample.r), float64(sample.g), float64(sample.b)
+			}
+
+			p.addRow(iy, row, pass+1)
+		}
+	}
+
+	p.stop(renderDone)
+}
+
+// addRow is a method that adds a row of samples to the sums and shows the new average of the row.
+func (p *progressiveRender) addRow(iy int, row []float64, samples int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sums := p.sums[3*iy*p.width : 3*(iy+1)*p.width]
+	for x := 0; x < p.width; x++ {
+		sums[3*x] += row[3*x]
+		sums[3*x+1] += row[3*x+1]
+		sums[3*x+2] += row[3*x+2]
+
+		n := float64(samples)
+		p.canvas.set(x, iy, NewColor(clampChannel(sums[3*x]/n), clampChannel(sums[3*x+1]/n), clampChannel(sums[3*x+2]/n), 255))
+	}
+
+	if p.rows++; p.rows == p.height {
+		p.pass, p.rows = p.pass+1, 0
+	}
+}
+
+func (p *progressiveRender) stop(state renderState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state, p.elapsed = state, time.Since(p.started)
+	p.stats.addPhase("progressive ray tracing", p.started)
+}
+
+// Image is a method that returns a copy of the current average.
+func (p *progressiveRender) Image() image.Image {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.canvas.Image()
+}
+
+// Progress is a method that returns how much of the render is done, with the time left estimated
+// from the average time per row so far.
+func (p *progressiveRender) Progress() progress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elapsed := p.elapsed
+	if p.state == renderRunning {
+		elapsed = time.Since(p.started)
+	}
+
+	fraction := (float64(p.pass) + float64(p.rows)/float64(p.height)) / float64(p.passes)
+
+	eta := -1.
+	switch {
+	case p.state != renderRunning:
+		eta = 0
+	case fraction > 0:
+		eta = elapsed.Seconds() * (1 - fraction) / fraction
+	}
+
+	return progress{
+		Pass:     p.pass,
+		Passes:   p.passes,
+		Fraction: fraction,
+		Elapsed:  elapsed.Seconds(),
+		ETA:      eta,
+		State:    p.state,
+	}
+}
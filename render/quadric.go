@@ -0,0 +1,163 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// Quadric is the surface where p^T Q p = 0 for the homogeneous points p = (x, y, z, 1) and a symmetric matrix Q,
+// which covers ellipsoids, paraboloids, hyperboloids, cones and cylinders. The inside is where p^T Q p < 0.
+// Most quadrics are unbounded, so the surface is clipped to a box, leaving open ones open.
+// The matrix is kept relative to a center, so that quadrics far from the origin don't lose precision.
+type Quadric struct {
+	center   linmath.Vector3
+	matrix   linmath.Matrix4
+	bounds   linmath.AABB
+	material Material
+}
+
+// NewQuadric is a function that returns the quadric of the matrix clipped to the bounds.
+// Only the symmetric part of the matrix counts, so it is symmetrized.
+func NewQuadric(matrix *linmath.Matrix4, bounds *linmath.AABB, material Material) *Quadric {
+	center := bounds.Center()
+	forth := linmath.NewTranslation(center.X(), center.Y(), center.Z())
+
+	return newQuadric(*center, forth.Transpose().Multiply(matrix).Multiply(forth), bounds, material)
+}
+
+// newQuadric is a function that returns the quadric of a matrix relative to the center.
+func newQuadric(center linmath.Vector3, matrix *linmath.Matrix4, bounds *linmath.AABB, material Material) *Quadric {
+	var rows [4][4]float64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			rows[i][j] = (matrix.At(i, j) + matrix.At(j, i)) / 2
+		}
+	}
+
+	return &Quadric{center, *linmath.NewMatrix4(rows), *bounds, material}
+}
+
+// NewEllipsoid is a function that returns an ellipsoid with the radii along the axes.
+func NewEllipsoid(center linmath.Vector3, radii linmath.Vector3, material Material) *Quadric {
+	matrix := linmath.NewMatrix4([4][4]float64{
+		{1 / (radii.X() * radii.X()), 0, 0, 0},
+		{0, 1 / (radii.Y() * radii.Y()), 0, 0},
+		{0, 0, 1 / (radii.Z() * radii.Z()), 0},
+		{0, 0, 0, -1},
+	})
+
+	return newQuadric(center, matrix, linmath.NewAABB(center.Subtraction(&radii), center.Add(&radii)), material)
+}
+
+// NewParaboloid is a function that returns a paraboloid with its vertex at the center opening up along +Y,
+// as wide as the radius at the height where it is cut off: (x^2 + z^2) / radius^2 = y / height.
+func NewParaboloid(center linmath.Vector3, radius, height float64, material Material) *Quadric {
+	matrix := linmath.NewMatrix4([4][4]float64{
+		{1 / (radius * radius), 0, 0, 0},
+		{0, 0, 0, -1 / (2 * height)},
+		{0, 0, 1 / (radius * radius), 0},
+		{0, -1 / (2 * height), 0, 0},
+	})
+
+	bounds := linmath.NewAABB(center.Add(linmath.NewVector3(-radius, 0, -radius)), center.Add(linmath.NewVector3(radius, height, radius)))
+
+	return newQuadric(center, matrix, bounds, material)
+}
+
+// NewHyperboloid is a function that returns a hyperboloid around the Y axis, x^2 + z^2 - (slope y)^2 = waist |waist|,
+// cut off at the height above and below the center. A positive waist is the radius of the narrowest circle of
+// a hyperboloid of one sheet. A negative one makes a hyperboloid of two sheets, whose tips are -waist / slope
+// above and below the center. A waist of 0 is a double cone.
+func NewHyperboloid(center linmath.Vector3, waist, slope, height float64, material Material) *Quadric {
+	matrix := linmath.NewMatrix4([4][4]float64{
+		{1, 0, 0, 0},
+		{0, -slope * slope, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, -waist * math.Abs(waist)},
+	})
+
+	radius := math.Sqrt(math.Max(slope*slope*height*height+waist*math.Abs(waist), 0))
+	bounds := linmath.NewAABB(center.Add(linmath.NewVector3(-radius, -height, -radius)), center.Add(linmath.NewVector3(radius, height, radius)))
+
+	return newQuadric(center, matrix, bounds, material)
+}
+
+// Intersect is a method that solves the quadratic of the ray and keeps the nearest root inside the bounds.
+// Like for spheres, the ray starts from its point closest to the center, which keeps the coefficients small.
+func (q *Quadric) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	start, end, ok := q.bounds.ClipRay(&ray.Origin, &ray.Direction, minT, maxT)
+	if !ok {
+		return Hit{}, false
+	}
+
+	d := &ray.Direction
+	closest := q.center.Subtraction(&ray.Origin).Dot(d) / d.Dot(d)
+	o := ray.At(closest).Subtraction(&q.center)
+
+	origin := [4]float64{o.X(), o.Y(), o.Z(), 1}
+	direction := [4]float64{d.X(), d.Y(), d.Z(), 0}
+
+	a := q.form(direction, direction)
+	b := 2 * q.form(origin, direction)
+	c := q.form(origin, origin)
+
+	t0, t1, ok := linmath.SolveQuadratic(a, b, c)
+	if !ok {
+		return Hit{}, false
+	}
+
+	// Rounding may put a root on the boundary just outside the clipped span, so the span is widened a little.
+	margin := 1e-9 * math.Max(1, math.Abs(end))
+
+	for _, t := range [2]float64{closest + t0, closest + t1} {
+		if minT < t && t < maxT && start-margin <= t && t <= end+margin {
+			return q.hit(ray, t), true
+		}
+	}
+
+	return Hit{}, false
+}
+
+// form is a method that returns u^T Q v for points and directions relative to the center.
+func (q *Quadric) form(u, v [4]float64) float64 {
+	var sum float64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			sum += u[i] * q.matrix.At(i, j) * v[j]
+		}
+	}
+
+	return sum
+}
+
+// hit is a method that describes the hit at t. The normal is the gradient Q p, pointing out of the inside.
+// Texture coordinates wrap around the center like on a sphere.
+func (q *Quadric) hit(ray *linmath.Ray, t float64) Hit {
+	point := ray.At(t)
+	local := point.Subtraction(&q.center)
+	p := [4]float64{local.X(), local.Y(), local.Z(), 1}
+
+	var gradient [3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 4; j++ {
+			gradient[i] += q.matrix.At(i, j) * p[j]
+		}
+	}
+
+	normal := linmath.NewVector3(gradient[0], gradient[1], gradient[2])
+	if normal.Length() > 0 {
+		normal = normal.Normal()
+	}
+
+	if local.Length() > 0 {
+		local = local.Normal()
+	}
+
+	u, v := sphereUV(local)
+
+	return Hit{t, point, normal, u, v, q.material.albedo(q.material.color, u, v), &q.material}
+}
+
+func (q *Quadric) Bounds() *linmath.AABB {
+	return linmath.NewAABB(q.bounds.Min(), q.bounds.Max())
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestQuadricIntersect(t *testing.T) {
+	material := *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil)
+	ellipsoid := NewEllipsoid(*linmath.NewVector3(0, 0, 0), *linmath.NewVector3(1, 2, 3), material)
+	distantEllipsoid := NewEllipsoid(*linmath.NewVector3(0, 0, 1e6), *linmath.NewVector3(1, 1, 1), material)
+	paraboloid := NewParaboloid(*linmath.NewVector3(0, 0, 0), 1, 1, material)
+	oneSheet := NewHyperboloid(*linmath.NewVector3(0, 0, 0), 1, 1, 2, material)
+	twoSheets := NewHyperboloid(*linmath.NewVector3(0, 0, 0), -1, 1, 2, material)
+	cone := NewHyperboloid(*linmath.NewVector3(0, 0, 0), 0, 1, 2, material)
+
+	tests := []struct {
+		name           string
+		inputRay       *linmath.Ray
+		inputQuadric   *Quadric
+		inputMinT      float64
+		expectedOk     bool
+		expectedT      float64
+		expectedNormal *linmath.Vector3
+	}{
+		{"ellipsoid", linmath.NewRay(linmath.NewVector3(0, 0, -5), linmath.NewVector3(0, 0, 1), 0), ellipsoid, 0, true, 2, linmath.NewVector3(0, 0, -1)},
+		{"ellipsoid from below", linmath.NewRay(linmath.NewVector3(0, -5, 0), linmath.NewVector3(0, 1, 0), 0), ellipsoid, 0, true, 3, linmath.NewVector3(0, -1, 0)},
+		{"ellipsoid from the surface", linmath.NewRay(linmath.NewVector3(0, 0, -3), linmath.NewVector3(0, 0, 1), 0), ellipsoid, shadowBias, true, 6, linmath.NewVector3(0, 0, 1)},
+		{"ellipsoid leaving the surface", linmath.NewRay(linmath.NewVector3(0, 0, -3), linmath.NewVector3(0, 0, -1), 0), ellipsoid, shadowBias, false, 0, nil},
+		{"ellipsoid tangent", linmath.NewRay(linmath.NewVector3(1, 0, -5), linmath.NewVector3(0, 0, 1), 0), ellipsoid, 0, true, 5, linmath.NewVector3(1, 0, 0)},
+		{"ellipsoid grazing distant", linmath.NewRay(linmath.NewVector3(0.999999, 0, 0), linmath.NewVector3(0, 0, 1), 0), distantEllipsoid, 0, true, 1e6 - math.Sqrt(1-0.999999*0.999999), nil},
+		{"paraboloid", linmath.NewRay(linmath.NewVector3(0.5, 5, 0), linmath.NewVector3(0, -1, 0), 0), paraboloid, 0, true, 4.75, linmath.NewVector3(1, -1, 0).Normal()},
+		// Along the axis the quadratic term vanishes and a single root is left.
+		{"paraboloid along the axis", linmath.NewRay(linmath.NewVector3(0, 5, 0), linmath.NewVector3(0, -1, 0), 0), paraboloid, 0, true, 5, linmath.NewVector3(0, -1, 0)},
+		{"paraboloid cut off", linmath.NewRay(linmath.NewVector3(1.5, 5, 0), linmath.NewVector3(0, -1, 0), 0), paraboloid, 0, false, 0, nil},
+		{"paraboloid from inside", linmath.NewRay(linmath.NewVector3(0, 0.5, 0), linmath.NewVector3(1, 0, 0), 0), paraboloid, 0, true, math.Sqrt(0.5), linmath.NewVector3(math.Sqrt(0.5), -0.5, 0).Normal()},
+		{"one sheet", linmath.NewRay(linmath.NewVector3(-5, 0, 0), linmath.NewVector3(1, 0, 0), 0), oneSheet, 0, true, 4, linmath.NewVector3(-1, 0, 0)},
+		{"one sheet higher", linmath.NewRay(linmath.NewVector3(-5, 1, 0), linmath.NewVector3(1, 0, 0), 0), oneSheet, 0, true, 5 - math.Sqrt(2), nil},
+		// The inside of a hyperboloid of two sheets is within the sheets, so the normal at the tip points to the center.
+		{"two sheets", linmath.NewRay(linmath.NewVector3(0, 5, 0), linmath.NewVector3(0, -1, 0), 0), twoSheets, 0, true, 4, linmath.NewVector3(0, -1, 0)},
+		{"between two sheets", linmath.NewRay(linmath.NewVector3(-5, 0, 0), linmath.NewVector3(1, 0, 0), 0), twoSheets, 0, false, 0, nil},
+		{"cone", linmath.NewRay(linmath.NewVector3(-5, 1, 0), linmath.NewVector3(1, 0, 0), 0), cone, 0, true, 4, linmath.NewVector3(-1, -1, 0).Normal()},
+	}
+
+	for _, ts := range tests {
+		h, ok := ts.inputQuadric.Intersect(ts.inputRay, ts.inputMinT, math.Inf(1))
+
+		if ok != ts.expectedOk {
+			t.Fatalf("%s: expected [%v] but have [%v] at [%v]", ts.name, ts.expectedOk, ok, h.T)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if math.Abs(h.T-ts.expectedT) > 1e-9 {
+			t.Fatalf("%s: expected t [%v] but have [%v]", ts.name, ts.expectedT, h.T)
+		}
+
+		// The hit point has to lie on the surface, where the quadratic form is zero.
+		local := h.Point.Subtraction(&ts.inputQuadric.center)
+		p := [4]float64{local.X(), local.Y(), local.Z(), 1}
+		if value := ts.inputQuadric.form(p, p); math.Abs(value) > 1e-9 {
+			t.Fatalf("%s: expected the hit point on the surface but the form is [%v]", ts.name, value)
+		}
+
+		if ts.expectedNormal != nil && h.Normal.Subtraction(ts.expectedNormal).Length() > 1e-9 {
+			t.Fatalf("%s: expected normal [%v] but have [%v]", ts.name, ts.expectedNormal, h.Normal)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// maxSamples is the number of pixels medianCut looks at; larger inputs are sampled on a regular stride.
+const maxSamples = 1 << 18
+
+// colorBox is a set of colors that median cut keeps splitting until there are enough of them.
+type colorBox struct {
+	colors [][3]uint8
+}
+
+// extent is a method that returns the channel with the widest range of values in the box and that range.
+func (b *colorBox) extent() (channel, width int) {
+	for c := 0; c < 3; c++ {
+		low, high := 255, 0
+
+		for _, v := range b.colors {
+			low, high = minInt(low, int(v[c])), maxInt(high, int(v[c]))
+		}
+
+		if high-low > width {
+			channel, width = c, high-low
+		}
+	}
+
+	return channel, width
+}
+
+// average is a method that returns the mean color of the box, which represents it in the palette.
+func (b *colorBox) average() color.Color {
+	var sum [3]int
+	for _, v := range b.colors {
+		sum[0], sum[1], sum[2] = sum[0]+int(v[0]), sum[1]+int(v[1]), sum[2]+int(v[2])
+	}
+
+	n := len(b.colors)
+
+	return color.NRGBA{R: uint8((sum[0] + n/2) / n), G: uint8((sum[1] + n/2) / n), B: uint8((sum[2] + n/2) / n), A: 255}
+}
+
+// MedianCut is a function that builds a palette of at most size opaque colors for the images with the median cut algorithm:
+// starting from a box with every color, the box with the widest channel is split at the median of that channel until
+// there are size boxes, and each box contributes its mean color. Sharing one palette keeps the colors of an animation steady.
+func MedianCut(images []image.Image, size int) color.Palette {
+	var colors [][3]uint8
+
+	total := 0
+	for _, img := range images {
+		total += img.Bounds().Dx() * img.Bounds().Dy()
+	}
+
+	stride := total/maxSamples + 1
+	i := 0
+
+	for _, img := range images {
+		bounds := img.Bounds()
+
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if i++; i%stride != 0 {
+					continue
+				}
+
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				colors = append(colors, [3]uint8{c.R, c.G, c.B})
+			}
+		}
+	}
+
+	if len(colors) == 0 {
+		return color.Palette{color.NRGBA{A: 255}}
+	}
+
+	boxes := []*colorBox{{colors}}
+
+	for len(boxes) < size {
+		widest, widestWidth, widestChannel := -1, 0, 0
+
+		for j, b := range boxes {
+			if len(b.colors) < 2 {
+				continue
+			}
+
+			if channel, width := b.extent(); width > widestWidth {
+				widest, widestWidth, widestChannel = j, width, channel
+			}
+		}
+
+		// Every box holds a single color, so no split would add a new one.
+		if widest < 0 {
+			break
+		}
+
+		b := boxes[widest]
+		sort.Slice(b.colors, func(j, k int) bool {
+			return b.colors[j][widestChannel] < b.colors[k][widestChannel]
+		})
+
+		// Split at the median, moved to the end of a run of equal values so that no color lands in both halves.
+		median := len(b.colors) / 2
+		for median < len(b.colors) && b.colors[median][widestChannel] == b.colors[median-1][widestChannel] {
+			median++
+		}
+
+		if median == len(b.colors) {
+			median = len(b.colors) / 2
+			for b.colors[median-1][widestChannel] == b.colors[median][widestChannel] {
+				median--
+			}
+		}
+
+		boxes[widest] = &colorBox{b.colors[:median]}
+		boxes = append(boxes, &colorBox{b.colors[median:]})
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for j, b := range boxes {
+		palette[j] = b.average()
+	}
+
+	return palette
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
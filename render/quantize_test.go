@@ -0,0 +1,46 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestMedianCut(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	colors := []color.NRGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 255, 255}}
+
+	for i := 0; i < 16; i++ {
+		img.SetNRGBA(i%4, i/4, colors[i%len(colors)])
+	}
+
+	tests := []struct {
+		inputSize    int
+		expectedSize int
+		expectExact  bool
+	}{
+		{256, 4, true},
+		{4, 4, true},
+		{2, 2, false},
+		{1, 1, false},
+	}
+
+	for _, ts := range tests {
+		palette := MedianCut([]image.Image{img}, ts.inputSize)
+
+		if len(palette) != ts.expectedSize {
+			t.Fatalf("expected [%v] colors but have [%v]", ts.expectedSize, len(palette))
+		}
+
+		if !ts.expectExact {
+			continue
+		}
+
+		// With enough room every color of the image has an exact entry.
+		for _, c := range colors {
+			if palette.Convert(c) != c {
+				t.Fatalf("expected [%v] in palette [%v]", c, palette)
+			}
+		}
+	}
+}
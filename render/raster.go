@@ -0,0 +1,445 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+const (
+	nearPlane = 0.1
+	farPlane  = 1000.
+)
+
+// tessellator is an object that the rasterizer can draw because it can approximate itself with triangles.
+type tessellator interface {
+	Tessellate() *Mesh
+}
+
+type ShadingMode int
+
+const (
+	FlatShading    ShadingMode = iota // lighting once per triangle with the face normal
+	GouraudShading                    // lighting per vertex, interpolated across the triangle
+	PhongShading                      // lighting per pixel with interpolated normals, like the ray tracer
+)
+
+// ShadingModes maps the names of the shading modes to their values, for command line flags.
+var ShadingModes = map[string]ShadingMode{
+	"flat":    FlatShading,
+	"gouraud": GouraudShading,
+	"phong":   PhongShading,
+}
+
+// Vertex attributes interpolated across triangles.
+const (
+	attributeRed = iota // vertex or material color, before texturing
+	attributeGreen
+	attributeBlue
+	attributeU
+	attributeV
+	attributeX // world space position
+	attributeY
+	attributeZ
+	attributeNormalX // world space normal
+	attributeNormalY
+	attributeNormalZ
+	attributeIntensity // light intensity of flat and Gouraud shading
+	attributeCount
+)
+
+// clipPlanes are the six planes of the view frustum in clip space; a point v is inside when plane.Dot(v) >= 0.
+var clipPlanes = [6]*linmath.Vector4{
+	linmath.NewVector4(1, 0, 0, 1),
+	linmath.NewVector4(-1, 0, 0, 1),
+	linmath.NewVector4(0, 1, 0, 1),
+	linmath.NewVector4(0, -1, 0, 1),
+	linmath.NewVector4(0, 0, 1, 0),
+	linmath.NewVector4(0, 0, -1, 1),
+}
+
+// clipVertex is a vertex in clip space with the attributes interpolated across its triangle.
+type clipVertex struct {
+	position   *linmath.Vector4
+	attributes []float64
+}
+
+func (v clipVertex) lerp(v2 clipVertex, t float64) clipVertex {
+	return clipVertex{v.position.Lerp(v2.position, t), lerpAttributes(make([]float64, len(v.attributes)), v.attributes, v2.attributes, t)}
+}
+
+// screenVertex is a vertex in image coordinates. Its attributes are divided by z and invZ is 1/z,
+// because, unlike the attributes themselves, these vary linearly across the screen. Dividing
+// the interpolated attributes by the interpolated invZ makes the interpolation perspective-correct.
+type screenVertex struct {
+	x, y       float64
+	invZ       float64
+	attributes []float64
+}
+
+func (v screenVertex) lerp(v2 screenVertex, t float64) screenVertex {
+	return screenVertex{
+		v.x + (v2.x-v.x)*t,
+		v.y + (v2.y-v.y)*t,
+		v.invZ + (v2.invZ-v.invZ)*t,
+		lerpAttributes(make([]float64, len(v.attributes)), v.attributes, v2.attributes, t),
+	}
+}
+
+func lerpAttributes(destination, a, b []float64, t float64) []float64 {
+	for i := range destination {
+		destination[i] = a[i] + (b[i]-a[i])*t
+	}
+
+	return destination
+}
+
+// shader is a function that computes the color of a pixel from its interpolated attributes.
+type shader func(attributes []float64) Color
+
+// rasterizer draws triangle meshes with a depth buffer, as an alternative to the ray tracer.
+// It sees the scene through the same camera and lights, but as a pinhole at the moment the shutter opens,
+// without shadows or reflections.
+type rasterizer struct {
+	canvas     *Canvas
+	depth      []float64 // 1/z of the closest surface drawn so far, 0 where nothing was drawn yet
+	view       linmath.Matrix4
+	projection linmath.Matrix4
+	frustum    linmath.Frustum // in world space, to discard objects before they are tessellated
+	eye        linmath.Vector3
+	lights     []Light
+	time       float64
+	options    *Options
+	stats      *Statistics
+	objects    int       // top level objects of the scene
+	finished   int       // top level objects drawn or culled so far
+	fragment   []float64 // attributes of the pixel being shaded, reused to avoid allocations
+}
+
+// Rasterize is a function that draws the scene seen by its camera into the canvas.
+// Objects that cannot be tessellated, such as CSG trees, are skipped and counted in the statistics,
+// and objects outside the view frustum are skipped too.
+// It reports progress after every object and stops early with the error of the context once it is canceled.
+func Rasterize(ctx context.Context, scene *Scene, canvas *Canvas, options *Options, stats *Statistics) error {
+	camera := scene.Camera
+	view, ok := linmath.NewTranslation(camera.position.X(), camera.position.Y(), camera.position.Z()).
+		Multiply(&camera.rotation).
+		Inverse()
+	if !ok {
+		return errors.New("render: camera transform is not invertible")
+	}
+
+	aspect, zoom := float64(canvas.width)/float64(canvas.height), camera.zoom()
+	projection := linmath.NewPerspective(projectionPlaneZ, viewportSize*aspect*zoom, viewportSize*zoom, nearPlane, farPlane)
+
+	r := &rasterizer{
+		canvas:     canvas,
+		depth:      make([]float64, canvas.width*canvas.height),
+		view:       *view,
+		projection: *projection,
+		frustum:    *linmath.NewFrustum(projection.Multiply(view)),
+		eye:        camera.position,
+		lights:     scene.Lights,
+		time:       camera.shutterOpen,
+		options:    options,
+		stats:      stats,
+		objects:    len(scene.Objects),
+		fragment:   make([]float64, attributeCount),
+	}
+
+	canvas.Clear(&backgroundColor)
+
+	return r.drawNode(ctx, newCullingNode(scene.Objects), linmath.Intersecting)
+}
+
+func (r *rasterizer) finishObjects(n int) {
+	r.finished += n
+	r.options.reportProgress(float64(r.finished) / float64(r.objects))
+}
+
+// drawObject is a method that draws an object placed in the world by the model matrix.
+// Unless the object is known to be inside the frustum, its bounds are tested first, and
+// so are the bounds of every nested instance, so only the visible parts are tessellated.
+func (r *rasterizer) drawObject(o Object, model *linmath.Matrix4, material *Material, classification linmath.Classification) {
+	classification = r.cull(o.Bounds().Transform(model), classification)
+	if classification == linmath.Outside {
+		return
+	}
+
+	switch o := o.(type) {
+	case *Instance:
+		transform := &o.transform
+		if o.end != nil {
+			transform = o.transform.Lerp(o.end, math.Min(math.Max(r.time, 0), 1))
+		}
+
+		// The outermost instance material wins, as it does in the ray tracer.
+		if material == nil {
+			material = o.material
+		}
+
+		r.drawObject(o.geometry, model.Multiply(transform), material, classification)
+	case tessellator:
+		r.drawMesh(o.Tessellate(), model, material, classification != linmath.Inside)
+	default:
+		r.stats.countSkippedObject()
+	}
+}
+
+// drawMesh is a method that draws the triangles of a mesh. Clipping may be skipped for a mesh entirely inside the frustum.
+func (r *rasterizer) drawMesh(m *Mesh, model *linmath.Matrix4, material *Material, clip bool) {
+	if material == nil {
+		material = &m.material
+	}
+
+	inverse, ok := model.Inverse()
+	if !ok {
+		return
+	}
+
+	normalMatrix := inverse.Transpose()
+	modelView := r.view.Multiply(model)
+
+	world := make([]*linmath.Vector3, len(m.vertices))
+	view := make([]*linmath.Vector3, len(m.vertices))
+	normals := make([]*linmath.Vector3, len(m.normals))
+
+	for i := range m.vertices {
+		world[i] = model.MultiplyPoint(&m.vertices[i])
+		view[i] = modelView.MultiplyPoint(&m.vertices[i])
+	}
+
+	for i := range m.normals {
+		normals[i] = normalMatrix.MultiplyDirection(&m.normals[i]).Normal()
+	}
+
+	for _, t := range m.triangles {
+		// The camera sits at the origin of view space, so a triangle facing away from it is hidden.
+		v0, v1, v2 := view[t[0]], view[t[1]], view[t[2]]
+		if v1.Subtraction(v0).Cross(v2.Subtraction(v0)).Dot(v0) >= 0 {
+			continue
+		}
+
+		r.stats.countTriangle()
+		w0, w1, w2 := world[t[0]], world[t[1]], world[t[2]]
+		faceNormal := w1.Subtraction(w0).Cross(w2.Subtraction(w0)).Normal()
+
+		var flatIntensity float64
+		if r.options.Shading == FlatShading {
+			centroid := w0.Add(w1).Add(w2).DivideOnScalar(3)
+			flatIntensity = r.lighting(centroid, faceNormal, material)
+		}
+
+		polygon := make([]clipVertex, 3)
+
+		for i, index := range t {
+			base := material.color
+			if material == &m.material && len(m.colors) > 0 {
+				base = m.colors[index]
+			}
+
+			normal := faceNormal
+			if len(normals) > 0 {
+				normal = normals[index]
+			}
+
+			attributes := make([]float64, attributeCount)
+			attributes[attributeRed] = float64(base.r)
+			attributes[attributeGreen] = float64(base.g)
+			attributes[attributeBlue] = float64(base.b)
+			attributes[attributeX], attributes[attributeY], attributes[attributeZ] = world[index].X(), world[index].Y(), world[index].Z()
+			attributes[attributeNormalX], attributes[attributeNormalY], attributes[attributeNormalZ] = normal.X(), normal.Y(), normal.Z()
+
+			if len(m.uvs) > 0 {
+				attributes[attributeU], attributes[attributeV] = m.uvs[index][0], m.uvs[index][1]
+			}
+
+			switch r.options.Shading {
+			case FlatShading:
+				attributes[attributeIntensity] = flatIntensity
+			case GouraudShading:
+				attributes[attributeIntensity] = r.lighting(world[index], normal, material)
+			}
+
+			v := view[index]
+			polygon[i] = clipVertex{r.projection.MultiplyVector4(linmath.NewVector4(v.X(), v.Y(), v.Z(), 1)), attributes}
+		}
+
+		if clip {
+			polygon = clipPolygon(polygon)
+		}
+
+		r.drawPolygon(polygon, r.shader(material))
+	}
+}
+
+// lighting is a method that computes the light intensity at a point in world space for the viewer at the camera.
+func (r *rasterizer) lighting(point, normal *linmath.Vector3, material *Material) float64 {
+	view := r.eye.Subtraction(point)
+	if normal.Dot(view) < 0 {
+		normal = normal.Negative()
+	}
+
+	return ComputeLighting(point, normal, view, material.specular, r.lights, nil, r.time, nil)
+}
+
+// shader is a method that returns the shader of a material for the shading mode of the rasterizer.
+func (r *rasterizer) shader(material *Material) shader {
+	return func(attributes []float64) Color {
+		base := colorFromAttributes(attributes)
+		if r.options.Wireframe {
+			return base
+		}
+
+		intensity := attributes[attributeIntensity]
+		if r.options.Shading == PhongShading {
+			point := linmath.NewVector3(attributes[attributeX], attributes[attributeY], attributes[attributeZ])
+			normal := linmath.NewVector3(attributes[attributeNormalX], attributes[attributeNormalY], attributes[attributeNormalZ])
+			intensity = r.lighting(point, normal.Normal(), material)
+		}
+
+		albedo := material.albedo(base, attributes[attributeU], attributes[attributeV])
+
+		return *albedo.MultiplyOnScalar(intensity)
+	}
+}
+
+// clipPolygon is a function that clips a convex polygon against every frustum plane with the Sutherland–Hodgman algorithm.
+func clipPolygon(polygon []clipVertex) []clipVertex {
+	for _, plane := range clipPlanes {
+		if len(polygon) == 0 {
+			break
+		}
+
+		var clipped []clipVertex
+
+		for i := range polygon {
+			current, next := polygon[i], polygon[(i+1)%len(polygon)]
+			dCurrent, dNext := plane.Dot(current.position), plane.Dot(next.position)
+
+			if dCurrent >= 0 {
+				clipped = append(clipped, current)
+			}
+
+			if (dCurrent >= 0) != (dNext >= 0) {
+				clipped = append(clipped, current.lerp(next, dCurrent/(dCurrent-dNext)))
+			}
+		}
+
+		polygon = clipped
+	}
+
+	return polygon
+}
+
+// drawPolygon is a method that projects a clipped polygon to the screen and draws it as a triangle fan.
+func (r *rasterizer) drawPolygon(polygon []clipVertex, shader shader) {
+	if len(polygon) < 3 {
+		return
+	}
+
+	width, height := float64(r.canvas.width), float64(r.canvas.height)
+	screen := make([]screenVertex, len(polygon))
+	for i, v := range polygon {
+		ndc := v.position.PerspectiveDivide()
+		invZ := 1 / v.position.W()
+		attributes := make([]float64, len(v.attributes))
+
+		for j := range attributes {
+			attributes[j] = v.attributes[j] * invZ
+		}
+
+		screen[i] = screenVertex{
+			x:          (ndc.X() + 1) * width / 2,
+			y:          height/2 - 1 - ndc.Y()*height/2,
+			invZ:       invZ,
+			attributes: attributes,
+		}
+	}
+
+	if r.options.Wireframe {
+		for i := range screen {
+			next := screen[(i+1)%len(screen)]
+			c := shader(polygon[i].attributes)
+			r.canvas.drawLine(int(screen[i].x), int(screen[i].y), int(next.x), int(next.y), &c)
+		}
+
+		return
+	}
+
+	for i := 1; i+1 < len(screen); i++ {
+		r.drawShadedTriangle(screen[0], screen[i], screen[i+1], shader)
+	}
+}
+
+// drawShadedTriangle is a method that fills a triangle scanline by scanline, interpolating the vertex attributes
+// and keeping only the pixels closer than what the depth buffer already holds.
+// A pixel is covered when its center lies inside the triangle or on a top or left edge,
+// so triangles sharing an edge never draw the same pixel twice.
+func (r *rasterizer) drawShadedTriangle(v0, v1, v2 screenVertex, shader shader) {
+	if v1.y < v0.y {
+		v0, v1 = v1, v0
+	}
+
+	if v2.y < v0.y {
+		v0, v2 = v2, v0
+	}
+
+	if v2.y < v1.y {
+		v1, v2 = v2, v1
+	}
+
+	for y := math.Ceil(v0.y); y < v2.y; y++ {
+		// The long edge v0-v2 spans every scanline, the short ones switch at v1.
+		long := v0.lerp(v2, (y-v0.y)/(v2.y-v0.y))
+
+		var short screenVertex
+		if y < v1.y {
+			short = v0.lerp(v1, (y-v0.y)/(v1.y-v0.y))
+		} else {
+			short = v1.lerp(v2, (y-v1.y)/(v2.y-v1.y))
+		}
+
+		left, right := long, short
+		if right.x < left.x {
+			left, right = right, left
+		}
+
+		for x := math.Ceil(left.x); x < right.x; x++ {
+			t := (x - left.x) / (right.x - left.x)
+			r.shadePixel(int(x), int(y), left.invZ+(right.invZ-left.invZ)*t, left.attributes, right.attributes, t, shader)
+		}
+	}
+}
+
+// shadePixel is a method that runs the depth test for a pixel and shades it if it is visible.
+// The attributes are interpolated between left and right only for visible pixels.
+func (r *rasterizer) shadePixel(x, y int, invZ float64, left, right []float64, t float64, shader shader) {
+	if !r.canvas.inside(x, y) {
+		return
+	}
+
+	i := x + y*r.canvas.width
+	if invZ <= r.depth[i] {
+		return
+	}
+
+	r.depth[i] = invZ
+
+	for j := range r.fragment {
+		r.fragment[j] = (left[j] + (right[j]-left[j])*t) / invZ
+	}
+
+	c := shader(r.fragment)
+	r.canvas.set(x, y, &c)
+}
+
+func colorFromAttributes(attributes []float64) Color {
+	return Color{
+		clampChannel(attributes[attributeRed]),
+		clampChannel(attributes[attributeGreen]),
+		clampChannel(attributes[attributeBlue]),
+		255,
+	}
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"context"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestClipPolygon(t *testing.T) {
+	// vertex is a function that returns a vertex in clip space whose attribute varies linearly over the space,
+	// so clipping keeps it equal to the same function of the position.
+	vertex := func(x, y, z float64) clipVertex {
+		return clipVertex{linmath.NewVector4(x, y, z, 1), []float64{x + 2*y + 3*z}}
+	}
+
+	front, back := 0.5, -0.5
+
+	tests := []struct {
+		name         string
+		inputPolygon []clipVertex
+		expected     [][3]float64
+	}{
+		{
+			"inside",
+			[]clipVertex{vertex(0, 0, front), vertex(0.5, 0, front), vertex(0, 0.5, front)},
+			[][3]float64{{0, 0, front}, {0.5, 0, front}, {0, 0.5, front}},
+		},
+		{
+			"behind",
+			[]clipVertex{vertex(0, 0, back), vertex(0.5, 0, back), vertex(0, 0.5, back)},
+			nil,
+		},
+		{
+			"one vertex behind",
+			[]clipVertex{vertex(0, 0, back), vertex(0.5, 0, front), vertex(0, 0.5, front)},
+			[][3]float64{{0.25, 0, 0}, {0.5, 0, front}, {0, 0.5, front}, {0, 0.25, 0}},
+		},
+		{
+			"two vertices behind",
+			[]clipVertex{vertex(0, 0, front), vertex(0.5, 0, back), vertex(0, 0.5, back)},
+			[][3]float64{{0, 0, front}, {0.25, 0, 0}, {0, 0.25, 0}},
+		},
+	}
+
+	for _, ts := range tests {
+		clipped := clipPolygon(ts.inputPolygon)
+		if len(clipped) != len(ts.expected) {
+			t.Fatalf("%s: expected [%v] vertices but have [%v]", ts.name, len(ts.expected), len(clipped))
+		}
+
+		for i, expected := range ts.expected {
+			have := clipped[i].position
+			if !equalVectors(linmath.NewVector3(have.X(), have.Y(), have.Z()), linmath.NewVector3(expected[0], expected[1], expected[2])) || have.W() != 1 {
+				t.Fatalf("%s: expected vertex [%v] but have [%v]", ts.name, expected, have)
+			}
+
+			if attribute := expected[0] + 2*expected[1] + 3*expected[2]; math.Abs(clipped[i].attributes[0]-attribute) > 1e-12 {
+				t.Fatalf("%s: expected attribute [%v] but have [%v]", ts.name, attribute, clipped[i].attributes[0])
+			}
+		}
+	}
+}
+
+func TestRasterizeSkippedObjects(t *testing.T) {
+	sphere := NewSphere(*linmath.NewVector3(0, 0, 3), 1, Material{})
+	scene := &Scene{
+		Objects: []Object{sphere, NewUnion(sphere, NewSphere(*linmath.NewVector3(1, 0, 3), 1, Material{}))},
+		Camera:  NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 0),
+	}
+
+	stats := &Statistics{}
+	if err := Rasterize(context.Background(), scene, NewCanvas(16, 16, true), &Options{}, stats); err != nil {
+		t.Fatal(err)
+	}
+
+	// The CSG tree cannot be tessellated, the sphere can.
+	if stats.SkippedObjects != 1 || stats.Triangles == 0 {
+		t.Fatalf("expected [1] skipped object and some triangles but have [%v] and [%v]", stats.SkippedObjects, stats.Triangles)
+	}
+}
+
+func TestRasterizeSingularCamera(t *testing.T) {
+	scene := &Scene{
+		Objects: []Object{NewSphere(*linmath.NewVector3(0, 0, 3), 1, Material{})},
+		Camera:  NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewScale(1, 1, 0), 0, 1, 0, 0, 0),
+	}
+
+	if err := Rasterize(context.Background(), scene, NewCanvas(16, 16, true), &Options{}, nil); err == nil {
+		t.Fatalf("expected an error for a camera transform that is not invertible")
+	}
+}
+
+func TestPerspectiveCorrectInterpolation(t *testing.T) {
+	size := 64
+	r := &rasterizer{
+		canvas:   NewCanvas(size, size, false),
+		depth:    make([]float64, size*size),
+		options:  &Options{},
+		fragment: make([]float64, attributeCount),
+	}
+
+	// A rectangle receding to the right: its left edge is at w = 1 and its right edge four times as far at w = 4,
+	// both edges reaching from y = -0.8 to 0.8 in view space. U runs from 0 on the left to 1 on the right
+	// and V from 0 at the bottom to 1 at the top.
+	const height, left, right, near, far = 0.8, -0.8, 3.2, 1., 4.
+	vertex := func(x, y, w, u, v float64) clipVertex {
+		attributes := make([]float64, attributeCount)
+		attributes[attributeU], attributes[attributeV] = u, v
+
+		return clipVertex{linmath.NewVector4(x, y, w/2, w), attributes}
+	}
+
+	quad := []clipVertex{
+		vertex(left, -height, near, 0, 0),
+		vertex(right, -height, far, 1, 0),
+		vertex(right, height, far, 1, 1),
+		vertex(left, height, near, 0, 1),
+	}
+	r.drawPolygon(quad, func(attributes []float64) Color {
+		return Color{clampChannel(attributes[attributeU] * 255), clampChannel(attributes[attributeV] * 255), 0, 255}
+	})
+
+	drawn, skewed := 0, 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if r.depth[x+y*size] == 0 {
+				continue
+			}
+
+			drawn++
+
+			// A point at U on the rectangle is seen at x / w = (left + U (right - left)) / (near + U (far - near)),
+			// solved here for U, and its height in view space is y / w times that w.
+			ndcX, ndcY := 2*float64(x)/float64(size)-1, 1-2*(float64(y)+1)/float64(size)
+			expectedU := (left - ndcX*near) / (ndcX*(far-near) - (right - left))
+			expectedV := (ndcY*(near+expectedU*(far-near))/height + 1) / 2
+
+			c := r.canvas.pixels[x+y*size]
+			if math.Abs(float64(c.r)-expectedU*255) > 1 || math.Abs(float64(c.g)-expectedV*255) > 1 {
+				t.Fatalf("expected UV [%v %v] at [%v %v] but have [%v %v]", expectedU, expectedV, x, y, float64(c.r)/255, float64(c.g)/255)
+			}
+
+			// Affine interpolation would spread U evenly across the screen from x / w = -0.8 to 0.8.
+			if affine := (ndcX - left/near) / (right/far - left/near); math.Abs(affine-expectedU) > 0.1 {
+				skewed++
+			}
+		}
+	}
+
+	// The rectangle covers two fifths of the image, most of it far from where affine interpolation would put its texture.
+	if drawn < size*size/3 || skewed < drawn/2 {
+		t.Fatalf("expected a third of the image drawn and most of it skewed but have [%v] and [%v] pixels", drawn, skewed)
+	}
+}
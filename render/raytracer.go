@@ -0,0 +1,252 @@
+package render
+
+import (
+	"context"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image"
+	"math"
+	"sync"
+)
+
+const (
+	viewportSize     = 1 // height of the viewport, its width follows the aspect ratio of the canvas
+	projectionPlaneZ = 1
+	recursionDepth   = 3 // mirror reflections followed by the ray tracer
+	shadowBias       = 0.001
+)
+
+// Object is anything that can be placed in the scene and hit by a ray.
+type Object interface {
+	// Intersect returns the nearest hit strictly between minT and maxT.
+	Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool)
+	Bounds() *linmath.AABB
+}
+
+// Hit describes where a ray meets the surface of an object.
+type Hit struct {
+	T        float64
+	Point    *linmath.Vector3
+	Normal   *linmath.Vector3 // unit length and pointing out of the object
+	U, V     float64          // texture coordinates
+	Color    Color            // albedo of the surface at the hit
+	Material *Material
+}
+
+// Scene is everything the renderers draw and the camera they see it through.
+type Scene struct {
+	Objects []Object
+	Lights  []Light
+	Camera  *Camera
+	Fog     *Fog     // ray tracer only, no fog when nil
+	Volumes []Volume // ray tracer only
+}
+
+// samples is a method that returns the number of primary rays per pixel. Volumes are sampled at random,
+// so any volume needs all of them, and otherwise the camera decides.
+func (s *Scene) samples(samples int) int {
+	if len(s.Volumes) > 0 {
+		return samples
+	}
+
+	return s.Camera.samples(samples)
+}
+
+// backgroundColor is what rays that hit nothing see.
+var backgroundColor = *NewColor(255, 255, 255, 255)
+
+// CanvasToViewPort is a function that converts 2D canvas coordinates to 3D viewport coordinates.
+// The coordinates are centered on a canvas of the given size, whose pixels are square.
+func CanvasToViewPort(x, y float64, width, height int) *linmath.Vector3 {
+	viewportWidth := viewportSize * float64(width) / float64(height)
+
+	return linmath.NewVector3(
+		x*viewportWidth/float64(width),
+		y*viewportSize/float64(height),
+		projectionPlaneZ,
+	)
+}
+
+// ClosestIntersection is a function that finds the nearest hit of the ray among the objects strictly between minT and maxT.
+func ClosestIntersection(ray *linmath.Ray, minT, maxT float64, objects []Object, stats *Statistics) (closest Hit, found bool) {
+	closest, _, found = closestObject(ray, minT, maxT, objects, stats)
+
+	return closest, found
+}
+
+// closestObject is a function that finds the nearest hit like ClosestIntersection and the index of the object it is on.
+func closestObject(ray *linmath.Ray, minT, maxT float64, objects []Object, stats *Statistics) (closest Hit, index int, found bool) {
+	stats.countIntersectionTests(len(objects))
+
+	for i, o := range objects {
+		// Each successful hit shrinks the search interval, so later objects only report closer hits.
+		if h, ok := o.Intersect(ray, minT, maxT); ok {
+			closest, index, found, maxT = h, i, true, h.T
+		}
+	}
+
+	return closest, index, found
+}
+
+// Sample is what a ray brings back: the color it sees and the first surface it hits,
+// which the arbitrary output variables of a render are made of.
+type Sample struct {
+	Color  Color
+	Hit    Hit     // first surface along the ray, only meaningful when Object is not negative
+	Object int     // index among the objects of the scene of the object hit first, -1 when the ray hits nothing
+	Hits   int     // surfaces hit by the ray and its mirror reflections
+	Time   float64 // time of the ray
+}
+
+// TraceRay is a function that traces the ray into the scene, following mirror reflections recursionDepth times.
+// Volumes and fog between the origin and what the ray hits dim it and add the light they scatter;
+// volumes draw the numbers they need from the sampler.
+func TraceRay(ray *linmath.Ray, minT, maxT float64, scene *Scene, recursionDepth int, sampler Sampler, stats *Statistics) Sample {
+	h, object, ok := closestObject(ray, minT, maxT, scene.Objects, stats)
+	if !ok {
+		return Sample{Color: scene.participate(ray, minT, maxT, backgroundColor, sampler, stats), Object: -1, Time: ray.Time}
+	}
+
+	color, hits := shade(ray, &h, scene, recursionDepth, sampler, stats)
+
+	return Sample{scene.participate(ray, minT, h.T, color, sampler, stats), h, object, hits, ray.Time}
+}
+
+// shade is a function that computes the color of the surface at the hit seen along the ray
+// and counts the surfaces it took, the hit and those seen in its reflections.
+func shade(ray *linmath.Ray, h *Hit, scene *Scene, recursionDepth int, sampler Sampler, stats *Statistics) (Color, int) {
+	view := ray.Direction.Negative()
+
+	// A surface seen from behind, like the inside of an open mesh, is lit on the side facing the viewer.
+	normal := h.Normal
+	if normal.Dot(view) < 0 {
+		normal = normal.Negative()
+	}
+
+	specular, reflective := h.Material.finish(h.U, h.V)
+	var localColor *Color
+	if h.Material.meanFreePath > 0 {
+		localColor = subsurface(ray, h, normal, view, specular, scene, sampler, stats)
+	} else {
+		intensity := computeLighting(h.Point, normal, view, specular, scene.Lights, scene.Objects, scene.Volumes, ray.Time, sampler, stats)
+		localColor = h.Color.MultiplyOnScalar(intensity)
+	}
+
+	if recursionDepth <= 0 || reflective <= 0 {
+		return *localColor, 1
+	}
+
+	stats.countReflectionRay()
+	reflectedRay := linmath.NewRay(h.Point, ReflectRay(view, normal), ray.Time)
+	reflected := TraceRay(reflectedRay, shadowBias, math.Inf(1), scene, recursionDepth-1, sampler, stats)
+
+	return *localColor.MultiplyOnScalar(1 - reflective).Add(reflected.Color.MultiplyOnScalar(reflective)), 1 + reflected.Hits
+}
+
+// tileSize is the side of the square tiles the ray tracer hands out to its workers.
+const tileSize = 16
+
+// RayTrace is a function that renders the scene seen by its camera into the canvas by tracing primary rays.
+// The canvas is split into tiles that options.Workers goroutines trace in whatever order they get to them;
+// every sample is drawn from a sampler started at its pixel, so the image does not depend on that order.
+// It reports progress after every tile and stops early with the error of the context once it is canceled.
+func RayTrace(ctx context.Context, scene *Scene, canvas *Canvas, options *Options, stats *Statistics) error {
+	return rayTrace(ctx, scene, canvas, nil, options, stats)
+}
+
+// rayTrace is a function that ray traces like RayTrace and also fills the AOVs of the canvas when they are not nil.
+func rayTrace(ctx context.Context, scene *Scene, canvas *Canvas, aovs *AOVs, options *Options, stats *Statistics) error {
+	width, height := canvas.width, canvas.height
+	columns, rows := (width+tileSize-1)/tileSize, (height+tileSize-1)/tileSize
+	workers := minInt(maxInt(options.Workers, 1), columns*rows)
+
+	tiles := make(chan image.Rectangle)
+	done := make(chan struct{})
+	workerStats := make([]Statistics, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(stats *Statistics) {
+			defer wg.Done()
+
+			sampler := NewSampler(options.Sampler, options.Seed)
+			for tile := range tiles {
+				rayTraceTile(scene, canvas, aovs, tile, options.Samples, sampler, stats)
+				done <- struct{}{}
+			}
+		}(&workerStats[w])
+	}
+
+	go func() {
+		defer close(tiles)
+
+		for ty := 0; ty < rows; ty++ {
+			for tx := 0; tx < columns; tx++ {
+				tile := image.Rect(tx*tileSize, ty*tileSize, (tx+1)*tileSize, (ty+1)*tileSize).Intersect(image.Rect(0, 0, width, height))
+				if ctx.Err() != nil {
+					return
+				}
+
+				select {
+				case tiles <- tile:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	finished := 0
+	for range done {
+		finished++
+		options.reportProgress(float64(finished) / float64(columns*rows))
+	}
+
+	if stats != nil {
+		for w := range workerStats {
+			stats.add(&workerStats[w])
+		}
+	}
+
+	if finished < columns*rows {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// rayTraceTile is a function that traces the samples of every pixel of a tile of the canvas, given in image coordinates,
+// and gathers the AOVs of the pixels when they are not nil.
+func rayTraceTile(scene *Scene, canvas *Canvas, aovs *AOVs, tile image.Rectangle, samples int, sampler Sampler, stats *Statistics) {
+	camera, width, height := scene.Camera, canvas.width, canvas.height
+	samples = scene.samples(samples)
+	pixel := make([]Sample, samples)
+
+	for iy := tile.Min.Y; iy < tile.Max.Y; iy++ {
+		y := height - iy - 1
+
+		for x := tile.Min.X; x < tile.Max.X; x++ {
+			var r, g, b int
+
+			for i := 0; i < samples; i++ {
+				stats.countPrimaryRay()
+				sampler.Start(x, iy, i)
+				ray := camera.Ray(float64(x)-float64(width)/2, float64(y)-float64(height)/2, width, height, sampler)
+				pixel[i] = TraceRay(ray, 0., math.Inf(1), scene, recursionDepth, sampler, stats)
+				r, g, b = r+int(pixel[i].Color.r), g+int(pixel[i].Color.g), b+int(pixel[i].Color.b)
+			}
+
+			canvas.set(x, iy, NewColor(uint8(r/samples), uint8(g/samples), uint8(b/samples), 255))
+
+			if aovs != nil {
+				aovs.set(x, iy, pixel, scene)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Backend is the algorithm that turns a scene into an image.
+type Backend int
+
+const (
+	RayTraceBackend Backend = iota // shadows, reflections, CSG and depth of field
+	RasterBackend                  // fast previews of everything that can be tessellated
+)
+
+// Backends maps the names of the backends to their values, for command line flags.
+var Backends = map[string]Backend{
+	"raytrace": RayTraceBackend,
+	"raster":   RasterBackend,
+}
+
+// Defaults of the options left at zero.
+const (
+	DefaultWidth   = 600
+	DefaultHeight  = 600
+	DefaultSamples = 16
+)
+
+// Options are the settings of a render. The zero value ray traces a DefaultWidth x DefaultHeight image.
+type Options struct {
+	Backend   Backend
+	Width     int
+	Height    int
+	Samples   int         // primary rays per pixel when the camera has an aperture or an open shutter
+	Sampler   SamplerKind // sequence the samples of the ray tracer are drawn from
+	Seed      uint64      // renders with the same seed and sampler are identical
+	Workers   int         // goroutines ray tracing tiles of the image, GOMAXPROCS when zero
+	Wireframe bool        // raster backend only
+	Shading   ShadingMode // raster backend only
+	// Progress, when not nil, is called on the rendering goroutine with the fraction of the image done so far.
+	Progress func(fraction float64)
+}
+
+// withDefaults is a method that returns a copy of the options with the defaults filled in.
+func (o *Options) withDefaults() *Options {
+	options := *o
+
+	if options.Width <= 0 {
+		options.Width = DefaultWidth
+	}
+
+	if options.Height <= 0 {
+		options.Height = DefaultHeight
+	}
+
+	if options.Samples <= 0 {
+		options.Samples = DefaultSamples
+	}
+
+	if options.Workers <= 0 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+
+	return &options
+}
+
+func (o *Options) reportProgress(fraction float64) {
+	if o.Progress != nil {
+		o.Progress(fraction)
+	}
+}
+
+// Render is a function that draws the scene seen by its camera into a new image.
+func Render(scene *Scene, options *Options) (image.Image, error) {
+	img, _, err := RenderContext(context.Background(), scene, options)
+
+	return img, err
+}
+
+// RenderContext is a function that draws the scene seen by its camera into a new image and describes the work it took.
+// Canceling the context stops the render early with the error of the context; the image then holds
+// whatever was drawn so far, and the statistics describe the work done until then.
+func RenderContext(ctx context.Context, scene *Scene, options *Options) (image.Image, *Statistics, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	options = options.withDefaults()
+	stats := &Statistics{}
+
+	if scene.Camera == nil {
+		return nil, stats, errors.New("render: scene has no camera")
+	}
+
+	canvas := NewCanvas(options.Width, options.Height, true)
+	start := time.Now()
+
+	var err error
+	switch options.Backend {
+	case RayTraceBackend:
+		err = RayTrace(ctx, scene, canvas, options, stats)
+		stats.addPhase("ray tracing", start)
+	case RasterBackend:
+		err = Rasterize(ctx, scene, canvas, options, stats)
+		stats.addPhase("rasterization", start)
+	default:
+		err = fmt.Errorf("render: unknown backend %d", options.Backend)
+	}
+
+	return canvas, stats, err
+}
+
+// progressBarWidth is the number of characters between the brackets of a progress bar.
+const progressBarWidth = 40
+
+// ProgressBar is a function that returns a progress callback drawing a bar with the time left on the terminal.
+// The bar is only redrawn when the percentage changes.
+func ProgressBar(w io.Writer, label string) func(fraction float64) {
+	start := time.Now()
+	last := -1
+
+	return func(fraction float64) {
+		percent := int(fraction * 100)
+		if percent == last {
+			return
+		}
+
+		last = percent
+		filled := int(fraction * progressBarWidth)
+		eta := "?"
+
+		if fraction > 0 {
+			elapsed := time.Since(start)
+			eta = (time.Duration(float64(elapsed) * (1 - fraction) / fraction)).Round(time.Second).String()
+		}
+
+		_, _ = fmt.Fprintf(w, "\r%s [%s%s] %3d%% %s left ", label, strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), percent, eta)
+
+		if fraction >= 1 {
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+}
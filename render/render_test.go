@@ -0,0 +1,153 @@
+package render_test
+
+import (
+	"context"
+	"errors"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"github.com/UnTea/ComputerGraphics/render"
+	"testing"
+)
+
+func testScene() *render.Scene {
+	return &render.Scene{
+		Objects: []render.Object{
+			render.NewSphere(*linmath.NewVector3(0, 0, 3), 1, *render.NewMaterial(*render.NewColor(255, 0, 0, 255), 10, 0.5, nil)),
+		},
+		Lights: []render.Light{*render.NewAmbientLight(0.2), *render.NewPointLight(0.8, linmath.NewVector3(2, 1, 0))},
+		Camera: render.NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 0),
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputOptions   *render.Options
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{"defaults", nil, render.DefaultWidth, render.DefaultHeight},
+		{"wide", &render.Options{Width: 64, Height: 32}, 64, 32},
+		{"raster", &render.Options{Backend: render.RasterBackend, Width: 20, Height: 40}, 20, 40},
+	}
+
+	for _, ts := range tests {
+		img, err := render.Render(testScene(), ts.inputOptions)
+		if err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+
+		if bounds := img.Bounds(); bounds.Dx() != ts.expectedWidth || bounds.Dy() != ts.expectedHeight {
+			t.Fatalf("%s: expected [%vx%v] but have [%v]", ts.name, ts.expectedWidth, ts.expectedHeight, bounds)
+		}
+
+		// The red sphere is in the middle of the image whatever its aspect ratio, and the background is around it.
+		if r, g, _, _ := img.At(ts.expectedWidth/2, ts.expectedHeight/2).RGBA(); r <= g {
+			t.Fatalf("%s: expected the red sphere in the middle but have [%v]", ts.name, img.At(ts.expectedWidth/2, ts.expectedHeight/2))
+		}
+
+		if r, g, b, _ := img.At(0, 0).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+			t.Fatalf("%s: expected the background in the corner but have [%v]", ts.name, img.At(0, 0))
+		}
+	}
+}
+
+func TestRenderWithoutCamera(t *testing.T) {
+	scene := testScene()
+	scene.Camera = nil
+
+	if _, err := render.Render(scene, nil); err == nil {
+		t.Fatalf("expected an error for a scene without a camera")
+	}
+}
+
+func TestRenderStatistics(t *testing.T) {
+	for name, backend := range render.Backends {
+		var reports []float64
+		options := &render.Options{Backend: backend, Width: 100, Height: 100, Progress: func(fraction float64) {
+			reports = append(reports, fraction)
+		}}
+
+		_, stats, err := render.RenderContext(context.Background(), testScene(), options)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(reports) == 0 || reports[len(reports)-1] != 1 {
+			t.Fatalf("%s: expected progress to end at [1] but have [%v]", name, reports)
+		}
+
+		for i := 1; i < len(reports); i++ {
+			if reports[i] < reports[i-1] {
+				t.Fatalf("%s: expected progress to grow but have [%v]", name, reports)
+			}
+		}
+
+		if len(stats.Phases) != 1 || stats.Duration() <= 0 {
+			t.Fatalf("%s: expected one timed phase but have [%v]", name, stats.Phases)
+		}
+
+		switch backend {
+		case render.RayTraceBackend:
+			// Every pixel that sees the sphere casts a shadow ray towards the point light and a reflection ray,
+			// which cannot hit the convex sphere again, and every ray is tested against the only object.
+			if stats.PrimaryRays != 100*100 {
+				t.Fatalf("expected [%v] primary rays but have [%v]", 100*100, stats.PrimaryRays)
+			}
+
+			if stats.ShadowRays == 0 || stats.ShadowRays != stats.ReflectionRays {
+				t.Fatalf("expected as many shadow as reflection rays but have [%v] and [%v]", stats.ShadowRays, stats.ReflectionRays)
+			}
+
+			if stats.IntersectionTests != stats.Rays() {
+				t.Fatalf("expected [%v] intersection tests but have [%v]", stats.Rays(), stats.IntersectionTests)
+			}
+		case render.RasterBackend:
+			if stats.Triangles == 0 || stats.Rays() != 0 {
+				t.Fatalf("expected triangles and no rays but have [%v] and [%v]", stats.Triangles, stats.Rays())
+			}
+		}
+	}
+}
+
+func TestRenderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, backend := range render.Backends {
+		_, stats, err := render.RenderContext(ctx, testScene(), &render.Options{Backend: backend})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("%s: expected [%v] but have [%v]", name, context.Canceled, err)
+		}
+
+		if stats.Rays() != 0 || stats.Triangles != 0 {
+			t.Fatalf("%s: expected no work but have [%v]", name, stats)
+		}
+	}
+}
+
+func TestRenderParallel(t *testing.T) {
+	for name, sampler := range render.Samplers {
+		options := render.Options{Width: 50, Height: 40, Samples: 4, Sampler: sampler, Seed: 3}
+
+		serialOptions, parallelOptions := options, options
+		serialOptions.Workers, parallelOptions.Workers = 1, 5
+
+		serial, err := render.Render(goldenDepthOfField(), &serialOptions)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		parallel, err := render.Render(goldenDepthOfField(), &parallelOptions)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 50; x++ {
+				if serial.At(x, y) != parallel.At(x, y) {
+					t.Fatalf("%s: expected [%v] at [%v %v] but have [%v]", name, serial.At(x, y), x, y, parallel.At(x, y))
+				}
+			}
+		}
+	}
+}
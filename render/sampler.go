@@ -0,0 +1,79 @@
+package render
+
+// Sampler produces the numbers in [0, 1) that stochastic effects such as depth of field and motion blur consume.
+// The value of a dimension depends only on the seed, the pixel, the sample index and the dimension,
+// never on the pixels sampled before, so tiles rendered in any order on any goroutine give the same image.
+// A Sampler is not safe for concurrent use; every goroutine creates its own with NewSampler.
+type Sampler interface {
+	// Start is a method that moves the sampler to a sample of a pixel; the next value is its first dimension.
+	Start(x, y, index int)
+	// Float64 is a method that returns the next dimension of the current sample.
+	Float64() float64
+}
+
+// SamplerKind selects the sequence a Sampler draws from.
+type SamplerKind int
+
+const (
+	SobolSampler     SamplerKind = iota // Owen-scrambled Sobol points, shuffled for every pixel
+	HaltonSampler                       // Halton points, rotated for every pixel
+	BlueNoiseSampler                    // the same Sobol points in every pixel, rotated by a blue-noise texture
+	RandomSampler                       // independent PCG numbers with no stratification at all
+)
+
+// Samplers maps the names of the samplers to their values, for command line flags.
+var Samplers = map[string]SamplerKind{
+	"sobol":     SobolSampler,
+	"halton":    HaltonSampler,
+	"bluenoise": BlueNoiseSampler,
+	"random":    RandomSampler,
+}
+
+// NewSampler is a function that returns a sampler of the given kind. Samplers with the same kind and seed
+// return the same values for the same pixel, sample and dimension.
+func NewSampler(kind SamplerKind, seed uint64) Sampler {
+	switch kind {
+	case HaltonSampler:
+		return &haltonSampler{seed: seed}
+	case BlueNoiseSampler:
+		return &blueNoiseSampler{seed: seed, texture: blueNoiseTexture()}
+	case RandomSampler:
+		return &randomSampler{seed: seed}
+	default:
+		return &sobolSampler{seed: seed}
+	}
+}
+
+// randomSampler draws every sample from its own PCG stream, seeded from the pixel and the sample index.
+type randomSampler struct {
+	seed   uint64
+	random PCG
+}
+
+func (s *randomSampler) Start(x, y, index int) {
+	s.random = *NewPCG(hash(s.seed, uint64(x), uint64(y), uint64(index)), s.seed)
+}
+
+func (s *randomSampler) Float64() float64 {
+	return s.random.Float64()
+}
+
+// hash is a function that mixes any number of values into one well distributed 64-bit value
+// with the finalizer of SplitMix64.
+func hash(values ...uint64) uint64 {
+	h := uint64(0x9e3779b97f4a7c15)
+
+	for _, v := range values {
+		h ^= v + 0x9e3779b97f4a7c15 + (h << 6) + (h >> 2)
+		h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+		h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+		h ^= h >> 31
+	}
+
+	return h
+}
+
+// hashFloat64 is a function that maps the hash of the values to [0, 1).
+func hashFloat64(values ...uint64) float64 {
+	return float64(hash(values...)>>11) / (1 << 53)
+}
@@ -0,0 +1,175 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPCG(t *testing.T) {
+	// The first outputs of pcg32-demo from the reference implementation.
+	expected := []uint32{0xa15c02b7, 0x7b47f409, 0xba1d3330, 0x83d2f293, 0xbfa4784b, 0xcbed606e}
+	random := NewPCG(42, 54)
+
+	for i, e := range expected {
+		if v := random.Uint32(); v != e {
+			t.Fatalf("output %d: expected [%#x] but have [%#x]", i, e, v)
+		}
+	}
+}
+
+func TestRadicalInverse(t *testing.T) {
+	tests := []struct {
+		inputBase     uint64
+		inputIndex    uint64
+		expectedValue float64
+	}{
+		{2, 0, 0},
+		{2, 1, 0.5},
+		{2, 6, 0.375},
+		{3, 1, 1. / 3},
+		{3, 5, 7. / 9},
+	}
+
+	for _, ts := range tests {
+		if v := radicalInverse(ts.inputBase, ts.inputIndex); math.Abs(v-ts.expectedValue) > 1e-12 {
+			t.Fatalf("expected [%v] but have [%v]", ts.expectedValue, v)
+		}
+	}
+}
+
+// samples is a function that returns the first dimensions of n samples of a pixel.
+func samples(sampler Sampler, x, y, n, dimensions int) [][]float64 {
+	points := make([][]float64, n)
+
+	for i := range points {
+		sampler.Start(x, y, i)
+
+		points[i] = make([]float64, dimensions)
+		for d := range points[i] {
+			points[i][d] = sampler.Float64()
+		}
+	}
+
+	return points
+}
+
+func TestSamplerRange(t *testing.T) {
+	for name, kind := range Samplers {
+		for _, point := range samples(NewSampler(kind, 7), 3, 5, 64, 40) {
+			for d, v := range point {
+				if v < 0 || v >= 1 {
+					t.Fatalf("%s: expected dimension %d in [0, 1) but have [%v]", name, d, v)
+				}
+			}
+		}
+	}
+}
+
+func TestSamplerDeterminism(t *testing.T) {
+	for name, kind := range Samplers {
+		expected := samples(NewSampler(kind, 7), 10, 20, 8, 6)
+
+		// Samples of other pixels in between must not change those of this one.
+		sampler := NewSampler(kind, 7)
+		samples(sampler, 11, 20, 3, 2)
+		have := samples(sampler, 10, 20, 8, 6)
+
+		for i := range expected {
+			for d := range expected[i] {
+				if have[i][d] != expected[i][d] {
+					t.Fatalf("%s: expected [%v] but have [%v]", name, expected[i], have[i])
+				}
+			}
+		}
+
+		if other := samples(NewSampler(kind, 8), 10, 20, 1, 6); other[0][0] == expected[0][0] && other[0][1] == expected[0][1] {
+			t.Fatalf("%s: expected another seed to give other samples", name)
+		}
+	}
+}
+
+func TestSamplerStratification(t *testing.T) {
+	tests := []struct {
+		name            string
+		inputKind       SamplerKind
+		inputDimensions []int
+	}{
+		{"sobol", SobolSampler, []int{0, 1, 2, 3, 4, 5}},
+		{"halton", HaltonSampler, []int{0}},
+	}
+
+	const n = 16
+
+	for _, ts := range tests {
+		for _, pixel := range [][2]int{{0, 0}, {5, 9}} {
+			points := samples(NewSampler(ts.inputKind, 1), pixel[0], pixel[1], n, 6)
+
+			for _, d := range ts.inputDimensions {
+				var strata [n]int
+				for _, point := range points {
+					strata[int(point[d]*n)]++
+				}
+
+				for s, count := range strata {
+					if count != 1 {
+						t.Fatalf("%s: expected one sample of dimension %d in stratum %d but have [%v]", ts.name, d, s, count)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSobolNet(t *testing.T) {
+	// The first 16 points of the first two dimensions are a (0, 4, 2)-net: every elementary box
+	// of area 1/16, from 1x16 to 16x1 strata, holds exactly one of them.
+	const n = 16
+	points := samples(NewSampler(SobolSampler, 3), 2, 4, n, 2)
+
+	for columns := 1; columns <= n; columns *= 2 {
+		rows := n / columns
+		boxes := make([]int, n)
+
+		for _, point := range points {
+			boxes[int(point[1]*float64(rows))*columns+int(point[0]*float64(columns))]++
+		}
+
+		for b, count := range boxes {
+			if count != 1 {
+				t.Fatalf("expected one point in box %d of %dx%d but have [%v]", b, columns, rows, count)
+			}
+		}
+	}
+}
+
+func TestBlueNoise(t *testing.T) {
+	texture := blueNoiseTexture()
+	n := len(texture)
+
+	if n != blueNoiseSize*blueNoiseSize {
+		t.Fatalf("expected [%v] texels but have [%v]", blueNoiseSize*blueNoiseSize, n)
+	}
+
+	// Every rank appears once, so the values are uniformly distributed.
+	seen := make([]bool, n)
+	for _, v := range texture {
+		r := int(v * float64(n))
+		if seen[r] {
+			t.Fatalf("expected distinct ranks but have [%v] twice", r)
+		}
+
+		seen[r] = true
+	}
+
+	// Neighbouring texels of blue noise differ more than those of white noise, whose mean difference is 1/3.
+	difference := 0.
+	for y := 0; y < blueNoiseSize; y++ {
+		for x := 0; x < blueNoiseSize; x++ {
+			difference += math.Abs(texture[y*blueNoiseSize+x] - texture[y*blueNoiseSize+(x+1)%blueNoiseSize])
+		}
+	}
+
+	if difference /= float64(n); difference < 0.38 {
+		t.Fatalf("expected neighbours to differ by more than [0.38] on average but have [%v]", difference)
+	}
+}
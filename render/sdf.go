@@ -0,0 +1,313 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+const (
+	sdfMaxSteps = 512  // steps a ray may take before it is taken to have missed
+	sdfEpsilon  = 1e-5 // distance at which a ray has reached the surface
+	sdfNormalH  = 1e-5 // step of the central differences of the gradient
+)
+
+// SDF is a signed distance field: the distance from a point to a surface, negative inside it. The distance
+// may be underestimated but never overestimated, so a ray can always step that far without crossing the surface.
+// Bounds enclose the whole surface.
+type SDF interface {
+	Distance(p *linmath.Vector3) float64
+	Bounds() *linmath.AABB
+}
+
+// ImplicitSurface is an object whose surface is where a signed distance field is zero, rendered by sphere tracing.
+// The raster backend cannot tessellate it and leaves it out.
+type ImplicitSurface struct {
+	sdf      SDF
+	material Material
+	bounds   linmath.AABB
+}
+
+func NewImplicitSurface(sdf SDF, material Material) *ImplicitSurface {
+	return &ImplicitSurface{sdf, material, *sdf.Bounds()}
+}
+
+// Intersect is a method that sphere traces the ray through the part of the bounds between minT and maxT:
+// the ray advances by the distance to the surface, which cannot skip it, until it is close enough.
+// Steps go by the absolute distance, so a ray starting inside the surface finds its way out.
+func (s *ImplicitSurface) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	start, end, ok := s.bounds.ClipRay(&ray.Origin, &ray.Direction, minT, maxT)
+	if !ok {
+		return Hit{}, false
+	}
+
+	// Distances are in world units while t is in lengths of the direction, which an instance may have scaled.
+	speed := ray.Direction.Length()
+
+	t := start
+	for i := 0; i < sdfMaxSteps && t <= end; i++ {
+		d := math.Abs(s.sdf.Distance(ray.At(t)))
+
+		if d < sdfEpsilon {
+			if minT < t && t < maxT {
+				return s.hit(ray, t), true
+			}
+
+			// The ray starts on the surface, so it steps off it first.
+			d = sdfEpsilon
+		}
+
+		t += d / speed
+	}
+
+	return Hit{}, false
+}
+
+// hit is a method that describes the hit at t. The normal is the gradient of the field from central differences.
+func (s *ImplicitSurface) hit(ray *linmath.Ray, t float64) Hit {
+	point := ray.At(t)
+
+	gradient := func(axis *linmath.Vector3) float64 {
+		offset := axis.MultiplyOnScalar(sdfNormalH)
+		return s.sdf.Distance(point.Add(offset)) - s.sdf.Distance(point.Subtraction(offset))
+	}
+
+	normal := linmath.NewVector3(
+		gradient(linmath.NewVector3(1, 0, 0)),
+		gradient(linmath.NewVector3(0, 1, 0)),
+		gradient(linmath.NewVector3(0, 0, 1)),
+	)
+
+	if normal.Length() > 0 {
+		normal = normal.Normal()
+	}
+
+	return Hit{t, point, normal, 0, 0, s.material.albedo(s.material.color, 0, 0), &s.material}
+}
+
+func (s *ImplicitSurface) Bounds() *linmath.AABB {
+	return linmath.NewAABB(s.bounds.Min(), s.bounds.Max())
+}
+
+type sdfSphere struct {
+	center linmath.Vector3
+	radius float64
+}
+
+func NewSDFSphere(center *linmath.Vector3, radius float64) SDF {
+	return &sdfSphere{*center, radius}
+}
+
+func (s *sdfSphere) Distance(p *linmath.Vector3) float64 {
+	return p.Subtraction(&s.center).Length() - s.radius
+}
+
+func (s *sdfSphere) Bounds() *linmath.AABB {
+	return linmath.NewAABB(s.center.Subtraction(linmath.Splat(s.radius)), s.center.Add(linmath.Splat(s.radius)))
+}
+
+type sdfBox struct {
+	center   linmath.Vector3
+	half     linmath.Vector3
+	rounding float64
+}
+
+// NewSDFBox is a function that returns an axis-aligned box of the given size whose edges and corners
+// are rounded off with the rounding radius.
+func NewSDFBox(center, size *linmath.Vector3, rounding float64) SDF {
+	return &sdfBox{*center, *size.DivideOnScalar(2), rounding}
+}
+
+func (b *sdfBox) Distance(p *linmath.Vector3) float64 {
+	q := p.Subtraction(&b.center)
+	q = linmath.NewVector3(math.Abs(q.X()), math.Abs(q.Y()), math.Abs(q.Z())).Subtraction(&b.half).Add(linmath.Splat(b.rounding))
+
+	// Outside the distance is to the nearest point of the shrunk box, inside to its nearest face.
+	outside := q.Max(linmath.Splat(0)).Length()
+	inside := math.Min(math.Max(q.X(), math.Max(q.Y(), q.Z())), 0)
+
+	return outside + inside - b.rounding
+}
+
+func (b *sdfBox) Bounds() *linmath.AABB {
+	return linmath.NewAABB(b.center.Subtraction(&b.half), b.center.Add(&b.half))
+}
+
+type sdfTorus struct {
+	center linmath.Vector3
+	major  float64
+	minor  float64
+}
+
+// NewSDFTorus is a function that returns a torus lying in the XZ plane: a tube of the minor radius
+// around a circle of the major radius.
+func NewSDFTorus(center *linmath.Vector3, major, minor float64) SDF {
+	return &sdfTorus{*center, major, minor}
+}
+
+func (t *sdfTorus) Distance(p *linmath.Vector3) float64 {
+	q := p.Subtraction(&t.center)
+
+	return math.Hypot(math.Hypot(q.X(), q.Z())-t.major, q.Y()) - t.minor
+}
+
+func (t *sdfTorus) Bounds() *linmath.AABB {
+	extent := linmath.NewVector3(t.major+t.minor, t.minor, t.major+t.minor)
+
+	return linmath.NewAABB(t.center.Subtraction(extent), t.center.Add(extent))
+}
+
+type sdfCapsule struct {
+	a, b   linmath.Vector3
+	radius float64
+}
+
+// NewSDFCapsule is a function that returns the points within the radius of the segment from a to b.
+func NewSDFCapsule(a, b *linmath.Vector3, radius float64) SDF {
+	return &sdfCapsule{*a, *b, radius}
+}
+
+func (c *sdfCapsule) Distance(p *linmath.Vector3) float64 {
+	pa, ba := p.Subtraction(&c.a), c.b.Subtraction(&c.a)
+
+	h := 0.
+	if length := ba.Dot(ba); length > 0 {
+		h = math.Min(math.Max(pa.Dot(ba)/length, 0), 1)
+	}
+
+	return pa.Subtraction(ba.MultiplyOnScalar(h)).Length() - c.radius
+}
+
+func (c *sdfCapsule) Bounds() *linmath.AABB {
+	r := linmath.Splat(c.radius)
+
+	return linmath.NewAABB(c.a.Min(&c.b).Subtraction(r), c.a.Max(&c.b).Add(r))
+}
+
+type smoothUnion struct {
+	left, right SDF
+	k           float64
+}
+
+// NewSmoothUnion is a function that returns both fields melted together where they are closer than k,
+// which makes blobs of nearby shapes. A k of 0 is the plain union.
+func NewSmoothUnion(left, right SDF, k float64) SDF {
+	return &smoothUnion{left, right, k}
+}
+
+func (u *smoothUnion) Distance(p *linmath.Vector3) float64 {
+	return smoothMin(u.left.Distance(p), u.right.Distance(p), u.k)
+}
+
+// Bounds is a method that returns the bounds of both operands, grown by the most the blend can add.
+func (u *smoothUnion) Bounds() *linmath.AABB {
+	bounds := u.left.Bounds().Union(u.right.Bounds())
+
+	return linmath.NewAABB(bounds.Min().Subtraction(linmath.Splat(u.k/4)), bounds.Max().Add(linmath.Splat(u.k/4)))
+}
+
+type smoothSubtraction struct {
+	left, right SDF
+	k           float64
+}
+
+// NewSmoothSubtraction is a function that returns left without right, with the edges of the cut rounded over k.
+func NewSmoothSubtraction(left, right SDF, k float64) SDF {
+	return &smoothSubtraction{left, right, k}
+}
+
+func (s *smoothSubtraction) Distance(p *linmath.Vector3) float64 {
+	return -smoothMin(-s.left.Distance(p), s.right.Distance(p), s.k)
+}
+
+func (s *smoothSubtraction) Bounds() *linmath.AABB {
+	return s.left.Bounds()
+}
+
+// smoothMin is a function that returns the polynomial smooth minimum of a and b, which is at most k/4 below the minimum.
+func smoothMin(a, b, k float64) float64 {
+	if k <= 0 {
+		return math.Min(a, b)
+	}
+
+	h := math.Max(k-math.Abs(a-b), 0) / k
+
+	return math.Min(a, b) - h*h*k/4
+}
+
+type repetition struct {
+	sdf     SDF
+	spacing linmath.Vector3
+	count   [3]int
+}
+
+// NewRepetition is a function that repeats the field every spacing along each axis, count times on either side
+// of the original, so there are 2 * count + 1 copies in a row. An axis with a count of 0 is not repeated.
+// The original must fit in a cell of the spacing around the origin for the distance to stay a bound.
+func NewRepetition(sdf SDF, spacing *linmath.Vector3, count [3]int) SDF {
+	return &repetition{sdf, *spacing, count}
+}
+
+// Distance is a method that folds the point into the cell of the nearest copy.
+func (r *repetition) Distance(p *linmath.Vector3) float64 {
+	coordinates := [3]float64{p.X(), p.Y(), p.Z()}
+	spacing := [3]float64{r.spacing.X(), r.spacing.Y(), r.spacing.Z()}
+
+	for axis := range coordinates {
+		if r.count[axis] > 0 && spacing[axis] > 0 {
+			cell := math.Min(math.Max(math.Round(coordinates[axis]/spacing[axis]), -float64(r.count[axis])), float64(r.count[axis]))
+			coordinates[axis] -= spacing[axis] * cell
+		}
+	}
+
+	return r.sdf.Distance(linmath.NewVector3(coordinates[0], coordinates[1], coordinates[2]))
+}
+
+func (r *repetition) Bounds() *linmath.AABB {
+	bounds := r.sdf.Bounds()
+	extent := linmath.NewVector3(
+		r.spacing.X()*float64(r.count[0]),
+		r.spacing.Y()*float64(r.count[1]),
+		r.spacing.Z()*float64(r.count[2]),
+	)
+
+	return linmath.NewAABB(bounds.Min().Subtraction(extent), bounds.Max().Add(extent))
+}
+
+type twist struct {
+	sdf    SDF
+	rate   float64
+	radius float64
+}
+
+// NewTwist is a function that twists the field around the Y axis by rate radians per unit of height.
+func NewTwist(sdf SDF, rate float64) SDF {
+	bounds := sdf.Bounds()
+
+	radius := 0.
+	for _, x := range []float64{bounds.Min().X(), bounds.Max().X()} {
+		for _, z := range []float64{bounds.Min().Z(), bounds.Max().Z()} {
+			radius = math.Max(radius, math.Hypot(x, z))
+		}
+	}
+
+	return &twist{sdf, rate, radius}
+}
+
+// Distance is a method that untwists the point. Twisting stretches space the more the farther it is from the axis,
+// so the distance is shrunk by the most it stretches within the bounds to stay a bound.
+func (t *twist) Distance(p *linmath.Vector3) float64 {
+	sin, cos := math.Sincos(-t.rate * p.Y())
+	q := linmath.NewVector3(cos*p.X()+sin*p.Z(), p.Y(), -sin*p.X()+cos*p.Z())
+
+	return t.sdf.Distance(q) / math.Sqrt(1+t.rate*t.rate*t.radius*t.radius)
+}
+
+// Bounds is a method that returns the bounds of the field turned all the way around the Y axis.
+func (t *twist) Bounds() *linmath.AABB {
+	bounds := t.sdf.Bounds()
+
+	return linmath.NewAABB(
+		linmath.NewVector3(-t.radius, bounds.Min().Y(), -t.radius),
+		linmath.NewVector3(t.radius, bounds.Max().Y(), t.radius),
+	)
+}
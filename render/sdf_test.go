@@ -0,0 +1,92 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestImplicitSurface(t *testing.T) {
+	material := *NewMaterial(*NewColor(255, 255, 255, 255), -1, 0, nil)
+	forward := linmath.NewVector3(0, 0, 1)
+
+	tests := []struct {
+		name           string
+		inputSDF       SDF
+		inputOrigin    *linmath.Vector3
+		inputDirection *linmath.Vector3
+		inputMinT      float64
+		inputMaxT      float64
+		expectedHit    bool
+		expectedT      float64
+		expectedNormal *linmath.Vector3
+	}{
+		{"sphere", NewSDFSphere(linmath.NewVector3(0, 0, 0), 1), linmath.NewVector3(0, 0, -5), forward, 0, math.Inf(1), true, 4, linmath.NewVector3(0, 0, -1)},
+		{"sphere beyond maxT", NewSDFSphere(linmath.NewVector3(0, 0, 0), 1), linmath.NewVector3(0, 0, -5), forward, 0, 3.9, false, 0, nil},
+		{"sphere from inside after minT", NewSDFSphere(linmath.NewVector3(0, 0, 0), 1), linmath.NewVector3(0, 0, -5), forward, 4.5, math.Inf(1), true, 6, linmath.NewVector3(0, 0, 1)},
+		{"sphere on a long direction", NewSDFSphere(linmath.NewVector3(0, 0, 0), 1), linmath.NewVector3(0, 0, -5), linmath.NewVector3(0, 0, 4), 0, math.Inf(1), true, 1, linmath.NewVector3(0, 0, -1)},
+		{"box", NewSDFBox(linmath.NewVector3(0, 0, 0), linmath.NewVector3(2, 2, 2), 0), linmath.NewVector3(0.9, 0.9, -5), forward, 0, math.Inf(1), true, 4, linmath.NewVector3(0, 0, -1)},
+		{"rounded box corner", NewSDFBox(linmath.NewVector3(0, 0, 0), linmath.NewVector3(2, 2, 2), 0.5), linmath.NewVector3(0.9, 0.9, -5), forward, 0, math.Inf(1), false, 0, nil},
+		{"torus", NewSDFTorus(linmath.NewVector3(0, 0, 0), 2, 0.5), linmath.NewVector3(2, 5, 0), linmath.NewVector3(0, -1, 0), 0, math.Inf(1), true, 4.5, linmath.NewVector3(0, 1, 0)},
+		{"torus hole", NewSDFTorus(linmath.NewVector3(0, 0, 0), 2, 0.5), linmath.NewVector3(0, 5, 0), linmath.NewVector3(0, -1, 0), 0, math.Inf(1), false, 0, nil},
+		{"capsule", NewSDFCapsule(linmath.NewVector3(-1, 0, 0), linmath.NewVector3(1, 0, 0), 0.5), linmath.NewVector3(0.5, 0, -5), forward, 0, math.Inf(1), true, 4.5, linmath.NewVector3(0, 0, -1)},
+		{"capsule cap", NewSDFCapsule(linmath.NewVector3(-1, 0, 0), linmath.NewVector3(1, 0, 0), 0.5), linmath.NewVector3(5, 0, 0), linmath.NewVector3(-1, 0, 0), 0, math.Inf(1), true, 3.5, linmath.NewVector3(1, 0, 0)},
+		{
+			"union", NewSmoothUnion(NewSDFSphere(linmath.NewVector3(-1.2, 0, 0), 1), NewSDFSphere(linmath.NewVector3(1.2, 0, 0), 1), 0),
+			linmath.NewVector3(0, 0, -5), forward, 0, math.Inf(1), false, 0, nil,
+		},
+		{
+			// The blob fills the gap between the spheres.
+			"smooth union", NewSmoothUnion(NewSDFSphere(linmath.NewVector3(-1.2, 0, 0), 1), NewSDFSphere(linmath.NewVector3(1.2, 0, 0), 1), 1),
+			linmath.NewVector3(0, 0, -5), forward, 0, math.Inf(1), true, 0, linmath.NewVector3(0, 0, -1),
+		},
+		{
+			"subtraction", NewSmoothSubtraction(NewSDFSphere(linmath.NewVector3(0, 0, 0), 1), NewSDFSphere(linmath.NewVector3(0, 0, -1), 0.5), 0),
+			linmath.NewVector3(0, 0, -5), forward, 0, math.Inf(1), true, 4.5, linmath.NewVector3(0, 0, -1),
+		},
+		{
+			"repetition", NewRepetition(NewSDFSphere(linmath.NewVector3(0, 0, 0), 0.4), linmath.NewVector3(1, 0, 0), [3]int{2, 0, 0}),
+			linmath.NewVector3(2, 0, -5), forward, 0, math.Inf(1), true, 4.6, linmath.NewVector3(0, 0, -1),
+		},
+		{
+			"repetition between copies", NewRepetition(NewSDFSphere(linmath.NewVector3(0, 0, 0), 0.4), linmath.NewVector3(1, 0, 0), [3]int{2, 0, 0}),
+			linmath.NewVector3(1.5, 0, -5), forward, 0, math.Inf(1), false, 0, nil,
+		},
+		{
+			"repetition past the last copy", NewRepetition(NewSDFSphere(linmath.NewVector3(0, 0, 0), 0.4), linmath.NewVector3(1, 0, 0), [3]int{2, 0, 0}),
+			linmath.NewVector3(3, 0, -5), forward, 0, math.Inf(1), false, 0, nil,
+		},
+		{
+			// A slab thin along Z, turned a quarter around Y at the height of the ray, where its end faces the ray.
+			// The end is a helicoid, so its normal leans.
+			"twist", NewTwist(NewSDFBox(linmath.NewVector3(0, 0, 0), linmath.NewVector3(2, 4, 0.5), 0), math.Pi/4),
+			linmath.NewVector3(0, 2, -5), forward, 0, math.Inf(1), true, 4, nil,
+		},
+		{
+			"untwisted", NewTwist(NewSDFBox(linmath.NewVector3(0, 0, 0), linmath.NewVector3(2, 4, 0.5), 0), math.Pi/4),
+			linmath.NewVector3(0, 0, -5), forward, 0, math.Inf(1), true, 4.75, linmath.NewVector3(0, 0, -1),
+		},
+	}
+
+	for _, ts := range tests {
+		surface := NewImplicitSurface(ts.inputSDF, material)
+		h, ok := surface.Intersect(linmath.NewRay(ts.inputOrigin, ts.inputDirection, 0), ts.inputMinT, ts.inputMaxT)
+
+		if ok != ts.expectedHit {
+			t.Fatalf("%s: expected a hit [%v] but have [%v] at [%v]", ts.name, ts.expectedHit, ok, h.T)
+		}
+
+		if !ok {
+			continue
+		}
+
+		// The smooth union has no simple closed form, so only its normal is checked.
+		if ts.expectedT > 0 && math.Abs(h.T-ts.expectedT) > 1e-4 {
+			t.Fatalf("%s: expected t [%v] but have [%v]", ts.name, ts.expectedT, h.T)
+		}
+
+		if ts.expectedNormal != nil && h.Normal.Subtraction(ts.expectedNormal).Length() > 1e-3 {
+			t.Fatalf("%s: expected the normal [%v] but have [%v]", ts.name, ts.expectedNormal, h.Normal)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"image/png"
+	"log"
+	"net"
+	"net/http"
+)
+
+// previewPage polls the progress and reloads the image once a second. It loads nothing
+// from outside the server, so the preview works without a network connection.
+const previewPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Render preview</title>
+<style>
+body { font-family: sans-serif; background: #222; color: #ddd; text-align: center; }
+img { image-rendering: pixelated; margin: 1em; max-width: 90vw; }
+progress { width: 600px; }
+</style>
+</head>
+<body>
+<img id="image" src="/image.png" alt="render">
+<div><progress id="bar" max="1" value="0"></progress></div>
+<div id="status">starting</div>
+<button id="cancel">Cancel</button>
+<script>
+const image = document.getElementById("image");
+const bar = document.getElementById("bar");
+const status = document.getElementById("status");
+const cancel = document.getElementById("cancel");
+
+function seconds(s) {
+	return s < 0 ? "unknown" : Math.round(s) + " s";
+}
+
+async function refresh() {
+	const p = await (await fetch("/progress", {cache: "no-store"})).json();
+	image.src = "/image.png?" + Date.now();
+	bar.value = p.fraction;
+	status.textContent = p.state + ", pass " + p.pass + " of " + p.passes +
+		", " + (100 * p.fraction).toFixed(1) + "%, elapsed " + seconds(p.elapsedSeconds) + ", left " + seconds(p.etaSeconds);
+	if (p.state === "rendering") {
+		setTimeout(refresh, 1000);
+	} else {
+		cancel.disabled = true;
+	}
+}
+
+cancel.onclick = () => fetch("/cancel", {method: "POST"});
+refresh();
+</script>
+</body>
+</html>
+`
+
+// previewServer serves a progressive render: the page, the current image, the progress as JSON and a cancel endpoint.
+type previewServer struct {
+	render *progressiveRender
+	cancel context.CancelFunc
+}
+
+func newPreviewServer(render *progressiveRender, cancel context.CancelFunc) http.Handler {
+	s := &previewServer{render, cancel}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.page)
+	mux.HandleFunc("/image.png", s.image)
+	mux.HandleFunc("/progress", s.progress)
+	mux.HandleFunc("/cancel", s.cancelRender)
+
+	return mux
+}
+
+func (s *previewServer) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(previewPage))
+}
+
+func (s *previewServer) image(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := png.Encode(w, s.render.Image()); err != nil {
+		log.Printf("preview: %v", err)
+	}
+}
+
+func (s *previewServer) progress(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := json.NewEncoder(w).Encode(s.render.Progress()); err != nil {
+		log.Printf("preview: %v", err)
+	}
+}
+
+func (s *previewServer) cancelRender(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "cancel needs a POST request", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	s.cancel()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Serve is a function that ray traces the scene progressively while the preview is served at the address.
+// Every pass adds one of the samples of the options to each pixel; the backend of the options is ignored.
+// The image is written to image.png once the render finishes or is canceled, and the server keeps
+// running until the context is canceled.
+func Serve(ctx context.Context, address string, scene *Scene, options *Options) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	renderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	render := newProgressiveRender(scene, options)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		render.Run(renderCtx)
+		log.Printf("render %s\n%s", render.Progress().State, &render.stats)
+
+		if err := WritePNG("image.png", render.Image()); err != nil {
+			log.Print(err)
+		}
+	}()
+
+	server := &http.Server{Handler: newPreviewServer(render, cancel)}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	log.Printf("preview at http://%s/", listener.Addr())
+
+	if err = server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+
+	<-done
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPreviewServer(t *testing.T) {
+	scene := &Scene{
+		Objects: []Object{NewSphere(*linmath.NewVector3(0, 0, 3), 1, *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil))},
+		Lights:  []Light{*NewAmbientLight(1)},
+		Camera:  NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0, 1, 0, 0, 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	render := newProgressiveRender(scene, &Options{Width: 40, Height: 30, Samples: 8})
+	server := httptest.NewServer(newPreviewServer(render, cancel))
+	defer server.Close()
+
+	render.Run(ctx)
+
+	response, err := http.Get(server.URL + "/progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p progress
+	err = json.NewDecoder(response.Body).Decode(&p)
+	_ = response.Body.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A pinhole camera sees the same image in every pass, so it renders just one.
+	if p.State != renderDone || p.Pass != 1 || p.Passes != 1 || p.Fraction != 1 || p.ETA != 0 {
+		t.Fatalf("unexpected progress [%+v]", p)
+	}
+
+	response, err = http.Get(server.URL + "/image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(response.Body)
+	_ = response.Body.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, g, b, _ := img.At(20, 15).RGBA(); r>>8 != 255 || g != 0 || b != 0 {
+		t.Fatalf("expected the red sphere in the middle of the image but have [%v]", img.At(20, 15))
+	}
+
+	response, err = http.Get(server.URL + "/cancel")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status [%v] but have [%v]", http.StatusMethodNotAllowed, response.StatusCode)
+	}
+
+	response, err = http.Post(server.URL+"/cancel", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent || ctx.Err() == nil {
+		t.Fatalf("expected the render to be canceled but have status [%v]", response.StatusCode)
+	}
+}
+
+func TestProgressiveRenderCanceled(t *testing.T) {
+	scene := &Scene{
+		Lights: []Light{*NewAmbientLight(1)},
+		Camera: NewCamera(linmath.NewVector3(0, 0, 0), linmath.NewIdentity(), 0.1, 1, 0, 0, 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	render := newProgressiveRender(scene, &Options{Samples: 8})
+	render.Run(ctx)
+
+	if p := render.Progress(); p.State != renderCanceled || p.Pass != 0 || p.Passes != 8 {
+		t.Fatalf("unexpected progress [%+v]", p)
+	}
+}
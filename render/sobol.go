@@ -0,0 +1,108 @@
+package render
+
+import "math/bits"
+
+// sobolDimensions is the number of Sobol dimensions sharing one shuffle of the sample index.
+// Further dimensions are padded with the same dimensions under another shuffle (Burley 2020),
+// which keeps every group stratified while the groups stay uncorrelated.
+const sobolDimensions = 4
+
+// sobolPolynomials are the primitive polynomials and initial direction numbers of Joe and Kuo (2008)
+// for the dimensions after the first, which is the van der Corput sequence.
+var sobolPolynomials = [sobolDimensions - 1]struct {
+	degree       int
+	coefficients uint32
+	initial      []uint32
+}{
+	{1, 0, []uint32{1}},
+	{2, 1, []uint32{1, 3}},
+	{3, 1, []uint32{1, 3, 1}},
+}
+
+// sobolDirections are the direction numbers of every dimension, one per bit of the index.
+var sobolDirections = newSobolDirections()
+
+// newSobolDirections is a function that expands the initial direction numbers with the recurrence of the polynomials.
+func newSobolDirections() (directions [sobolDimensions][32]uint32) {
+	for i := range directions[0] {
+		directions[0][i] = 1 << (31 - i)
+	}
+
+	for d, p := range sobolPolynomials {
+		v := &directions[d+1]
+
+		for i := 0; i < 32; i++ {
+			if i < p.degree {
+				v[i] = p.initial[i] << (31 - i)
+				continue
+			}
+
+			v[i] = v[i-p.degree] ^ v[i-p.degree]>>p.degree
+			for k := 1; k < p.degree; k++ {
+				if p.coefficients>>(p.degree-1-k)&1 == 1 {
+					v[i] ^= v[i-k]
+				}
+			}
+		}
+	}
+
+	return directions
+}
+
+// sobol is a function that returns the fixed-point coordinate of the Sobol point with the given index.
+func sobol(index uint32, dimension int) uint32 {
+	var v uint32
+
+	for i := 0; index != 0; i, index = i+1, index>>1 {
+		if index&1 == 1 {
+			v ^= sobolDirections[dimension][i]
+		}
+	}
+
+	return v
+}
+
+// laineKarras is a function that hashes the bits of x so that every bit only depends on itself and the bits below it,
+// which is the property an Owen scramble needs once the bits are reversed (Burley 2020).
+func laineKarras(x, seed uint32) uint32 {
+	x ^= x * 0x3d20adea
+	x += seed
+	x *= (seed >> 16) | 1
+	x ^= x * 0x05526c56
+	x ^= x * 0x53a22864
+
+	return x
+}
+
+// owenScramble is a function that applies a nested uniform scramble to a fixed-point number in [0, 1):
+// every digit is flipped depending on the digits above it, which randomizes the points but keeps their stratification.
+func owenScramble(x, seed uint32) uint32 {
+	return bits.Reverse32(laineKarras(bits.Reverse32(x), seed))
+}
+
+// owenSobol is a function that returns a dimension of an Owen-scrambled, shuffled Sobol point.
+// Shuffling the index with a scramble of its own keeps the first 2^k points of any seed a complete net.
+func owenSobol(index uint32, dimension int, seed uint64) float64 {
+	group, d := uint64(dimension/sobolDimensions), dimension%sobolDimensions
+	shuffled := owenScramble(index, uint32(hash(seed, group)))
+
+	return float64(owenScramble(sobol(shuffled, d), uint32(hash(seed, group, uint64(d)+1)))) / (1 << 32)
+}
+
+// sobolSampler draws the points of every pixel from its own scramble of the Sobol sequence.
+type sobolSampler struct {
+	seed      uint64
+	pixel     uint64
+	index     uint32
+	dimension int
+}
+
+func (s *sobolSampler) Start(x, y, index int) {
+	s.pixel, s.index, s.dimension = hash(s.seed, uint64(x), uint64(y)), uint32(index), 0
+}
+
+func (s *sobolSampler) Float64() float64 {
+	s.dimension++
+
+	return owenSobol(s.index, s.dimension-1, s.pixel)
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+type Sphere struct {
+	center   linmath.Vector3
+	radius   float64
+	material Material
+}
+
+func NewSphere(center linmath.Vector3, radius float64, material Material) *Sphere {
+	return &Sphere{center, radius, material}
+}
+
+// IntersectRaySphere is a function that computes the nearest intersection of a ray and a sphere
+// strictly between minT and maxT. A ray starting inside the sphere hits it from within,
+// and a tangent ray hits it at the single touching point.
+func IntersectRaySphere(ray *linmath.Ray, sphere *Sphere, minT, maxT float64) (Hit, bool) {
+	t0, t1, ok := sphere.roots(ray)
+	if !ok {
+		return Hit{}, false
+	}
+
+	for _, t := range [2]float64{t0, t1} {
+		if minT < t && t < maxT {
+			return sphere.hit(ray, t), true
+		}
+	}
+
+	return Hit{}, false
+}
+
+// roots is a method that returns both parameters at which the line of the ray meets the sphere.
+// The quadratic is solved relative to the point of the line closest to the center, which keeps
+// its coefficients small for spheres far from the ray origin.
+func (s *Sphere) roots(ray *linmath.Ray) (t0, t1 float64, ok bool) {
+	d := &ray.Direction
+	a := d.Dot(d)
+	closest := -ray.Origin.Subtraction(&s.center).Dot(d) / a
+	co := ray.At(closest).Subtraction(&s.center)
+
+	// at^2 + bt + c = 0
+	t0, t1, ok = linmath.SolveQuadratic(a, 2*co.Dot(d), co.Dot(co)-s.radius*s.radius)
+
+	return closest + t0, closest + t1, ok
+}
+
+// hit is a method that describes the hit at t. Texture coordinates wrap around the Y axis and run from the north pole down.
+func (s *Sphere) hit(ray *linmath.Ray, t float64) Hit {
+	point := ray.At(t)
+	normal := point.Subtraction(&s.center).DivideOnScalar(s.radius)
+	u, v := sphereUV(normal)
+
+	return Hit{t, point, normal, u, v, s.material.albedo(s.material.color, u, v), &s.material}
+}
+
+func sphereUV(normal *linmath.Vector3) (u, v float64) {
+	u = math.Atan2(normal.Z(), normal.X()) / (2 * math.Pi)
+	if u < 0 {
+		u++
+	}
+
+	return u, math.Acos(math.Min(math.Max(normal.Y(), -1), 1)) / math.Pi
+}
+
+func (s *Sphere) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	return IntersectRaySphere(ray, s, minT, maxT)
+}
+
+// Intervals is a method that returns the span of the ray between both roots.
+func (s *Sphere) Intervals(ray *linmath.Ray) []interval {
+	t0, t1, ok := s.roots(ray)
+
+	// A tangent ray touches the sphere without entering any volume.
+	if !ok || t0 == t1 {
+		return nil
+	}
+
+	return []interval{{s.hit(ray, t0), s.hit(ray, t1)}}
+}
+
+func (s *Sphere) Bounds() *linmath.AABB {
+	return linmath.NewAABB(
+		s.center.Subtraction(linmath.Splat(s.radius)),
+		s.center.Add(linmath.Splat(s.radius)),
+	)
+}
+
+// Tessellate is a method that approximates the sphere with a latitude-longitude grid of triangles.
+func (s *Sphere) Tessellate() *Mesh {
+	const rings, segments = 24, 48
+
+	var (
+		vertices  []linmath.Vector3
+		normals   []linmath.Vector3
+		uvs       [][2]float64
+		triangles [][3]int
+	)
+
+	for i := 0; i <= rings; i++ {
+		sinTheta, cosTheta := math.Sincos(math.Pi * float64(i) / rings)
+
+		for j := 0; j <= segments; j++ {
+			sinPhi, cosPhi := math.Sincos(2 * math.Pi * float64(j) / segments)
+			normal := linmath.NewVector3(sinTheta*cosPhi, cosTheta, sinTheta*sinPhi)
+
+			normals = append(normals, *normal)
+			vertices = append(vertices, *s.center.Add(normal.MultiplyOnScalar(s.radius)))
+			uvs = append(uvs, [2]float64{float64(j) / segments, float64(i) / rings})
+		}
+	}
+
+	for i := 0; i < rings; i++ {
+		for j := 0; j < segments; j++ {
+			a := i*(segments+1) + j
+			b, c, d := a+segments+1, a+segments+2, a+1
+
+			// The triangles touching a pole would be degenerate.
+			if i != rings-1 {
+				triangles = append(triangles, [3]int{a, c, b})
+			}
+
+			if i != 0 {
+				triangles = append(triangles, [3]int{a, d, c})
+			}
+		}
+	}
+
+	return NewMesh(vertices, normals, uvs, nil, triangles, s.material)
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestIntersectRaySphere(t *testing.T) {
+	unitSphere := NewSphere(*linmath.NewVector3(0, 0, 0), 1, *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil))
+	distantSphere := NewSphere(*linmath.NewVector3(0, 0, 1e6), 1, *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil))
+
+	tests := []struct {
+		name           string
+		inputRay       *linmath.Ray
+		inputSphere    *Sphere
+		inputMinT      float64
+		expectedOk     bool
+		expectedT      float64
+		expectedNormal *linmath.Vector3
+	}{
+		{"head-on", linmath.NewRay(linmath.NewVector3(0, 0, -5), linmath.NewVector3(0, 0, 1), 0), unitSphere, 0, true, 4, linmath.NewVector3(0, 0, -1)},
+		{"unnormalized direction", linmath.NewRay(linmath.NewVector3(0, 0, -5), linmath.NewVector3(0, 0, 2), 0), unitSphere, 0, true, 2, linmath.NewVector3(0, 0, -1)},
+		{"miss", linmath.NewRay(linmath.NewVector3(0, 2, -5), linmath.NewVector3(0, 0, 1), 0), unitSphere, 0, false, 0, nil},
+		{"behind", linmath.NewRay(linmath.NewVector3(0, 0, 5), linmath.NewVector3(0, 0, 1), 0), unitSphere, 0, false, 0, nil},
+		{"from inside", linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 1), 0), unitSphere, 0, true, 1, linmath.NewVector3(0, 0, 1)},
+		{"from inside off center", linmath.NewRay(linmath.NewVector3(0, 0.6, 0), linmath.NewVector3(0, 0, -1), 0), unitSphere, 0, true, 0.8, linmath.NewVector3(0, 0.6, -0.8)},
+		{"from the surface", linmath.NewRay(linmath.NewVector3(0, 0, -1), linmath.NewVector3(0, 0, 1), 0), unitSphere, 1e-9, true, 2, linmath.NewVector3(0, 0, 1)},
+		{"tangent", linmath.NewRay(linmath.NewVector3(1, 0, -5), linmath.NewVector3(0, 0, 1), 0), unitSphere, 0, true, 5, linmath.NewVector3(1, 0, 0)},
+		{"grazing", linmath.NewRay(linmath.NewVector3(0.999999, 0, -5), linmath.NewVector3(0, 0, 1), 0), unitSphere, 0, true, 5 - math.Sqrt(1-0.999999*0.999999), nil},
+		{"grazing distant", linmath.NewRay(linmath.NewVector3(0.999999, 0, 0), linmath.NewVector3(0, 0, 1), 0), distantSphere, 0, true, 1e6 - math.Sqrt(1-0.999999*0.999999), nil},
+	}
+
+	for _, ts := range tests {
+		h, ok := IntersectRaySphere(ts.inputRay, ts.inputSphere, ts.inputMinT, math.Inf(1))
+
+		if ok != ts.expectedOk {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expectedOk, ok)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if math.Abs(h.T-ts.expectedT) > 1e-9 {
+			t.Fatalf("%s: expected t [%v] but have [%v]", ts.name, ts.expectedT, h.T)
+		}
+
+		// The hit point has to lie on the surface, which is what cancellation in the roots breaks first.
+		if distance := h.Point.Subtraction(&ts.inputSphere.center).Length(); math.Abs(distance-ts.inputSphere.radius) > 1e-9 {
+			t.Fatalf("%s: expected the hit point on the surface but it is [%v] from the center", ts.name, distance)
+		}
+
+		if ts.expectedNormal != nil && h.Normal.Subtraction(ts.expectedNormal).Length() > 1e-9 {
+			t.Fatalf("%s: expected normal [%v] but have [%v]", ts.name, ts.expectedNormal, h.Normal)
+		}
+	}
+}
+
+func TestSphereIntervals(t *testing.T) {
+	unitSphere := NewSphere(*linmath.NewVector3(0, 0, 0), 1, *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil))
+
+	tests := []struct {
+		name          string
+		inputRay      *linmath.Ray
+		expectedSpans int
+		expectedEnter float64
+		expectedExit  float64
+	}{
+		{"through", linmath.NewRay(linmath.NewVector3(0, 0, -5), linmath.NewVector3(0, 0, 1), 0), 1, 4, 6},
+		{"from inside", linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 1), 0), 1, -1, 1},
+		{"tangent", linmath.NewRay(linmath.NewVector3(1, 0, -5), linmath.NewVector3(0, 0, 1), 0), 0, 0, 0},
+		{"miss", linmath.NewRay(linmath.NewVector3(0, 2, -5), linmath.NewVector3(0, 0, 1), 0), 0, 0, 0},
+	}
+
+	for _, ts := range tests {
+		intervals := unitSphere.Intervals(ts.inputRay)
+
+		if len(intervals) != ts.expectedSpans {
+			t.Fatalf("%s: expected [%v] spans but have [%v]", ts.name, ts.expectedSpans, len(intervals))
+		}
+
+		if ts.expectedSpans == 0 {
+			continue
+		}
+
+		if math.Abs(intervals[0].enter.T-ts.expectedEnter) > 1e-9 || math.Abs(intervals[0].exit.T-ts.expectedExit) > 1e-9 {
+			t.Fatalf("%s: expected [%v %v] but have [%v %v]", ts.name, ts.expectedEnter, ts.expectedExit, intervals[0].enter.T, intervals[0].exit.T)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Statistics counts the work done by a render. A nil *Statistics counts nothing,
+// so code shared by both backends can report to it unconditionally.
+// It is not safe for concurrent use; every goroutine keeps its own and they are merged with add.
+type Statistics struct {
+	PrimaryRays       int64
+	ShadowRays        int64
+	ReflectionRays    int64
+	IntersectionTests int64 // rays tested against objects of the scene, bounds checks included
+	Triangles         int64 // triangles rasterized after culling
+	SkippedObjects    int64 // objects the rasterizer cannot draw because they cannot be tessellated
+	Phases            []Phase
+}
+
+// Phase is a named stage of a render and how long it took.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+func (s *Statistics) countPrimaryRay() {
+	if s != nil {
+		s.PrimaryRays++
+	}
+}
+
+func (s *Statistics) countShadowRay() {
+	if s != nil {
+		s.ShadowRays++
+	}
+}
+
+func (s *Statistics) countReflectionRay() {
+	if s != nil {
+		s.ReflectionRays++
+	}
+}
+
+func (s *Statistics) countIntersectionTests(n int) {
+	if s != nil {
+		s.IntersectionTests += int64(n)
+	}
+}
+
+func (s *Statistics) countTriangle() {
+	if s != nil {
+		s.Triangles++
+	}
+}
+
+func (s *Statistics) countSkippedObject() {
+	if s != nil {
+		s.SkippedObjects++
+	}
+}
+
+// addPhase is a method that records a stage that started at the given time and has just ended.
+func (s *Statistics) addPhase(name string, start time.Time) {
+	if s != nil {
+		s.Phases = append(s.Phases, Phase{name, time.Since(start)})
+	}
+}
+
+// add is a method that adds the counters of another render, such as one running on another goroutine.
+func (s *Statistics) add(s2 *Statistics) {
+	s.PrimaryRays += s2.PrimaryRays
+	s.ShadowRays += s2.ShadowRays
+	s.ReflectionRays += s2.ReflectionRays
+	s.IntersectionTests += s2.IntersectionTests
+	s.Triangles += s2.Triangles
+	s.SkippedObjects += s2.SkippedObjects
+	s.Phases = append(s.Phases, s2.Phases...)
+}
+
+func (s *Statistics) Rays() int64 {
+	return s.PrimaryRays + s.ShadowRays + s.ReflectionRays
+}
+
+func (s *Statistics) Duration() time.Duration {
+	var total time.Duration
+	for _, p := range s.Phases {
+		total += p.Duration
+	}
+
+	return total
+}
+
+// RaysPerSecond is a method that returns the ray throughput over the phases of the render.
+func (s *Statistics) RaysPerSecond() float64 {
+	seconds := s.Duration().Seconds()
+	if seconds == 0 {
+		return 0
+	}
+
+	return float64(s.Rays()) / seconds
+}
+
+// String is a method that returns a multi-line summary for people to read.
+func (s *Statistics) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "rays:               %d (%.0f per second)\n", s.Rays(), s.RaysPerSecond())
+	fmt.Fprintf(&b, "  primary:          %d\n", s.PrimaryRays)
+	fmt.Fprintf(&b, "  shadow:           %d\n", s.ShadowRays)
+	fmt.Fprintf(&b, "  reflection:       %d\n", s.ReflectionRays)
+	fmt.Fprintf(&b, "intersection tests: %d\n", s.IntersectionTests)
+
+	if s.Triangles > 0 {
+		fmt.Fprintf(&b, "triangles:          %d\n", s.Triangles)
+	}
+
+	if s.SkippedObjects > 0 {
+		fmt.Fprintf(&b, "skipped objects:    %d\n", s.SkippedObjects)
+	}
+
+	for _, p := range s.Phases {
+		fmt.Fprintf(&b, "%-19s %v\n", p.Name+":", p.Duration.Round(time.Millisecond))
+	}
+
+	fmt.Fprintf(&b, "total:              %v\n", s.Duration().Round(time.Millisecond))
+
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"github.com/UnTea/ComputerGraphics/mesh"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// stlHeaderSize and stlTriangleSize are the sizes in bytes of the header and of every triangle of a binary STL file.
+const (
+	stlHeaderSize   = 84
+	stlTriangleSize = 50
+)
+
+// LoadSTL is a function that reads a mesh from an STL file.
+func LoadSTL(name string, material Material, normals NormalMode) (*Mesh, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return DecodeSTL(file, material, normals)
+}
+
+// DecodeSTL is a function that reads a mesh from STL data, ASCII or binary. STL stores every triangle with
+// its own corners, so corners at the same position are welded into one vertex to make an indexed mesh
+// that can be shaded smoothly, and triangles that collapse are dropped. The facet normals of the file
+// are ignored and computed from the corners, which the format winds counterclockwise around them.
+func DecodeSTL(r io.Reader, material Material, normals NormalMode) (*Mesh, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var corners []linmath.Vector3
+
+	// A binary file may start with "solid" too, but its size always matches the triangle count of its header.
+	if len(data) >= stlHeaderSize && len(data) == stlHeaderSize+stlTriangleSize*int(binary.LittleEndian.Uint32(data[80:])) {
+		corners = readBinarySTL(data)
+	} else if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid")) {
+		if corners, err = readASCIISTL(data); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, errors.New("stl: neither an ASCII file nor a binary file of the right size")
+	}
+
+	triangles := make([][3]int, len(corners)/3)
+	for i := range triangles {
+		triangles[i] = [3]int{3 * i, 3*i + 1, 3*i + 2}
+	}
+
+	welded, _ := (&mesh.Mesh{Vertices: corners, Triangles: triangles}).Weld(0)
+	welded = welded.RemoveDegenerate(0)
+
+	return newLoadedMesh(welded.Vertices, nil, nil, welded.Triangles, material, normals), nil
+}
+
+// readBinarySTL is a function that returns the corners of every triangle of a binary STL file.
+func readBinarySTL(data []byte) []linmath.Vector3 {
+	count := int(binary.LittleEndian.Uint32(data[80:]))
+	corners := make([]linmath.Vector3, 0, 3*count)
+
+	for i := 0; i < count; i++ {
+		// Each triangle is a normal, three corners and a two-byte attribute.
+		triangle := data[stlHeaderSize+i*stlTriangleSize:]
+
+		for c := 1; c <= 3; c++ {
+			var v [3]float64
+			for axis := range v {
+				v[axis] = float64(math.Float32frombits(binary.LittleEndian.Uint32(triangle[12*c+4*axis:])))
+			}
+
+			corners = append(corners, *linmath.NewVector3(v[0], v[1], v[2]))
+		}
+	}
+
+	return corners
+}
+
+// readASCIISTL is a function that returns the corners of every triangle of an ASCII STL file,
+// which lists them as "vertex x y z" inside facets; the other keywords carry nothing the mesh needs.
+func readASCIISTL(data []byte) ([]linmath.Vector3, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Split(bufio.ScanWords)
+
+	var corners []linmath.Vector3
+
+	for scanner.Scan() {
+		if scanner.Text() != "vertex" {
+			continue
+		}
+
+		var v [3]float64
+		for axis := range v {
+			if !scanner.Scan() {
+				return nil, fmt.Errorf("stl: vertex %d: %w", len(corners), io.ErrUnexpectedEOF)
+			}
+
+			var err error
+			if v[axis], err = strconv.ParseFloat(scanner.Text(), 64); err != nil {
+				return nil, fmt.Errorf("stl: vertex %d: %w", len(corners), err)
+			}
+		}
+
+		corners = append(corners, *linmath.NewVector3(v[0], v[1], v[2]))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(corners)%3 != 0 {
+		return nil, fmt.Errorf("stl: %d vertices do not make whole triangles", len(corners))
+	}
+
+	return corners, nil
+}
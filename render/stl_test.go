@@ -0,0 +1,98 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"strings"
+	"testing"
+)
+
+// stlTetrahedron are the corners of a tetrahedron, wound counterclockwise seen from outside.
+var stlTetrahedron = [][3][3]float32{
+	{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}},
+	{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}},
+	{{0, 0, 0}, {0, 0, 1}, {0, 1, 0}},
+	{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+}
+
+func TestDecodeSTL(t *testing.T) {
+	var ascii strings.Builder
+	ascii.WriteString("solid tetrahedron\n")
+
+	for _, triangle := range stlTetrahedron {
+		ascii.WriteString("  facet normal 0 0 0\n    outer loop\n")
+		for _, v := range triangle {
+			fmt.Fprintf(&ascii, "      vertex %g %g %g\n", v[0], v[1], v[2])
+		}
+
+		ascii.WriteString("    endloop\n  endfacet\n")
+	}
+
+	ascii.WriteString("endsolid tetrahedron\n")
+
+	// The header of this binary file starts with "solid" like an ASCII one.
+	var binaryFile bytes.Buffer
+	binaryFile.WriteString("solid but binary")
+	binaryFile.Write(make([]byte, 80-binaryFile.Len()))
+	_ = binary.Write(&binaryFile, binary.LittleEndian, uint32(len(stlTetrahedron)))
+
+	for _, triangle := range stlTetrahedron {
+		_ = binary.Write(&binaryFile, binary.LittleEndian, [3]float32{})
+		_ = binary.Write(&binaryFile, binary.LittleEndian, triangle)
+		_ = binary.Write(&binaryFile, binary.LittleEndian, uint16(0))
+	}
+
+	files := map[string][]byte{"ascii": []byte(ascii.String()), "binary": binaryFile.Bytes()}
+	material := *NewMaterial(*NewColor(128, 128, 128, 255), -1, 0, nil)
+
+	for name, data := range files {
+		mesh, err := DecodeSTL(bytes.NewReader(data), material, SmoothNormals)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// The twelve corners are welded into the four vertices of the tetrahedron.
+		if len(mesh.vertices) != 4 || len(mesh.triangles) != 4 {
+			t.Fatalf("%s: expected [4] vertices and [4] triangles but have [%v] and [%v]", name, len(mesh.vertices), len(mesh.triangles))
+		}
+
+		// The corner at the origin is surrounded by three right triangles of the same area facing -X, -Y and -Z.
+		expected := linmath.NewVector3(-1, -1, -1).Normal()
+		if !equalVectors(&mesh.normals[0], expected) {
+			t.Fatalf("%s: expected [%v] but have [%v]", name, expected, mesh.normals[0])
+		}
+
+		flat, err := DecodeSTL(bytes.NewReader(data), material, FlatNormals)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		h, ok := flat.Intersect(linmath.NewRay(linmath.NewVector3(0.2, 0.2, -1), linmath.NewVector3(0, 0, 1), 0), 0, math.Inf(1))
+		if !ok || !equalVectors(h.Normal, linmath.NewVector3(0, 0, -1)) {
+			t.Fatalf("%s: expected to hit the face at z = 0 with normal [0 0 -1] but have [%v] [%v]", name, ok, h.Normal)
+		}
+	}
+}
+
+func TestDecodeSTLErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputData     string
+		expectedError string
+	}{
+		{"neither", "mesh", "neither"},
+		{"short binary", strings.Repeat("\x00", 80) + "\x02\x00\x00\x00", "neither"},
+		{"partial triangle", "solid s\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nendloop\nendfacet\nendsolid s\n", "whole triangles"},
+		{"bad number", "solid s\nvertex 0 x 0\n", "vertex 0"},
+	}
+
+	for _, ts := range tests {
+		_, err := DecodeSTL(strings.NewReader(ts.inputData), Material{}, SmoothNormals)
+		if err == nil || !strings.Contains(err.Error(), ts.expectedError) {
+			t.Fatalf("%s: expected an error containing [%v] but have [%v]", ts.name, ts.expectedError, err)
+		}
+	}
+}
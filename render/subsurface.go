@@ -0,0 +1,159 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+const (
+	subsurfaceProbes = 48 // probe rays gathering the light under a shaded point, a multiple of the 3 channels
+	subsurfaceHits   = 4  // surfaces of the material a probe ray gathers light from at most
+)
+
+// subsurface is a function that returns the color of a translucent surface at the hit: the light entering the surface
+// nearby, spread under it by the Christensen–Burley diffusion profile of each channel, plus the ambient light
+// and the highlight at the hit. Points nearby are found by probe rays cast along the normal through a disk
+// around the hit, at radii distributed like the profile of each channel in turn. Every point of the material
+// a probe crosses counts, weighed by the chance of a probe through it with any channel, so the light crosses
+// thin parts of an object but doesn't jump to other objects. Light doesn't wrap around edges much sharper
+// than the mean free path, whose far side the probes along the normal hardly find.
+func subsurface(ray *linmath.Ray, h *Hit, normal, view *linmath.Vector3, specular float64, scene *Scene, sampler Sampler, stats *Statistics) *Color {
+	ambient, _, highlight := lightingTerms(h.Point, normal, view, specular, scene.Lights, scene.Objects, scene.Volumes, ray.Time, sampler, stats)
+
+	albedo := [3]float64{float64(h.Color.r) / 255, float64(h.Color.g) / 255, float64(h.Color.b) / 255}
+
+	// Probes reach as far as the radius within which all but a thousandth of the light of every channel leaves.
+	var radii [3]float64
+	reach := 0.
+	for c := range radii {
+		radii[c] = burleyRadius(albedo[c], h.Material.meanFreePath)
+		reach = math.Max(reach, 3*radii[c]*math.Log(1000))
+	}
+
+	tangent, bitangent := orthonormalBasis(normal)
+
+	// Probes turn around the normal by the golden ratio from a random start, which spreads them evenly.
+	rotation := sampler.Float64()
+
+	var scattered [3]float64
+	for i := 0; i < subsurfaceProbes; i++ {
+		// Probes take turns over the channels and those of each channel spread their radii over strata of its profile.
+		r := sampleBurley((float64(i/3)+sampler.Float64())/(subsurfaceProbes/3), radii[i%3])
+		if r >= reach {
+			continue
+		}
+
+		_, turn := math.Modf(rotation + float64(i)*(math.Sqrt(5)-1)/2)
+		sin, cos := math.Sincos(2 * math.Pi * turn)
+
+		// The probe crosses the sphere of the reach around the hit through the sampled point of the disk.
+		height := math.Sqrt(reach*reach - r*r)
+		origin := h.Point.Add(tangent.MultiplyOnScalar(r * cos)).Add(bitangent.MultiplyOnScalar(r * sin)).Add(normal.MultiplyOnScalar(height))
+
+		for _, q := range probeSurface(linmath.NewRay(origin, normal.Negative(), ray.Time), 2*height, h.Material, scene.Objects, stats) {
+			// The chance of a probe through the point is that of its projection on the disk with any channel,
+			// spread over the surface by the slant of the surface to the disk.
+			offset := q.Point.Subtraction(h.Point)
+			projected := math.Max(offset.Subtraction(normal.MultiplyOnScalar(offset.Dot(normal))).Length(), 1e-9*reach)
+			distance := math.Max(offset.Length(), 1e-9*reach)
+
+			pdf := 0.
+			for c := range radii {
+				pdf += math.Abs(q.Normal.Dot(normal)) * burleyRadialPDF(projected, radii[c]) / (2 * math.Pi * projected) / 3
+			}
+
+			if pdf <= 0 {
+				continue
+			}
+
+			_, diffuse, _ := lightingTerms(q.Point, q.Normal, view, -1, scene.Lights, scene.Objects, scene.Volumes, ray.Time, sampler, stats)
+			for c := range scattered {
+				scattered[c] += burleyProfile(distance, radii[c]) * diffuse / pdf
+			}
+		}
+	}
+
+	intensity := func(c int) float64 {
+		return ambient + highlight + scattered[c]/subsurfaceProbes
+	}
+
+	return NewColor(
+		clampChannel(float64(h.Color.r)*intensity(0)),
+		clampChannel(float64(h.Color.g)*intensity(1)),
+		clampChannel(float64(h.Color.b)*intensity(2)),
+		h.Color.a,
+	)
+}
+
+// probeSurface is a function that returns the first subsurfaceHits hits of the ray on the material up to maxT.
+func probeSurface(ray *linmath.Ray, maxT float64, material *Material, objects []Object, stats *Statistics) []Hit {
+	var hits []Hit
+
+	for minT := 0.; len(hits) < subsurfaceHits; {
+		h, ok := ClosestIntersection(ray, minT, maxT, objects, stats)
+		if !ok {
+			break
+		}
+
+		if h.Material == material {
+			hits = append(hits, h)
+		}
+
+		minT = h.T + shadowBias
+	}
+
+	return hits
+}
+
+// burleyRadius is a function that returns the shape parameter of the diffusion profile of a channel
+// from its albedo and the mean free path, by the fit of Christensen and Burley for light entering head on.
+func burleyRadius(albedo, meanFreePath float64) float64 {
+	return meanFreePath / (1.85 - albedo + 7*math.Pow(math.Abs(albedo-0.8), 3))
+}
+
+// burleyProfile is a function that returns the share of light entering the surface at the distance that leaves it,
+// per unit of area: (exp(-r / d) + exp(-r / 3d)) / (8 Pi d r), which adds up to 1 over the plane.
+func burleyProfile(r, d float64) float64 {
+	return (math.Exp(-r/d) + math.Exp(-r/(3*d))) / (8 * math.Pi * d * r)
+}
+
+// burleyRadialPDF is a function that returns the density of the radii drawn by sampleBurley, the profile
+// summed around the circle of the radius.
+func burleyRadialPDF(r, d float64) float64 {
+	return (math.Exp(-r/d) + math.Exp(-r/(3*d))) / (4 * d)
+}
+
+// sampleBurley is a function that maps a uniform random number to a radius distributed like the diffusion profile
+// by inverting its cumulative distribution 1 - (exp(-x) + 3 exp(-x / 3)) / 4 of x = r / d with Newton's method,
+// kept within the radii of the two exponentials it mixes. Unlike picking one of them, this keeps stratified numbers stratified.
+func sampleBurley(u, d float64) float64 {
+	low, high := -math.Log(1-u), -3*math.Log(1-u)
+	x := high
+
+	for i := 0; i < 32 && high-low > 1e-12*high; i++ {
+		cdf := 1 - (math.Exp(-x)+3*math.Exp(-x/3))/4
+		if cdf < u {
+			low = x
+		} else {
+			high = x
+		}
+
+		x -= (cdf - u) / ((math.Exp(-x) + math.Exp(-x/3)) / 4)
+		if x <= low || x >= high {
+			x = (low + high) / 2
+		}
+	}
+
+	return x * d
+}
+
+// orthonormalBasis is a function that returns two unit vectors perpendicular to the unit normal and to each other,
+// by the branchless construction of Duff et al.
+func orthonormalBasis(n *linmath.Vector3) (*linmath.Vector3, *linmath.Vector3) {
+	sign := math.Copysign(1, n.Z())
+	a := -1 / (sign + n.Z())
+	b := n.X() * n.Y() * a
+
+	return linmath.NewVector3(1+sign*n.X()*n.X()*a, sign*b, -sign*n.X()),
+		linmath.NewVector3(b, sign+n.Y()*n.Y()*a, -n.Y())
+}
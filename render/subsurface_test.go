@@ -0,0 +1,125 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestBurleyProfile(t *testing.T) {
+	d := 0.3
+
+	// The profile adds up to 1 over the plane and the radii are drawn with its density around each circle.
+	n := 200000
+	step := 60 * d / float64(n)
+	sum := 0.
+	for i := 0; i < n; i++ {
+		r := (float64(i) + 0.5) * step
+		profile := 2 * math.Pi * r * burleyProfile(r, d)
+
+		if pdf := burleyRadialPDF(r, d); math.Abs(profile-pdf) > 1e-12 {
+			t.Fatalf("expected density [%v] but have [%v] at [%v]", profile, pdf, r)
+		}
+
+		sum += profile * step
+	}
+
+	if math.Abs(sum-1) > 1e-6 {
+		t.Fatalf("expected [1] but have [%v]", sum)
+	}
+
+	// Radii are drawn by inverting the cumulative distribution of the profile.
+	for _, u := range []float64{0, 1e-9, 0.1, 0.25, 0.5, 0.9, 0.999, 1 - 1e-12} {
+		x := sampleBurley(u, d) / d
+
+		if have := 1 - (math.Exp(-x)+3*math.Exp(-x/3))/4; math.Abs(have-u) > 1e-9 {
+			t.Fatalf("expected [%v] but have [%v]", u, have)
+		}
+	}
+}
+
+func TestOrthonormalBasis(t *testing.T) {
+	for _, n := range []*linmath.Vector3{
+		linmath.NewVector3(0, 0, 1),
+		linmath.NewVector3(0, 0, -1),
+		linmath.NewVector3(1, 0, 0),
+		linmath.NewVector3(1, -2, 3).Normal(),
+		linmath.NewVector3(-0.001, 0.001, -1).Normal(),
+	} {
+		tangent, bitangent := orthonormalBasis(n)
+
+		for _, dot := range []float64{tangent.Dot(n), bitangent.Dot(n), tangent.Dot(bitangent), tangent.Length() - 1, bitangent.Length() - 1} {
+			if math.Abs(dot) > 1e-12 {
+				t.Fatalf("expected an orthonormal basis around [%v] but have [%v] and [%v]", n, tangent, bitangent)
+			}
+		}
+	}
+}
+
+func TestSubsurface(t *testing.T) {
+	gray := *NewColor(200, 200, 200, 255)
+	skin := *NewColor(230, 150, 120, 255)
+
+	// A wall facing the camera, whose light under the surface comes from everywhere around a hit as much as it leaves.
+	wall := func(material *Material) Object {
+		return NewMesh(
+			[]linmath.Vector3{*linmath.NewVector3(-50, -50, 5), *linmath.NewVector3(-50, 50, 5), *linmath.NewVector3(50, 50, 5), *linmath.NewVector3(50, -50, 5)},
+			nil,
+			nil,
+			nil,
+			[][3]int{{0, 1, 2}, {0, 2, 3}},
+			*material,
+		)
+	}
+
+	// A thin slab lit only from behind, whose light only a translucent material lets through.
+	slab := func(material *Material) Object {
+		return NewInstance(NewSphere(*linmath.NewVector3(0, 0, 0), 1, *material), linmath.NewTranslation(0, 0, 5).Multiply(linmath.NewScale(2, 2, 0.05)), nil)
+	}
+
+	front := []Light{*NewAmbientLight(0.1), *NewDirectionalLight(0.8, linmath.NewVector3(0, 0, -1))}
+	behind := []Light{*NewAmbientLight(0.1), *NewDirectionalLight(0.8, linmath.NewVector3(0, 0, 1))}
+
+	tests := []struct {
+		name        string
+		inputObject func(*Material) Object
+		inputColor  Color
+		inputLights []Light
+		tolerance   float64
+	}{
+		{"gray wall lit from the front", wall, gray, front, 1},
+		{"colored wall lit from the front", wall, skin, front, 20},
+		{"slab lit from behind", slab, gray, behind, -1},
+	}
+
+	ray := linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 1), 0)
+
+	for _, ts := range tests {
+		trace := func(material *Material) Color {
+			scene := &Scene{Objects: []Object{ts.inputObject(material)}, Lights: ts.inputLights}
+			sampler := NewSampler(SobolSampler, 3)
+			sampler.Start(0, 0, 0)
+
+			return TraceRay(ray, 0, math.Inf(1), scene, 0, sampler, nil).Color
+		}
+
+		have := trace(NewSubsurfaceMaterial(ts.inputColor, 0.2, -1))
+		opaque := trace(NewMaterial(ts.inputColor, -1, 0, nil))
+
+		if ts.tolerance < 0 {
+			// The opaque slab only gets the ambient light, the translucent one lets the light behind it through.
+			if int(have.r) <= int(opaque.r)+20 {
+				t.Fatalf("%s: expected more than [%v] but have [%v]", ts.name, opaque, have)
+			}
+
+			continue
+		}
+
+		// Light spread evenly under a flat surface leaves it as evenly as it entered.
+		for _, channel := range [][2]uint8{{have.r, opaque.r}, {have.g, opaque.g}, {have.b, opaque.b}} {
+			if math.Abs(float64(channel[0])-float64(channel[1])) > ts.tolerance {
+				t.Fatalf("%s: expected [%v] but have [%v]", ts.name, opaque, have)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+)
+
+// Torus is a ring lying in the XZ plane: the points at the minor radius from a circle of the major radius.
+type Torus struct {
+	center   linmath.Vector3
+	major    float64
+	minor    float64
+	material Material
+}
+
+func NewTorus(center linmath.Vector3, major, minor float64, material Material) *Torus {
+	return &Torus{center, major, minor, material}
+}
+
+// Intersect is a method that solves the quartic of the ray with linmath.SolveQuartic. The ray is taken
+// with a unit direction from its point closest to the center, which keeps the coefficients of the quartic
+// of the same size as the torus wherever the ray starts.
+func (t *Torus) Intersect(ray *linmath.Ray, minT, maxT float64) (Hit, bool) {
+	if !t.Bounds().IntersectRay(&ray.Origin, &ray.Direction, minT, maxT) {
+		return Hit{}, false
+	}
+
+	speed := ray.Direction.Length()
+	u := ray.Direction.DivideOnScalar(speed)
+	closest := t.center.Subtraction(&ray.Origin).Dot(u) / speed
+	p := ray.At(closest).Subtraction(&t.center)
+
+	// (|p + s u|^2 + R^2 - r^2)^2 = 4 R^2 ((px + s ux)^2 + (pz + s uz)^2)
+	r2, minor2 := t.major*t.major, t.minor*t.minor
+	f := p.Dot(u)
+	k := p.Dot(p) + r2 - minor2
+
+	roots := linmath.SolveQuartic(
+		1,
+		4*f,
+		4*f*f+2*k-4*r2*(u.X()*u.X()+u.Z()*u.Z()),
+		4*f*k-8*r2*(p.X()*u.X()+p.Z()*u.Z()),
+		k*k-4*r2*(p.X()*p.X()+p.Z()*p.Z()),
+	)
+
+	for _, s := range roots {
+		if hitT := closest + s/speed; minT < hitT && hitT < maxT {
+			return t.hit(ray, hitT), true
+		}
+	}
+
+	return Hit{}, false
+}
+
+// hit is a method that describes the hit at t. The normal points away from the nearest point of the central circle;
+// texture coordinates go around the ring and then around the tube.
+func (t *Torus) hit(ray *linmath.Ray, hitT float64) Hit {
+	point := ray.At(hitT)
+	q := point.Subtraction(&t.center)
+
+	ring := math.Hypot(q.X(), q.Z())
+
+	var nearest *linmath.Vector3
+	if ring > 0 {
+		nearest = linmath.NewVector3(q.X(), 0, q.Z()).MultiplyOnScalar(t.major / ring)
+	} else {
+		nearest = linmath.NewVector3(t.major, 0, 0)
+	}
+
+	normal := q.Subtraction(nearest)
+	if normal.Length() > 0 {
+		normal = normal.Normal()
+	}
+
+	u := math.Atan2(q.Z(), q.X()) / (2 * math.Pi)
+	if u < 0 {
+		u++
+	}
+
+	v := math.Atan2(q.Y(), ring-t.major) / (2 * math.Pi)
+	if v < 0 {
+		v++
+	}
+
+	return Hit{hitT, point, normal, u, v, t.material.albedo(t.material.color, u, v), &t.material}
+}
+
+func (t *Torus) Bounds() *linmath.AABB {
+	extent := linmath.NewVector3(t.major+t.minor, t.minor, t.major+t.minor)
+
+	return linmath.NewAABB(t.center.Subtraction(extent), t.center.Add(extent))
+}
+
+// Tessellate is a method that approximates the torus with a grid of triangles around the ring and the tube.
+func (t *Torus) Tessellate() *Mesh {
+	const rings, segments = 48, 24
+
+	var (
+		vertices  []linmath.Vector3
+		normals   []linmath.Vector3
+		uvs       [][2]float64
+		triangles [][3]int
+	)
+
+	for i := 0; i <= rings; i++ {
+		sinPhi, cosPhi := math.Sincos(2 * math.Pi * float64(i) / rings)
+
+		for j := 0; j <= segments; j++ {
+			sinTheta, cosTheta := math.Sincos(2 * math.Pi * float64(j) / segments)
+			normal := linmath.NewVector3(cosTheta*cosPhi, sinTheta, cosTheta*sinPhi)
+
+			normals = append(normals, *normal)
+			vertices = append(vertices, *t.center.Add(linmath.NewVector3(t.major*cosPhi, 0, t.major*sinPhi)).Add(normal.MultiplyOnScalar(t.minor)))
+			uvs = append(uvs, [2]float64{float64(i) / rings, float64(j) / segments})
+		}
+	}
+
+	for i := 0; i < rings; i++ {
+		for j := 0; j < segments; j++ {
+			a := i*(segments+1) + j
+			b, c, d := a+segments+1, a+segments+2, a+1
+
+			triangles = append(triangles, [3]int{a, d, c}, [3]int{a, c, b})
+		}
+	}
+
+	return NewMesh(vertices, normals, uvs, nil, triangles, t.material)
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestTorusIntersect(t *testing.T) {
+	material := *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil)
+	torus := NewTorus(*linmath.NewVector3(0, 0, 0), 2, 0.5, material)
+	distantTorus := NewTorus(*linmath.NewVector3(0, 0, 1e6), 2, 0.5, material)
+
+	below, above := 0.5-1e-6, 0.5+1e-6
+
+	tests := []struct {
+		name           string
+		inputRay       *linmath.Ray
+		inputTorus     *Torus
+		inputMinT      float64
+		expectedOk     bool
+		expectedT      float64
+		expectedNormal *linmath.Vector3
+	}{
+		{"top", linmath.NewRay(linmath.NewVector3(2, 5, 0), linmath.NewVector3(0, -1, 0), 0), torus, 0, true, 4.5, linmath.NewVector3(0, 1, 0)},
+		{"unnormalized direction", linmath.NewRay(linmath.NewVector3(2, 5, 0), linmath.NewVector3(0, -2, 0), 0), torus, 0, true, 2.25, linmath.NewVector3(0, 1, 0)},
+		{"through the hole", linmath.NewRay(linmath.NewVector3(0, 5, 0), linmath.NewVector3(0, -1, 0), 0), torus, 0, false, 0, nil},
+		{"across", linmath.NewRay(linmath.NewVector3(-5, 0, 0), linmath.NewVector3(1, 0, 0), 0), torus, 0, true, 2.5, linmath.NewVector3(-1, 0, 0)},
+		{"from the hole", linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(1, 0, 0), 0), torus, 0, true, 1.5, linmath.NewVector3(-1, 0, 0)},
+		{"from inside the tube", linmath.NewRay(linmath.NewVector3(2, 0, 0), linmath.NewVector3(0, 1, 0), 0), torus, 0, true, 0.5, linmath.NewVector3(0, 1, 0)},
+		// A ray leaving the surface must not hit it again at the origin, and one entering finds the far side.
+		{"leaving the surface", linmath.NewRay(linmath.NewVector3(2.5, 0, 0), linmath.NewVector3(1, 0, 0), 0), torus, shadowBias, false, 0, nil},
+		{"entering the surface", linmath.NewRay(linmath.NewVector3(-2.5, 0, 0), linmath.NewVector3(1, 0, 0), 0), torus, shadowBias, true, 1, linmath.NewVector3(1, 0, 0)},
+		{"tangent", linmath.NewRay(linmath.NewVector3(-5, 0.5, 0), linmath.NewVector3(1, 0, 0), 0), torus, 0, true, 3, linmath.NewVector3(0, 1, 0)},
+		{"grazing", linmath.NewRay(linmath.NewVector3(-5, below, 0), linmath.NewVector3(1, 0, 0), 0), torus, 0, true, 3 - math.Sqrt(0.25-below*below), nil},
+		{"just missing", linmath.NewRay(linmath.NewVector3(-5, above, 0), linmath.NewVector3(1, 0, 0), 0), torus, 0, false, 0, nil},
+		{"distant", linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 0, 1), 0), distantTorus, 0, true, 1e6 - 2.5, linmath.NewVector3(0, 0, -1)},
+	}
+
+	for _, ts := range tests {
+		h, ok := ts.inputTorus.Intersect(ts.inputRay, ts.inputMinT, math.Inf(1))
+
+		if ok != ts.expectedOk {
+			t.Fatalf("%s: expected [%v] but have [%v] at [%v]", ts.name, ts.expectedOk, ok, h.T)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if math.Abs(h.T-ts.expectedT) > 1e-6 {
+			t.Fatalf("%s: expected t [%v] but have [%v]", ts.name, ts.expectedT, h.T)
+		}
+
+		// The hit point has to lie on the surface: at the minor radius from the central circle.
+		q := h.Point.Subtraction(&ts.inputTorus.center)
+		if distance := math.Hypot(math.Hypot(q.X(), q.Z())-2, q.Y()); math.Abs(distance-0.5) > 1e-9 {
+			t.Fatalf("%s: expected the hit point on the surface but it is [%v] from the central circle", ts.name, distance)
+		}
+
+		if ts.expectedNormal != nil && h.Normal.Subtraction(ts.expectedNormal).Length() > 1e-6 {
+			t.Fatalf("%s: expected normal [%v] but have [%v]", ts.name, ts.expectedNormal, h.Normal)
+		}
+	}
+}
+
+func TestTorusTessellate(t *testing.T) {
+	torus := NewTorus(*linmath.NewVector3(1, 2, 3), 2, 0.5, *NewMaterial(*NewColor(255, 0, 0, 255), -1, 0, nil))
+	mesh := torus.Tessellate()
+
+	for i, tr := range mesh.triangles {
+		v0, v1, v2 := mesh.corners(i)
+		face := v1.Subtraction(v0).Cross(v2.Subtraction(v0))
+
+		if face.Dot(&mesh.normals[tr[0]]) <= 0 {
+			t.Fatalf("expected triangle [%v] to face out of the torus", i)
+		}
+
+		for _, v := range tr {
+			q := mesh.vertices[v].Subtraction(&torus.center)
+			if distance := math.Hypot(math.Hypot(q.X(), q.Z())-2, q.Y()); math.Abs(distance-0.5) > 1e-9 {
+				t.Fatalf("expected vertex [%v] on the surface but it is [%v] from the central circle", v, distance)
+			}
+		}
+	}
+}
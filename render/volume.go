@@ -0,0 +1,313 @@
+package render
+
+import (
+	"errors"
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"sort"
+)
+
+// Fog is exponential fog filling the whole scene: what is seen at a distance d keeps exp(-density * d) of its color
+// and takes the rest from the color of the fog, so whatever is far enough, the background included, fades into it.
+type Fog struct {
+	color   Color
+	density float64
+}
+
+func NewFog(color Color, density float64) *Fog {
+	return &Fog{color, density}
+}
+
+// transmittance is a method that returns the share of the color seen at the distance that makes it through the fog.
+func (f *Fog) transmittance(distance float64) float64 {
+	if f.density <= 0 {
+		return 1
+	}
+
+	return math.Exp(-f.density * distance)
+}
+
+// apply is a method that fades a color seen at the distance into the fog.
+func (f *Fog) apply(c Color, distance float64) Color {
+	transmittance := f.transmittance(distance)
+
+	return *c.MultiplyOnScalar(transmittance).Add(f.color.MultiplyOnScalar(1 - transmittance))
+}
+
+// Medium is what fills a volume: particles that absorb and scatter light. The coefficients are the chances per unit
+// of length that light is absorbed or scattered, scaled by the density grid when there is one. Scattered light
+// is tinted by the color and leaves in directions given by the Henyey–Greenstein phase function of the anisotropy,
+// from -1, which scatters everything back, through 0, which scatters evenly, to 1, which scatters everything forward.
+type Medium struct {
+	color      Color
+	absorption float64
+	scattering float64
+	anisotropy float64
+	density    *DensityGrid // nil for a homogeneous medium
+}
+
+func NewMedium(color Color, absorption, scattering, anisotropy float64, density *DensityGrid) *Medium {
+	return &Medium{color, absorption, scattering, math.Min(math.Max(anisotropy, -0.99), 0.99), density}
+}
+
+// extinction is a method that returns the chance per unit of length that light is absorbed or scattered at full density.
+func (m *Medium) extinction() float64 {
+	return m.absorption + m.scattering
+}
+
+// albedo is a method that returns the share of the light taken out of a ray that is scattered rather than absorbed.
+func (m *Medium) albedo() float64 {
+	if m.extinction() <= 0 {
+		return 0
+	}
+
+	return m.scattering / m.extinction()
+}
+
+// DensityGrid is a grid of densities stretched over the bounds of a volume, with the first and last points
+// of every axis on its faces. Densities between the points are interpolated trilinearly.
+type DensityGrid struct {
+	nx, ny, nz int
+	values     []float64
+	max        float64 // largest density, which bounds the extinction for delta and ratio tracking
+}
+
+// NewDensityGrid is a function that returns a grid of nx x ny x nz densities, listed with x changing fastest and z slowest.
+func NewDensityGrid(nx, ny, nz int, values []float64) (*DensityGrid, error) {
+	if nx <= 0 || ny <= 0 || nz <= 0 {
+		return nil, errors.New("render: density grid has no points")
+	}
+
+	if len(values) != nx*ny*nz {
+		return nil, errors.New("render: density grid does not have nx * ny * nz values")
+	}
+
+	max := 0.
+	for _, v := range values {
+		if v < 0 {
+			return nil, errors.New("render: density grid has a negative density")
+		}
+
+		max = math.Max(max, v)
+	}
+
+	return &DensityGrid{nx, ny, nz, values, max}, nil
+}
+
+// at is a method that returns the density at the coordinates, from 0 to 1 across the grid on every axis.
+func (g *DensityGrid) at(x, y, z float64) float64 {
+	x0, x1, fx := gridCoordinate(x, g.nx)
+	y0, y1, fy := gridCoordinate(y, g.ny)
+	z0, z1, fz := gridCoordinate(z, g.nz)
+
+	value := func(x, y, z int) float64 {
+		return g.values[(z*g.ny+y)*g.nx+x]
+	}
+
+	lerp := func(a, b, t float64) float64 {
+		return a + (b-a)*t
+	}
+
+	return lerp(
+		lerp(lerp(value(x0, y0, z0), value(x1, y0, z0), fx), lerp(value(x0, y1, z0), value(x1, y1, z0), fx), fy),
+		lerp(lerp(value(x0, y0, z1), value(x1, y0, z1), fx), lerp(value(x0, y1, z1), value(x1, y1, z1), fx), fy),
+		fz,
+	)
+}
+
+// gridCoordinate is a function that returns the points of an axis of n points around a coordinate from 0 to 1
+// and how far the coordinate is from the first towards the second.
+func gridCoordinate(u float64, n int) (int, int, float64) {
+	f := math.Min(math.Max(u, 0), 1) * float64(n-1)
+	i := minInt(int(f), maxInt(n-2, 0))
+
+	return i, minInt(i+1, n-1), f - float64(i)
+}
+
+// Volume is a region of the scene, a sphere or a box, filled with a medium. Volumes are seen only by the ray tracer.
+type Volume struct {
+	sphere *Sphere // nil for a box
+	bounds linmath.AABB
+	medium Medium
+}
+
+func NewVolumeSphere(center linmath.Vector3, radius float64, medium *Medium) *Volume {
+	extent := linmath.Splat(radius)
+
+	return &Volume{NewSphere(center, radius, Material{}), *linmath.NewAABB(center.Subtraction(extent), center.Add(extent)), *medium}
+}
+
+func NewVolumeBox(min, max linmath.Vector3, medium *Medium) *Volume {
+	return &Volume{nil, *linmath.NewAABB(&min, &max), *medium}
+}
+
+// segment is a method that returns the span of the ray inside the volume between minT and maxT.
+func (v *Volume) segment(ray *linmath.Ray, minT, maxT float64) (float64, float64, bool) {
+	if v.sphere == nil {
+		return v.bounds.ClipRay(&ray.Origin, &ray.Direction, minT, maxT)
+	}
+
+	t0, t1, ok := v.sphere.roots(ray)
+	t0, t1 = math.Max(t0, minT), math.Min(t1, maxT)
+
+	return t0, t1, ok && t0 < t1
+}
+
+// extinction is a method that returns the chance per unit of length that light is absorbed or scattered at the point.
+func (v *Volume) extinction(p *linmath.Vector3) float64 {
+	if v.medium.density == nil {
+		return v.medium.extinction()
+	}
+
+	min, max := v.bounds.Min(), v.bounds.Max()
+
+	return v.medium.extinction() * v.medium.density.at(
+		(p.X()-min.X())/(max.X()-min.X()),
+		(p.Y()-min.Y())/(max.Y()-min.Y()),
+		(p.Z()-min.Z())/(max.Z()-min.Z()),
+	)
+}
+
+// majorant is a method that returns an extinction no point of the volume exceeds.
+func (v *Volume) majorant() float64 {
+	if v.medium.density == nil {
+		return v.medium.extinction()
+	}
+
+	return v.medium.extinction() * v.medium.density.max
+}
+
+// transmittance is a method that returns the share of light that crosses the volume along the ray from t0 to t1.
+// A homogeneous medium lets exp(-extinction * length) through. Through a heterogeneous one it is estimated
+// by ratio tracking: tentative collisions are spaced as if the medium were as dense as the majorant everywhere,
+// and each one keeps the share of the light that such a fictitious collision would have let through.
+func (v *Volume) transmittance(ray *linmath.Ray, t0, t1 float64, sampler Sampler) float64 {
+	// Extinction is per unit of world length while t is in lengths of the direction, which an instance may have scaled.
+	speed := ray.Direction.Length()
+
+	if v.medium.density == nil {
+		return math.Exp(-v.medium.extinction() * (t1 - t0) * speed)
+	}
+
+	majorant := v.majorant()
+	if majorant <= 0 {
+		return 1
+	}
+
+	transmittance := 1.
+	for t := t0; transmittance > 0; {
+		if t -= math.Log(1-sampler.Float64()) / (majorant * speed); t >= t1 {
+			break
+		}
+
+		transmittance *= 1 - v.extinction(ray.At(t))/majorant
+	}
+
+	return transmittance
+}
+
+// sampleScattering is a method that picks a point of the ray from t0 to t1 where light is scattered towards its origin,
+// with the chance of every point proportional to how much of the light that it takes out of the ray reaches the origin.
+// It returns the point and the weight of its in-scattered light. A homogeneous medium always picks a point
+// and weighs it by the share of light the segment takes out of the ray. Through a heterogeneous medium, delta tracking
+// walks from collision to tentative collision and accepts each one with the ratio of the extinction to the majorant;
+// when no collision is accepted before t1, the ray gathers no light in the volume.
+func (v *Volume) sampleScattering(ray *linmath.Ray, t0, t1 float64, sampler Sampler) (float64, float64, bool) {
+	speed := ray.Direction.Length()
+	majorant := v.majorant()
+	if majorant <= 0 {
+		return 0, 0, false
+	}
+
+	if v.medium.density == nil {
+		// The exponential distribution of collisions, cut off at the end of the segment.
+		taken := 1 - math.Exp(-majorant*(t1-t0)*speed)
+		t := t0 - math.Log(1-sampler.Float64()*taken)/(majorant*speed)
+
+		return math.Min(t, t1), taken, true
+	}
+
+	for t := t0; ; {
+		if t -= math.Log(1-sampler.Float64()) / (majorant * speed); t >= t1 {
+			return 0, 0, false
+		}
+
+		if sampler.Float64()*majorant < v.extinction(ray.At(t)) {
+			return t, 1, true
+		}
+	}
+}
+
+// inScattered is a method that returns the light intensity that the medium at the point scatters
+// back along the ray, summed over the lights that reach the point through the objects and volumes of the scene.
+func (v *Volume) inScattered(point *linmath.Vector3, ray *linmath.Ray, scene *Scene, sampler Sampler, stats *Statistics) float64 {
+	intensity := 0.
+
+	for _, l := range scene.Lights {
+		if l.lightType == ambientLight {
+			intensity += l.intensity
+			continue
+		}
+
+		direction, maxT := l.toward(point)
+
+		visibility := lightVisibility(point, direction, maxT, scene.Objects, scene.Volumes, ray.Time, sampler, stats)
+		if visibility == 0 {
+			continue
+		}
+
+		// Light travels along -direction towards the point and leaves it along -ray.Direction.
+		cos := direction.Dot(&ray.Direction) / (direction.Length() * ray.Direction.Length())
+		intensity += l.intensity * visibility * henyeyGreenstein(cos, v.medium.anisotropy)
+	}
+
+	return intensity
+}
+
+// henyeyGreenstein is a function that returns the Henyey–Greenstein phase function of the anisotropy for the cosine
+// of the angle between the directions light travels before and after scattering. It is scaled by 4 Pi,
+// so that even scattering is 1 and a volume is as bright as a matte surface lit by the same lights.
+func henyeyGreenstein(cos, anisotropy float64) float64 {
+	denominator := 1 + anisotropy*anisotropy - 2*anisotropy*cos
+
+	return (1 - anisotropy*anisotropy) / (denominator * math.Sqrt(denominator))
+}
+
+// participate is a method that returns the color seen along the ray from minT to maxT when behind is what lies at maxT.
+// The fog fades what is behind with its distance. Then every volume the ray crosses, starting from the one entered last,
+// dims the color behind it and adds the light it scatters along the ray, itself faded by the fog in front of it.
+// Overlapping volumes are approximated by taking them one after the other.
+func (s *Scene) participate(ray *linmath.Ray, minT, maxT float64, behind Color, sampler Sampler, stats *Statistics) Color {
+	type crossing struct {
+		volume *Volume
+		t0, t1 float64
+	}
+
+	var crossings []crossing
+	for i := range s.Volumes {
+		if t0, t1, ok := s.Volumes[i].segment(ray, minT, maxT); ok {
+			crossings = append(crossings, crossing{&s.Volumes[i], t0, t1})
+		}
+	}
+
+	sort.Slice(crossings, func(i, j int) bool { return crossings[i].t0 > crossings[j].t0 })
+
+	speed := ray.Direction.Length()
+	fog := s.Fog
+	if fog == nil {
+		fog = &Fog{}
+	}
+
+	color := fog.apply(behind, (maxT-minT)*speed)
+	for _, c := range crossings {
+		medium := &c.volume.medium
+		color = *color.MultiplyOnScalar(c.volume.transmittance(ray, c.t0, c.t1, sampler))
+
+		if t, weight, ok := c.volume.sampleScattering(ray, c.t0, c.t1, sampler); ok && medium.scattering > 0 {
+			intensity := weight * medium.albedo() * c.volume.inScattered(ray.At(t), ray, s, sampler, stats)
+			color = *color.Add(medium.color.MultiplyOnScalar(intensity * fog.transmittance((t-minT)*speed)))
+		}
+	}
+
+	return color
+}
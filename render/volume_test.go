@@ -0,0 +1,171 @@
+package render
+
+import (
+	"github.com/UnTea/ComputerGraphics/linmath"
+	"math"
+	"testing"
+)
+
+func TestFog(t *testing.T) {
+	fog := NewFog(*NewColor(200, 100, 0, 255), 0.5)
+
+	tests := []struct {
+		name          string
+		inputDistance float64
+		expected      Color
+	}{
+		{"at the eye", 0, *NewColor(0, 0, 255, 255)},
+		{"half way", math.Ln2 / 0.5, *NewColor(100, 50, 128, 255)},
+		{"background", math.Inf(1), *NewColor(200, 100, 0, 255)},
+	}
+
+	for _, ts := range tests {
+		if have := fog.apply(*NewColor(0, 0, 255, 255), ts.inputDistance); have != ts.expected {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expected, have)
+		}
+	}
+}
+
+func TestDensityGrid(t *testing.T) {
+	grid, err := NewDensityGrid(2, 2, 1, []float64{0, 1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		inputX   float64
+		inputY   float64
+		expected float64
+	}{
+		{"corner", 0, 0, 0},
+		{"far corner", 1, 1, 3},
+		{"center", 0.5, 0.5, 1.5},
+		{"edge", 0.25, 1, 2.25},
+		{"outside", -1, 2, 2},
+	}
+
+	for _, ts := range tests {
+		if have := grid.at(ts.inputX, ts.inputY, 0.7); math.Abs(have-ts.expected) > 1e-12 {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expected, have)
+		}
+	}
+
+	for _, values := range [][]float64{{1, 2, 3}, {1, 2, 3, -4}} {
+		if _, err := NewDensityGrid(2, 2, 1, values); err == nil {
+			t.Fatalf("expected an error for [%v]", values)
+		}
+	}
+}
+
+func TestVolumeTransmittance(t *testing.T) {
+	// The density rises from 0 to 1 across the box along x, so every ray below crosses an optical depth of 1.
+	gradient, err := NewDensityGrid(2, 1, 1, []float64{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	medium := NewMedium(*NewColor(255, 255, 255, 255), 0.25, 0.75, 0, nil)
+	sphere := NewVolumeSphere(*linmath.NewVector3(0, 0, 0), 0.5, medium)
+	box := NewVolumeBox(*linmath.NewVector3(0, -1, -1), *linmath.NewVector3(2, 1, 1), NewMedium(*NewColor(255, 255, 255, 255), 0.25, 0.75, 0, gradient))
+
+	tests := []struct {
+		name        string
+		inputVolume *Volume
+		inputRay    *linmath.Ray
+		expected    float64
+		tolerance   float64
+	}{
+		{"homogeneous", sphere, linmath.NewRay(linmath.NewVector3(-2, 0, 0), linmath.NewVector3(1, 0, 0), 0), math.Exp(-1), 1e-12},
+		{"unnormalized direction", sphere, linmath.NewRay(linmath.NewVector3(-2, 0, 0), linmath.NewVector3(4, 0, 0), 0), math.Exp(-1), 1e-12},
+		{"from inside", sphere, linmath.NewRay(linmath.NewVector3(0, 0, 0), linmath.NewVector3(0, 1, 0), 0), math.Exp(-0.5), 1e-12},
+		{"along the gradient", box, linmath.NewRay(linmath.NewVector3(-1, 0, 0), linmath.NewVector3(1, 0, 0), 0), math.Exp(-1), 0.01},
+		{"across the gradient", box, linmath.NewRay(linmath.NewVector3(1, -2, 0), linmath.NewVector3(0, 1, 0), 0), math.Exp(-1), 0.01},
+	}
+
+	sampler := NewSampler(RandomSampler, 1)
+	n := 20000
+
+	for _, ts := range tests {
+		t0, t1, ok := ts.inputVolume.segment(ts.inputRay, 0, math.Inf(1))
+		if !ok {
+			t.Fatalf("%s: expected the ray to cross the volume", ts.name)
+		}
+
+		// Ratio tracking is right on average.
+		sum := 0.
+		for i := 0; i < n; i++ {
+			sampler.Start(0, 0, i)
+			sum += ts.inputVolume.transmittance(ts.inputRay, t0, t1, sampler)
+		}
+
+		if have := sum / float64(n); math.Abs(have-ts.expected) > ts.tolerance {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, ts.expected, have)
+		}
+	}
+}
+
+func TestVolumeSampleScattering(t *testing.T) {
+	gradient, err := NewDensityGrid(2, 1, 1, []float64{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	white := *NewColor(255, 255, 255, 255)
+
+	tests := []struct {
+		name        string
+		inputVolume *Volume
+	}{
+		{"homogeneous", NewVolumeBox(*linmath.NewVector3(0, -1, -1), *linmath.NewVector3(2, 1, 1), NewMedium(white, 0, 0.5, 0, nil))},
+		{"heterogeneous", NewVolumeBox(*linmath.NewVector3(0, -1, -1), *linmath.NewVector3(2, 1, 1), NewMedium(white, 0, 1, 0, gradient))},
+	}
+
+	ray := linmath.NewRay(linmath.NewVector3(-1, 0, 0), linmath.NewVector3(1, 0, 0), 0)
+	sampler := NewSampler(RandomSampler, 2)
+	n := 20000
+
+	for _, ts := range tests {
+		t0, t1, _ := ts.inputVolume.segment(ray, 0, math.Inf(1))
+
+		// Both media take 1 - exp(-1) of the light out of the ray, which is what the weights add up to on average.
+		sum := 0.
+		for i := 0; i < n; i++ {
+			sampler.Start(0, 0, i)
+
+			at, weight, ok := ts.inputVolume.sampleScattering(ray, t0, t1, sampler)
+			if !ok {
+				continue
+			}
+
+			if at < t0 || at > t1 {
+				t.Fatalf("%s: expected a point between [%v] and [%v] but have [%v]", ts.name, t0, t1, at)
+			}
+
+			sum += weight
+		}
+
+		if expected, have := 1-math.Exp(-1), sum/float64(n); math.Abs(have-expected) > 0.01 {
+			t.Fatalf("%s: expected [%v] but have [%v]", ts.name, expected, have)
+		}
+	}
+}
+
+func TestHenyeyGreenstein(t *testing.T) {
+	for _, anisotropy := range []float64{-0.9, -0.3, 0, 0.5, 0.9} {
+		// Scaled by 4 Pi, the phase function averages to 1 over the sphere of directions.
+		n := 200000
+		sum := 0.
+		for i := 0; i < n; i++ {
+			sum += henyeyGreenstein(-1+2*(float64(i)+0.5)/float64(n), anisotropy)
+		}
+
+		if have := sum / float64(n); math.Abs(have-1) > 1e-3 {
+			t.Fatalf("%v: expected [1] but have [%v]", anisotropy, have)
+		}
+	}
+
+	if forward, backward := henyeyGreenstein(1, 0.5), henyeyGreenstein(-1, 0.5); forward <= backward {
+		t.Fatalf("expected more forward than backward scattering but have [%v] and [%v]", forward, backward)
+	}
+}